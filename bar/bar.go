@@ -0,0 +1,40 @@
+package bar
+
+import (
+	gomath "math"
+
+	"github.com/bsladewski/lapis/stream"
+)
+
+// pending holds bars that have been completed but not yet read from a bar
+// stream.
+type pending []stream.Candle
+
+// push queues a completed bar.
+func (p *pending) push(bar stream.Candle) {
+	*p = append(*p, bar)
+}
+
+// pop removes and returns the oldest completed bar.
+func (p *pending) pop() stream.Candle {
+
+	bar := (*p)[0]
+	*p = (*p)[1:]
+
+	return bar
+
+}
+
+// newBar returns a bar that moves from the open price to the close price; the
+// high and low of the bar are the greater and lesser of the two.
+func newBar(candle stream.Candle, open, close float64) stream.Candle {
+
+	return stream.Candle{
+		Timestamp: candle.Timestamp,
+		Open:      open,
+		High:      gomath.Max(open, close),
+		Low:       gomath.Min(open, close),
+		Close:     close,
+	}
+
+}
@@ -0,0 +1,7 @@
+// Package bar provides candle streams that transform input candles into
+// alternative bars, such as Heikin-Ashi candles, Renko bricks, Kagi lines,
+// Point and Figure columns and range bars. Apart from Heikin-Ashi candles these
+// bars are formed by price movement rather than time, so a bar stream may read
+// any number of input candles before outputting a bar and a single input candle
+// may complete several bars.
+package bar
@@ -0,0 +1,63 @@
+package bar
+
+import (
+	gomath "math"
+
+	"github.com/bsladewski/lapis/stream"
+)
+
+// heikinAshi is the concrete implementation of a candle stream that transforms
+// input candles into Heikin-Ashi candles.
+type heikinAshi struct {
+	previous stream.Candle
+	started  bool
+	in       stream.CandleStream
+}
+
+// NewHeikinAshiStream returns a candle stream that transforms each input candle
+// into a Heikin-Ashi candle. The close is the average of the input candle's
+// prices and the open is the midpoint of the previous Heikin-Ashi candle's body,
+// or of the input candle's body for the first candle; the high and low extend
+// the input candle's range to include the new open and close. Timestamps and
+// volumes are copied from the input candle.
+func NewHeikinAshiStream(in stream.CandleStream) stream.CandleStream {
+
+	return &heikinAshi{
+		in: in,
+	}
+
+}
+
+func (h *heikinAshi) Next() (stream.Candle, error) {
+
+	// retrieve the next candle
+	candle, err := h.in.Next()
+	if err != nil {
+		return stream.Candle{}, err
+	}
+
+	open := (candle.Open + candle.Close) / 2.0
+	if h.started {
+		open = (h.previous.Open + h.previous.Close) / 2.0
+	}
+
+	close := (candle.Open + candle.High + candle.Low + candle.Close) / 4.0
+
+	h.previous = stream.Candle{
+		Timestamp:   candle.Timestamp,
+		Open:        open,
+		High:        gomath.Max(candle.High, gomath.Max(open, close)),
+		Low:         gomath.Min(candle.Low, gomath.Min(open, close)),
+		Close:       close,
+		Volume:      candle.Volume,
+		QuoteVolume: candle.QuoteVolume,
+	}
+	h.started = true
+
+	return h.previous, nil
+
+}
+
+func (h *heikinAshi) Close() {
+	h.in.Close()
+}
@@ -0,0 +1,139 @@
+package bar_test
+
+import (
+	gomath "math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bsladewski/lapis/bar"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// loadMockCandles reads the candles in the mock data file.
+func loadMockCandles(t testing.TB) []stream.Candle {
+
+	mockData, err := os.Open("../input/mock_data.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mockData.Close()
+
+	ms, err := input.NewCoinbaseMockCandleStream(mockData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ms.Close()
+
+	return readCandles(t, ms)
+
+}
+
+// readCandles reads every candle from a candle stream until the end of the
+// stream.
+func readCandles(t testing.TB, s stream.CandleStream) []stream.Candle {
+
+	t.Helper()
+
+	var candles []stream.Candle
+	for {
+
+		candle, err := s.Next()
+		if err == stream.ErrEndOfStream {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		candles = append(candles, candle)
+
+	}
+
+	return candles
+
+}
+
+// closeCandles returns hourly candles that open and close at the supplied
+// prices, each with a volume of 1.
+func closeCandles(closes ...float64) []stream.Candle {
+
+	start := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	candles := make([]stream.Candle, len(closes))
+	for i, close := range closes {
+		candles[i] = stream.Candle{
+			Timestamp: start.Add(time.Duration(i) * time.Hour),
+			Open:      close,
+			High:      close,
+			Low:       close,
+			Close:     close,
+			Volume:    1.0,
+		}
+	}
+
+	return candles
+
+}
+
+// assertBars asserts that bars move between the expected open and close
+// prices.
+func assertBars(t *testing.T, bars []stream.Candle, expected [][2]float64) {
+
+	t.Helper()
+
+	if len(bars) != len(expected) {
+		t.Fatalf("expected %d bars, got %d: %v", len(expected), len(bars),
+			bars)
+	}
+
+	for i, bar := range bars {
+		if util.CompareFloat(bar.Open, expected[i][0]) != 0 ||
+			util.CompareFloat(bar.Close, expected[i][1]) != 0 {
+			t.Fatalf("index %d; expected %v, got open %v close %v", i,
+				expected[i], bar.Open, bar.Close)
+		}
+	}
+
+}
+
+// TestHeikinAshiStream tests transforming candles into Heikin-Ashi candles.
+func TestHeikinAshiStream(t *testing.T) {
+
+	candles := loadMockCandles(t)
+
+	s := bar.NewHeikinAshiStream(input.NewCandleListStream(candles))
+	defer s.Close()
+
+	bars := readCandles(t, s)
+	if len(bars) != len(candles) {
+		t.Fatalf("expected %d candles, got %d", len(candles), len(bars))
+	}
+
+	for i, candle := range candles {
+
+		open := (candle.Open + candle.Close) / 2.0
+		if i > 0 {
+			open = (bars[i-1].Open + bars[i-1].Close) / 2.0
+		}
+
+		close := (candle.Open + candle.High + candle.Low + candle.Close) / 4.0
+
+		expected := stream.Candle{
+			Timestamp:   candle.Timestamp,
+			Open:        open,
+			High:        gomath.Max(candle.High, gomath.Max(open, close)),
+			Low:         gomath.Min(candle.Low, gomath.Min(open, close)),
+			Close:       close,
+			Volume:      candle.Volume,
+			QuoteVolume: candle.QuoteVolume,
+		}
+
+		if bars[i] != expected {
+			t.Fatalf("index %d; expected %v, got %v", i, expected, bars[i])
+		}
+
+	}
+
+}
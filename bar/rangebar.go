@@ -0,0 +1,119 @@
+package bar
+
+import (
+	"errors"
+	gomath "math"
+	"time"
+
+	"github.com/bsladewski/lapis/stream"
+)
+
+// rangeBar is the concrete implementation of a candle stream that transforms
+// input candles into range bars.
+type rangeBar struct {
+	size    float64
+	bar     stream.Candle
+	started bool
+	bars    pending
+	in      stream.CandleStream
+}
+
+// NewRangeBarStream returns a candle stream that transforms input candles into
+// bars that each span the specified range from high to low. Prices within an
+// input candle are assumed to move from the open to whichever of the high and
+// low is nearer in the direction of the candle body, then to the other, then to
+// the close. A bar is complete once its range is reached and the next bar opens
+// at the completed bar's close, so a single candle may complete several bars or
+// none at all.
+//
+// Each bar is timestamped with the candle in which it opened and the volume of
+// each candle is assigned to the bar in progress when the candle opened. The
+// bar in progress at the end of input is not output.
+func NewRangeBarStream(in stream.CandleStream,
+	size float64) stream.CandleStream {
+
+	return &rangeBar{
+		size: size,
+		in:   in,
+	}
+
+}
+
+func (r *rangeBar) Next() (stream.Candle, error) {
+
+	if r.size <= 0 {
+		return stream.Candle{},
+			errors.New("range bar size cannot be negative or zero")
+	}
+
+	// read candles until at least one bar has been completed
+	for len(r.bars) == 0 {
+
+		candle, err := r.in.Next()
+		if err != nil {
+			return stream.Candle{}, err
+		}
+
+		if !r.started {
+			r.bar = newBar(candle, candle.Open, candle.Open)
+			r.started = true
+		}
+
+		r.bar.Volume += candle.Volume
+		r.bar.QuoteVolume += candle.QuoteVolume
+
+		path := []float64{candle.Open, candle.High, candle.Low, candle.Close}
+		if candle.Close >= candle.Open {
+			path = []float64{candle.Open, candle.Low, candle.High, candle.Close}
+		}
+
+		for _, price := range path {
+			r.move(price, candle.Timestamp)
+		}
+
+	}
+
+	return r.bars.pop(), nil
+
+}
+
+// move moves the price of the bar in progress, completing a bar each time the
+// price reaches the range of the bar.
+func (r *rangeBar) move(price float64, timestamp time.Time) {
+
+	for price >= r.bar.Low+r.size {
+		r.complete(r.bar.Low+r.size, timestamp)
+	}
+
+	for price <= r.bar.High-r.size {
+		r.complete(r.bar.High-r.size, timestamp)
+	}
+
+	r.bar.High = gomath.Max(r.bar.High, price)
+	r.bar.Low = gomath.Min(r.bar.Low, price)
+	r.bar.Close = price
+
+}
+
+// complete completes the bar in progress at the specified price and opens a
+// new bar at that price.
+func (r *rangeBar) complete(price float64, timestamp time.Time) {
+
+	r.bar.High = gomath.Max(r.bar.High, price)
+	r.bar.Low = gomath.Min(r.bar.Low, price)
+	r.bar.Close = price
+	r.bars.push(r.bar)
+
+	r.bar = stream.Candle{
+		Timestamp: timestamp,
+		Open:      price,
+		High:      price,
+		Low:       price,
+		Close:     price,
+	}
+
+}
+
+func (r *rangeBar) Close() {
+	r.in.Close()
+}
@@ -0,0 +1,91 @@
+package bar_test
+
+import (
+	"testing"
+
+	"github.com/bsladewski/lapis/bar"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/util"
+)
+
+// TestRangeBarStream tests forming range bars, including candles that
+// complete no bars and candles that complete several.
+func TestRangeBarStream(t *testing.T) {
+
+	candles := closeCandles(103, 112, 91)
+	for i, prices := range [][3]float64{
+		{100, 104, 98},
+		{103, 115, 101},
+		{112, 112, 90},
+	} {
+		candles[i].Open, candles[i].High, candles[i].Low =
+			prices[0], prices[1], prices[2]
+	}
+
+	s := bar.NewRangeBarStream(input.NewCandleListStream(candles), 10.0)
+	defer s.Close()
+
+	bars := readCandles(t, s)
+
+	// the bar in progress at the end of input is not output
+	assertBars(t, bars, [][2]float64{
+		{100, 108},
+		{108, 105},
+		{105, 95},
+	})
+
+	for i, bar := range bars {
+		if util.CompareFloat(bar.High-bar.Low, 10.0) != 0 {
+			t.Fatalf("index %d; expected range 10, got %v", i,
+				bar.High-bar.Low)
+		}
+	}
+
+	// bars are timestamped with the candle in which they opened and include
+	// the volume of candles that opened while they were in progress
+	for i, expected := range []struct {
+		index  int
+		volume float64
+	}{{0, 2}, {1, 1}, {2, 0}} {
+		if !bars[i].Timestamp.Equal(candles[expected.index].Timestamp) {
+			t.Fatalf("index %d; expected timestamp %v, got %v", i,
+				candles[expected.index].Timestamp, bars[i].Timestamp)
+		}
+		if bars[i].Volume != expected.volume {
+			t.Fatalf("index %d; expected volume %v, got %v", i,
+				expected.volume, bars[i].Volume)
+		}
+	}
+
+}
+
+// TestRangeBarStreamMock tests that range bars formed from mock data each span
+// the bar size and open where the previous bar closed.
+func TestRangeBarStreamMock(t *testing.T) {
+
+	size := 25.0
+
+	s := bar.NewRangeBarStream(input.NewCandleListStream(
+		loadMockCandles(t)), size)
+	defer s.Close()
+
+	bars := readCandles(t, s)
+	if len(bars) == 0 {
+		t.Fatal("expected bars to be formed")
+	}
+
+	for i, bar := range bars {
+
+		if util.CompareFloat(bar.High-bar.Low, size) != 0 {
+			t.Fatalf("index %d; expected range %v, got %v", i, size,
+				bar.High-bar.Low)
+		}
+
+		if i > 0 && bar.Open != bars[i-1].Close {
+			t.Fatalf("index %d; bar opens at %v, previous closed at %v", i,
+				bar.Open, bars[i-1].Close)
+		}
+
+	}
+
+}
@@ -0,0 +1,139 @@
+package bar
+
+import (
+	"errors"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// renko is the concrete implementation of a candle stream that transforms
+// input candles into Renko bricks.
+type renko struct {
+	size        float64
+	sizes       stream.Stream
+	warmUp      int
+	count       int
+	started     bool
+	low         float64
+	high        float64
+	volume      float64
+	quoteVolume float64
+	bricks      pending
+	in          stream.CandleStream
+}
+
+// NewRenkoStream returns a candle stream that transforms input candles into
+// Renko bricks of a fixed box size. The first close price is the reference for
+// the first brick; a new brick is formed each time the close moves a full box
+// beyond the top or bottom of the last brick, so reversing direction takes a
+// move of two boxes from the last close. A single candle may form several
+// bricks or none at all.
+//
+// Each brick is timestamped with the candle that formed it and the volume
+// traded since the previous brick is assigned to the first brick formed by
+// that candle.
+func NewRenkoStream(in stream.CandleStream, size float64) stream.CandleStream {
+
+	return &renko{
+		size: size,
+		in:   in,
+	}
+
+}
+
+// NewATRRenkoStream returns a candle stream that transforms input candles into
+// Renko bricks whose box size is Wilder's Average True Range of the input over
+// the specified period at the time each brick is formed. Bricks are not formed
+// until the average true range has warmed up; the close of the first candle
+// after the warm-up is the reference for the first brick.
+func NewATRRenkoStream(in stream.CandleStream,
+	period int) stream.CandleStream {
+
+	// each candle is read once to form bricks and once by the average true
+	// range
+	splitter := input.NewCandleSplitterStream(in, 2)
+	atr := indicator.NewATRStream(splitter, period)
+
+	return &renko{
+		sizes:  atr,
+		warmUp: atr.WarmUp(),
+		in:     splitter,
+	}
+
+}
+
+func (r *renko) Next() (stream.Candle, error) {
+
+	if r.sizes == nil && r.size <= 0 {
+		return stream.Candle{},
+			errors.New("renko box size cannot be negative or zero")
+	}
+
+	// read candles until at least one brick has been formed
+	for len(r.bricks) == 0 {
+
+		candle, err := r.in.Next()
+		if err != nil {
+			return stream.Candle{}, err
+		}
+
+		size := r.size
+		if r.sizes != nil {
+			if size, err = r.sizes.Next(); err != nil {
+				return stream.Candle{}, err
+			}
+		}
+
+		r.volume += candle.Volume
+		r.quoteVolume += candle.QuoteVolume
+
+		// wait for the box size to warm up; a box size of zero, e.g. the
+		// average true range of flat input, cannot form bricks
+		r.count++
+		if r.count <= r.warmUp || !(size > 0) {
+			continue
+		}
+
+		if !r.started {
+			r.low, r.high = candle.Close, candle.Close
+			r.started = true
+			continue
+		}
+
+		r.form(candle, size)
+
+	}
+
+	return r.bricks.pop(), nil
+
+}
+
+// form queues any bricks formed by the close of the supplied candle.
+func (r *renko) form(candle stream.Candle, size float64) {
+
+	formed := len(r.bricks)
+
+	for candle.Close >= r.high+size {
+		r.bricks.push(newBar(candle, r.high, r.high+size))
+		r.low, r.high = r.high, r.high+size
+	}
+
+	for candle.Close <= r.low-size {
+		r.bricks.push(newBar(candle, r.low, r.low-size))
+		r.low, r.high = r.low-size, r.low
+	}
+
+	// assign the volume traded since the last brick to the first new brick
+	if len(r.bricks) > formed {
+		r.bricks[formed].Volume = r.volume
+		r.bricks[formed].QuoteVolume = r.quoteVolume
+		r.volume, r.quoteVolume = 0.0, 0.0
+	}
+
+}
+
+func (r *renko) Close() {
+	r.in.Close()
+}
@@ -0,0 +1,120 @@
+package bar_test
+
+import (
+	gomath "math"
+	"testing"
+	"time"
+
+	"github.com/bsladewski/lapis/bar"
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/util"
+)
+
+// TestRenkoStream tests forming Renko bricks of a fixed box size, including
+// candles that form no bricks and candles that form several.
+func TestRenkoStream(t *testing.T) {
+
+	candles := closeCandles(100, 104, 111, 135, 125, 119, 98, 112, 121)
+
+	s := bar.NewRenkoStream(input.NewCandleListStream(candles), 10.0)
+	defer s.Close()
+
+	bricks := readCandles(t, s)
+
+	assertBars(t, bricks, [][2]float64{
+		{100, 110}, // 111
+		{110, 120}, // 135
+		{120, 130},
+		{120, 110}, // 98, 125 and 119 do not reverse
+		{110, 100},
+		{110, 120}, // 121, 112 does not reverse
+	})
+
+	// each brick is timestamped with the candle that formed it
+	for i, index := range []int{2, 3, 3, 6, 6, 8} {
+		if !bricks[i].Timestamp.Equal(candles[index].Timestamp) {
+			t.Fatalf("index %d; expected timestamp %v, got %v", i,
+				candles[index].Timestamp, bricks[i].Timestamp)
+		}
+	}
+
+	// volume since the previous brick goes to the first brick formed
+	for i, volume := range []float64{3, 1, 0, 3, 0, 2} {
+		if bricks[i].Volume != volume {
+			t.Fatalf("index %d; expected volume %v, got %v", i, volume,
+				bricks[i].Volume)
+		}
+	}
+
+}
+
+// TestATRRenkoStream tests that bricks sized by the average true range are
+// contiguous and sized by the average true range of the forming candle.
+func TestATRRenkoStream(t *testing.T) {
+
+	period := 14
+	candles := loadMockCandles(t)
+
+	s := bar.NewATRRenkoStream(input.NewCandleListStream(candles), period)
+	defer s.Close()
+
+	bricks := readCandles(t, s)
+	if len(bricks) == 0 {
+		t.Fatal("expected bricks to be formed")
+	}
+
+	// no brick may be formed before the average true range is warmed up
+	if bricks[0].Timestamp.Before(candles[period].Timestamp) {
+		t.Fatalf("brick formed during warm-up at %v", bricks[0].Timestamp)
+	}
+
+	// calculate the average true range at each candle
+	atr := indicator.NewATRStream(input.NewCandleListStream(candles), period)
+	defer atr.Close()
+
+	sizes := make(map[time.Time]float64)
+	for _, candle := range candles {
+		size, err := atr.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes[candle.Timestamp] = size
+	}
+
+	for i := range bricks {
+
+		size := gomath.Abs(bricks[i].Close - bricks[i].Open)
+		if util.CompareFloat(size, sizes[bricks[i].Timestamp]) != 0 {
+			t.Fatalf("index %d; expected size %v, got %v", i,
+				sizes[bricks[i].Timestamp], size)
+		}
+
+		if i == 0 {
+			continue
+		}
+
+		// each brick starts at the top or bottom of the previous brick
+		previous := bricks[i-1]
+		if util.CompareFloat(bricks[i].Open, previous.High) != 0 &&
+			util.CompareFloat(bricks[i].Open, previous.Low) != 0 {
+			t.Fatalf("index %d; brick %v does not follow %v", i, bricks[i],
+				previous)
+		}
+
+	}
+
+}
+
+// TestRenkoStreamSize tests that a Renko stream with an invalid box size
+// returns an error.
+func TestRenkoStreamSize(t *testing.T) {
+
+	s := bar.NewRenkoStream(input.NewCandleListStream(closeCandles(1)), 0.0)
+	defer s.Close()
+
+	if _, err := s.Next(); err == nil {
+		t.Fatal("expected error for zero box size")
+	}
+
+}
@@ -0,0 +1,203 @@
+package bar
+
+import (
+	"errors"
+	gomath "math"
+
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// boxPrecision absorbs rounding error when snapping prices to boxes, so that a
+// price that lies on a box boundary is not placed in the box below it.
+const boxPrecision = 1e-9
+
+// reversal is the concrete implementation of a candle stream that outputs a
+// bar for each line of a Kagi chart or column of a Point and Figure chart; a
+// line or column extends while the close price moves in its direction and is
+// output once the close price reverses by at least the reversal amount.
+type reversal struct {
+	box       float64
+	threshold float64
+	offset    float64
+	name      string
+	direction int
+	started   bool
+	bar       stream.Candle
+	bars      pending
+	done      bool
+	in        stream.CandleStream
+}
+
+// NewKagiStream returns a candle stream that transforms input candles into the
+// lines of a Kagi chart. A line extends while the close moves in its direction
+// and a new line is started from the extreme of the previous line once the
+// close reverses by at least the specified amount; the direction of the first
+// line is that of the first move of at least the reversal amount.
+//
+// Each line is output once it has been reversed, opening where the line starts
+// and closing at its extreme, with the line in progress output at the end of
+// input. Lines are timestamped with the candle that started them and include
+// the volume traded while they were in progress.
+func NewKagiStream(in stream.CandleStream,
+	amount float64) stream.CandleStream {
+
+	return &reversal{
+		threshold: amount,
+		name:      "kagi reversal amount",
+		in:        in,
+	}
+
+}
+
+// NewPointAndFigureStream returns a candle stream that transforms input
+// candles into the columns of a Point and Figure chart with the specified box
+// size. A rising column extends to the highest box filled by the close and a
+// falling column to the lowest; the column is reversed once the close fills the
+// specified number of boxes in the opposite direction, starting the new column
+// one box from the extreme of the previous column.
+//
+// Each column is output once it has been reversed, opening at its first box
+// and closing at its last, with the column in progress output at the end of
+// input. Columns are timestamped with the candle that started them and include
+// the volume traded while they were in progress.
+func NewPointAndFigureStream(in stream.CandleStream, box float64,
+	boxes int) stream.CandleStream {
+
+	return &reversal{
+		box:       box,
+		threshold: box * float64(boxes),
+		offset:    box,
+		name:      "point and figure box size and reversal",
+		in:        in,
+	}
+
+}
+
+func (r *reversal) Next() (stream.Candle, error) {
+
+	if r.threshold <= 0 || r.box < 0 {
+		return stream.Candle{},
+			errors.New(r.name + " cannot be negative or zero")
+	}
+
+	// read candles until a line or column has been reversed
+	for len(r.bars) == 0 {
+
+		if r.done {
+			return stream.Candle{}, stream.ErrEndOfStream
+		}
+
+		candle, err := r.in.Next()
+		if err == stream.ErrEndOfStream {
+
+			// output the line or column in progress at the end of input
+			r.done = true
+			if r.direction != 0 {
+				r.bars.push(r.bar)
+			}
+			continue
+
+		} else if err != nil {
+			return stream.Candle{}, err
+		}
+
+		r.update(candle)
+
+	}
+
+	return r.bars.pop(), nil
+
+}
+
+// update moves the line or column in progress to the close price of the
+// supplied candle, reversing it if the close has moved far enough.
+func (r *reversal) update(candle stream.Candle) {
+
+	up, down := r.snap(candle.Close)
+
+	if !r.started {
+		r.bar = newBar(candle, up, up)
+		r.started = true
+	}
+
+	switch r.direction {
+
+	case 0:
+
+		// the first line or column moves in the direction of the first move
+		// that would fill a box, or reach the reversal amount for Kagi lines
+		first := r.box
+		if first == 0.0 {
+			first = r.threshold
+		}
+
+		if util.CompareFloat(up-r.bar.Open, first) >= 0 {
+			r.direction = 1
+			r.extend(up)
+		} else if util.CompareFloat(r.bar.Open-down, first) >= 0 {
+			r.direction = -1
+			r.extend(down)
+		}
+
+	case 1:
+
+		if up > r.bar.Close {
+			r.extend(up)
+		} else if util.CompareFloat(r.bar.Close-down, r.threshold) >= 0 {
+			r.reverse(candle, r.bar.Close-r.offset, down)
+		}
+
+	case -1:
+
+		if down < r.bar.Close {
+			r.extend(down)
+		} else if util.CompareFloat(up-r.bar.Close, r.threshold) >= 0 {
+			r.reverse(candle, r.bar.Close+r.offset, up)
+		}
+
+	}
+
+	// the volume of a reversing candle belongs to the new line or column
+	r.bar.Volume += candle.Volume
+	r.bar.QuoteVolume += candle.QuoteVolume
+
+}
+
+// snap returns the levels reached by a price moving up and down; for Point and
+// Figure columns these are the highest box a rising price fills and the lowest
+// box a falling price fills, for Kagi lines both are the price itself.
+func (r *reversal) snap(price float64) (float64, float64) {
+
+	if r.box == 0.0 {
+		return price, price
+	}
+
+	return gomath.Floor(price/r.box+boxPrecision) * r.box,
+		gomath.Ceil(price/r.box-boxPrecision) * r.box
+
+}
+
+// extend moves the close of the line or column in progress.
+func (r *reversal) extend(close float64) {
+
+	r.bar.Close = close
+	r.bar.High = gomath.Max(r.bar.High, close)
+	r.bar.Low = gomath.Min(r.bar.Low, close)
+
+}
+
+// reverse completes the line or column in progress and starts a new one in
+// the opposite direction.
+func (r *reversal) reverse(candle stream.Candle, open, close float64) {
+
+	r.bars.push(r.bar)
+
+	r.bar = newBar(candle, open, close)
+	r.direction = -r.direction
+
+}
+
+func (r *reversal) Close() {
+	r.in.Close()
+}
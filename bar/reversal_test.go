@@ -0,0 +1,92 @@
+package bar_test
+
+import (
+	"testing"
+
+	"github.com/bsladewski/lapis/bar"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// TestKagiStream tests forming the lines of a Kagi chart.
+func TestKagiStream(t *testing.T) {
+
+	candles := closeCandles(100, 105, 112, 108, 120, 109, 111, 95, 102, 106)
+
+	s := bar.NewKagiStream(input.NewCandleListStream(candles), 10.0)
+	defer s.Close()
+
+	lines := readCandles(t, s)
+
+	// the line in progress is output at the end of input
+	assertBars(t, lines, [][2]float64{
+		{100, 120},
+		{120, 95},
+		{95, 106},
+	})
+
+	// lines include the volume of every candle read while in progress
+	for i, volume := range []float64{5, 4, 1} {
+		if lines[i].Volume != volume {
+			t.Fatalf("index %d; expected volume %v, got %v", i, volume,
+				lines[i].Volume)
+		}
+	}
+
+}
+
+// TestPointAndFigureStream tests forming the columns of a Point and Figure
+// chart with a three box reversal.
+func TestPointAndFigureStream(t *testing.T) {
+
+	candles := closeCandles(100, 108, 121, 135, 119, 104, 99, 131)
+
+	s := bar.NewPointAndFigureStream(input.NewCandleListStream(candles), 10.0,
+		3)
+	defer s.Close()
+
+	columns := readCandles(t, s)
+
+	assertBars(t, columns, [][2]float64{
+		{100, 130},
+		{120, 100}, // starts one box below the previous column
+		{110, 130},
+	})
+
+	// columns are timestamped with the candle that started them
+	for i, index := range []int{0, 6, 7} {
+		if !columns[i].Timestamp.Equal(candles[index].Timestamp) {
+			t.Fatalf("index %d; expected timestamp %v, got %v", i,
+				candles[index].Timestamp, columns[i].Timestamp)
+		}
+	}
+
+}
+
+// TestReversalStreamNoDirection tests that no bars are output when the input
+// never moves far enough to establish a direction.
+func TestReversalStreamNoDirection(t *testing.T) {
+
+	s := bar.NewKagiStream(input.NewCandleListStream(
+		closeCandles(100, 104, 96)), 10.0)
+	defer s.Close()
+
+	if _, err := s.Next(); err != stream.ErrEndOfStream {
+		t.Fatalf("expected end of stream error, got %v", err)
+	}
+
+}
+
+// TestPointAndFigureStreamBox tests that a Point and Figure stream with an
+// invalid box size returns an error.
+func TestPointAndFigureStreamBox(t *testing.T) {
+
+	s := bar.NewPointAndFigureStream(input.NewCandleListStream(
+		closeCandles(1)), 0.0, 3)
+	defer s.Close()
+
+	if _, err := s.Next(); err == nil {
+		t.Fatal("expected error for zero box size")
+	}
+
+}
@@ -0,0 +1,735 @@
+package chart
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	gomath "math"
+	"time"
+
+	"github.com/bsladewski/lapis/stream"
+)
+
+const (
+	// DefaultWidth is the width of a chart in pixels if none is specified.
+	DefaultWidth = 1200
+	// DefaultHeight is the height of a chart in pixels if none is specified.
+	DefaultHeight = 800
+)
+
+// the margins around the plotting area of a chart and the gap between panels
+const (
+	marginLeft   = 10.0
+	marginRight  = 70.0
+	marginTop    = 30.0
+	marginBottom = 30.0
+	panelGap     = 12.0
+)
+
+// the colors used to draw chart elements
+var (
+	backgroundColor = color.RGBA{255, 255, 255, 255}
+	frameColor      = color.RGBA{96, 96, 96, 255}
+	gridColor       = color.RGBA{224, 224, 224, 255}
+	levelColor      = color.RGBA{160, 160, 160, 255}
+	textColor       = color.RGBA{32, 32, 32, 255}
+	risingColor     = color.RGBA{38, 166, 91, 255}
+	fallingColor    = color.RGBA{214, 69, 65, 255}
+	palette         = []color.RGBA{
+		{31, 119, 180, 255},
+		{255, 127, 14, 255},
+		{148, 103, 189, 255},
+		{23, 190, 207, 255},
+		{140, 86, 75, 255},
+		{227, 119, 194, 255},
+	}
+)
+
+// A Chart is a stack of panels sharing a time axis; sample i of every series
+// in every panel is drawn at the same horizontal position.
+type Chart struct {
+	// Title is drawn above the chart.
+	Title string
+	// Width and Height are the size of the chart in pixels; zero values are
+	// replaced by DefaultWidth and DefaultHeight.
+	Width  int
+	Height int
+	// Times holds the timestamp of each sample and labels the time axis;
+	// samples are labeled by index if Times is empty.
+	Times []time.Time
+	// Panels are drawn from top to bottom.
+	Panels []*Panel
+}
+
+// A Panel plots series against a vertical axis of its own.
+type Panel struct {
+	// Title is drawn in the top left of the panel ahead of the series names.
+	Title string
+	// Weight is the height of the panel relative to the other panels; a zero
+	// weight is treated as 1.
+	Weight float64
+	// Series are drawn in order, so later series are drawn over earlier ones.
+	Series []Series
+	// Levels are values marked with a horizontal line, e.g. 30 and 70 for an
+	// RSI panel.
+	Levels []float64
+	// Markers mark trade signals.
+	Markers []Marker
+}
+
+// A Series is data drawn in a panel; see Line, Histogram, Band and Candles.
+type Series interface {
+	// label returns the name of the series and the color of its legend.
+	label() (string, color.RGBA)
+	// bounds returns the range of the values in the series and the number of
+	// samples in the series.
+	bounds() (float64, float64, int)
+	// draw draws the series.
+	draw(p *plot, c color.RGBA)
+}
+
+// A Line draws values connected by a line; NaN values break the line.
+type Line struct {
+	Name   string
+	Values []float64
+	// Color is the color of the line; a zero color picks a color from the
+	// chart palette.
+	Color color.RGBA
+}
+
+// A Histogram draws each value as a bar from zero, e.g. a MACD histogram; NaN
+// values are skipped.
+type Histogram struct {
+	Name   string
+	Values []float64
+	// Color is the color of the bars; a zero color draws positive bars in
+	// green and negative bars in red.
+	Color color.RGBA
+}
+
+// A Band fills the area between an upper and lower line, e.g. Bollinger Bands;
+// NaN values break the band.
+type Band struct {
+	Name  string
+	Upper []float64
+	Lower []float64
+	// Color is the color of the band; a zero color picks a color from the
+	// chart palette.
+	Color color.RGBA
+}
+
+// Candles draw candlesticks, green for candles that close at or above their
+// open and red otherwise.
+type Candles struct {
+	Name    string
+	Candles []stream.Candle
+}
+
+// MarkerSignal identifies the kind of trade signal marked by a marker.
+type MarkerSignal int
+
+const (
+	// MarkerBuy marks a buy signal with a green triangle pointing up drawn
+	// below the price.
+	MarkerBuy MarkerSignal = iota
+	// MarkerSell marks a sell signal with a red triangle pointing down drawn
+	// above the price.
+	MarkerSell
+)
+
+// A Marker marks a trade signal at a sample.
+type Marker struct {
+	// Index is the sample the signal occurred at.
+	Index int
+	// Value is the price the marker points at.
+	Value float64
+	// Signal is the kind of signal.
+	Signal MarkerSignal
+	// Label is drawn beside the marker, if not empty.
+	Label string
+}
+
+// anchor aligns text horizontally with its position.
+type anchor int
+
+const (
+	anchorStart anchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+// point is a position on a canvas in pixels.
+type point struct {
+	x float64
+	y float64
+}
+
+// canvas is a drawing surface of a chart format.
+type canvas interface {
+	// polyline draws lines connecting the points.
+	polyline(points []point, c color.RGBA, width float64)
+	// polygon fills the polygon with the points as vertices.
+	polygon(points []point, fill color.RGBA)
+	// rect fills a rectangle.
+	rect(x, y, w, h float64, fill color.RGBA)
+	// text draws text with its baseline at y.
+	text(x, y float64, s string, c color.RGBA, a anchor)
+}
+
+// plot maps sample indexes and values to positions within a panel.
+type plot struct {
+	canvas  canvas
+	left    float64
+	top     float64
+	width   float64
+	height  float64
+	samples int
+	min     float64
+	max     float64
+}
+
+// x returns the horizontal position of the center of a sample.
+func (p *plot) x(i int) float64 {
+	return p.left + (float64(i)+0.5)*p.slot()
+}
+
+// y returns the vertical position of a value.
+func (p *plot) y(value float64) float64 {
+	return p.top + p.height*(p.max-value)/(p.max-p.min)
+}
+
+// slot returns the width available to each sample.
+func (p *plot) slot() float64 {
+	return p.width / float64(p.samples)
+}
+
+// runs returns the runs of consecutive indexes at which every series holds a
+// value, so that lines and bands are broken at missing values.
+func runs(n int, series ...[]float64) [][]int {
+
+	var result [][]int
+	var run []int
+
+	for i := 0; i < n; i++ {
+
+		valid := true
+		for _, values := range series {
+			if i >= len(values) || gomath.IsNaN(values[i]) ||
+				gomath.IsInf(values[i], 0) {
+				valid = false
+				break
+			}
+		}
+
+		if valid {
+			run = append(run, i)
+			continue
+		}
+
+		if len(run) > 0 {
+			result = append(result, run)
+			run = nil
+		}
+
+	}
+
+	if len(run) > 0 {
+		result = append(result, run)
+	}
+
+	return result
+
+}
+
+// valueBounds returns the range of the finite values in a set of series and
+// the length of the longest series.
+func valueBounds(series ...[]float64) (float64, float64, int) {
+
+	min, max, n := gomath.Inf(1), gomath.Inf(-1), 0
+
+	for _, values := range series {
+		if len(values) > n {
+			n = len(values)
+		}
+		for _, value := range values {
+			if gomath.IsNaN(value) || gomath.IsInf(value, 0) {
+				continue
+			}
+			min, max = gomath.Min(min, value), gomath.Max(max, value)
+		}
+	}
+
+	return min, max, n
+
+}
+
+func (l *Line) label() (string, color.RGBA) {
+	return l.Name, l.Color
+}
+
+func (l *Line) bounds() (float64, float64, int) {
+	return valueBounds(l.Values)
+}
+
+func (l *Line) draw(p *plot, c color.RGBA) {
+
+	for _, run := range runs(len(l.Values), l.Values) {
+
+		points := make([]point, len(run))
+		for i, index := range run {
+			points[i] = point{p.x(index), p.y(l.Values[index])}
+		}
+
+		// a lone value is drawn as a short horizontal line
+		if len(points) == 1 {
+			half := p.slot() / 2.0
+			points = []point{{points[0].x - half, points[0].y},
+				{points[0].x + half, points[0].y}}
+		}
+
+		p.canvas.polyline(points, c, 1.5)
+
+	}
+
+}
+
+func (h *Histogram) label() (string, color.RGBA) {
+
+	if h.Color.A == 0 {
+		return h.Name, risingColor
+	}
+
+	return h.Name, h.Color
+
+}
+
+func (h *Histogram) bounds() (float64, float64, int) {
+
+	min, max, n := valueBounds(h.Values)
+
+	// bars are drawn from zero
+	return gomath.Min(min, 0), gomath.Max(max, 0), n
+
+}
+
+func (h *Histogram) draw(p *plot, c color.RGBA) {
+
+	width := gomath.Max(1.0, 0.7*p.slot())
+	base := p.y(0)
+
+	for i, value := range h.Values {
+
+		if gomath.IsNaN(value) || gomath.IsInf(value, 0) {
+			continue
+		}
+
+		fill := h.Color
+		if fill.A == 0 {
+			fill = risingColor
+			if value < 0 {
+				fill = fallingColor
+			}
+		}
+
+		top, bottom := p.y(value), base
+		if top > bottom {
+			top, bottom = bottom, top
+		}
+
+		p.canvas.rect(p.x(i)-width/2.0, top, width,
+			gomath.Max(1.0, bottom-top), fill)
+
+	}
+
+}
+
+func (b *Band) label() (string, color.RGBA) {
+	return b.Name, b.Color
+}
+
+func (b *Band) bounds() (float64, float64, int) {
+	return valueBounds(b.Upper, b.Lower)
+}
+
+func (b *Band) draw(p *plot, c color.RGBA) {
+
+	n := len(b.Upper)
+	if len(b.Lower) < n {
+		n = len(b.Lower)
+	}
+
+	fill := c
+	fill.A = 48
+
+	for _, run := range runs(n, b.Upper, b.Lower) {
+
+		upper := make([]point, len(run))
+		lower := make([]point, len(run))
+		for i, index := range run {
+			upper[i] = point{p.x(index), p.y(b.Upper[index])}
+			lower[i] = point{p.x(index), p.y(b.Lower[index])}
+		}
+
+		// the outline runs along the upper line and back along the lower
+		outline := append([]point{}, upper...)
+		for i := len(lower) - 1; i >= 0; i-- {
+			outline = append(outline, lower[i])
+		}
+
+		p.canvas.polygon(outline, fill)
+		p.canvas.polyline(upper, c, 1.0)
+		p.canvas.polyline(lower, c, 1.0)
+
+	}
+
+}
+
+func (c *Candles) label() (string, color.RGBA) {
+	return c.Name, risingColor
+}
+
+func (c *Candles) bounds() (float64, float64, int) {
+
+	highs := make([]float64, len(c.Candles))
+	lows := make([]float64, len(c.Candles))
+	for i, candle := range c.Candles {
+		highs[i], lows[i] = candle.High, candle.Low
+	}
+
+	return valueBounds(highs, lows)
+
+}
+
+func (c *Candles) draw(p *plot, _ color.RGBA) {
+
+	width := gomath.Max(1.0, 0.7*p.slot())
+
+	for i, candle := range c.Candles {
+
+		fill := risingColor
+		if candle.Close < candle.Open {
+			fill = fallingColor
+		}
+
+		x := p.x(i)
+
+		// the wick spans the range of the candle and the body spans its open
+		// and close
+		p.canvas.polyline([]point{{x, p.y(candle.High)},
+			{x, p.y(candle.Low)}}, fill, 1.0)
+
+		top := p.y(gomath.Max(candle.Open, candle.Close))
+		bottom := p.y(gomath.Min(candle.Open, candle.Close))
+		p.canvas.rect(x-width/2.0, top, width, gomath.Max(1.0, bottom-top),
+			fill)
+
+	}
+
+}
+
+// validate returns an error if the chart cannot be drawn.
+func (c *Chart) validate() error {
+
+	if len(c.Panels) == 0 {
+		return errors.New("chart has no panels")
+	}
+
+	if c.Width < 0 || c.Height < 0 {
+		return errors.New("chart size cannot be negative")
+	}
+
+	for i, panel := range c.Panels {
+		if panel == nil {
+			return fmt.Errorf("chart panel %d is nil", i)
+		}
+		if panel.Weight < 0 {
+			return fmt.Errorf("chart panel %d weight cannot be negative", i)
+		}
+	}
+
+	return nil
+
+}
+
+// size returns the size of the chart in pixels.
+func (c *Chart) size() (float64, float64) {
+
+	width, height := c.Width, c.Height
+	if width == 0 {
+		width = DefaultWidth
+	}
+	if height == 0 {
+		height = DefaultHeight
+	}
+
+	return float64(width), float64(height)
+
+}
+
+// draw draws the chart on a canvas.
+func (c *Chart) draw(cv canvas) {
+
+	width, height := c.size()
+	cv.rect(0, 0, width, height, backgroundColor)
+
+	if c.Title != "" {
+		cv.text(width/2.0, 20, c.Title, textColor, anchorMiddle)
+	}
+
+	// every panel shares the number of samples in the longest series
+	samples := len(c.Times)
+	for _, panel := range c.Panels {
+		for _, series := range panel.Series {
+			if _, _, n := series.bounds(); n > samples {
+				samples = n
+			}
+		}
+	}
+	if samples == 0 {
+		samples = 1
+	}
+
+	// divide the height between the panels by weight
+	var total float64
+	for _, panel := range c.Panels {
+		total += panelWeight(panel)
+	}
+
+	plotWidth := width - marginLeft - marginRight
+	available := height - marginTop - marginBottom -
+		panelGap*float64(len(c.Panels)-1)
+
+	top := marginTop
+	for i, panel := range c.Panels {
+
+		p := &plot{
+			canvas:  cv,
+			left:    marginLeft,
+			top:     top,
+			width:   plotWidth,
+			height:  available * panelWeight(panel) / total,
+			samples: samples,
+		}
+
+		c.drawPanel(p, panel, i == len(c.Panels)-1)
+
+		top += p.height + panelGap
+
+	}
+
+}
+
+// panelWeight returns the weight of a panel, treating zero as 1.
+func panelWeight(panel *Panel) float64 {
+
+	if panel.Weight == 0 {
+		return 1.0
+	}
+
+	return panel.Weight
+
+}
+
+// drawPanel draws a panel, labeling the time axis beneath it if it is the
+// bottom panel.
+func (c *Chart) drawPanel(p *plot, panel *Panel, bottom bool) {
+
+	// the vertical axis spans every value drawn in the panel
+	p.min, p.max, _ = valueBounds(panel.Levels)
+	for _, series := range panel.Series {
+		min, max, _ := series.bounds()
+		p.min, p.max = gomath.Min(p.min, min), gomath.Max(p.max, max)
+	}
+	for _, marker := range panel.Markers {
+		p.min = gomath.Min(p.min, marker.Value)
+		p.max = gomath.Max(p.max, marker.Value)
+	}
+
+	if gomath.IsInf(p.min, 0) || gomath.IsInf(p.max, 0) {
+		p.min, p.max = 0, 1
+	}
+
+	// pad the axis so that values do not touch the frame
+	padding := (p.max - p.min) * 0.05
+	if padding == 0 {
+		padding = gomath.Max(gomath.Abs(p.max)*0.05, 1.0)
+	}
+	p.min, p.max = p.min-padding, p.max+padding
+
+	for _, tick := range ticks(p.min, p.max, 5) {
+		y := p.y(tick)
+		p.canvas.polyline([]point{{p.left, y}, {p.left + p.width, y}},
+			gridColor, 1.0)
+		p.canvas.text(p.left+p.width+6, y+4, formatTick(tick), textColor,
+			anchorStart)
+	}
+
+	for _, level := range panel.Levels {
+		y := p.y(level)
+		p.canvas.polyline([]point{{p.left, y}, {p.left + p.width, y}},
+			levelColor, 1.0)
+	}
+
+	// draw the series and name them in the legend
+	legend := p.left + 6
+	if panel.Title != "" {
+		p.canvas.text(legend, p.top+14, panel.Title, textColor, anchorStart)
+		legend += float64(len(panel.Title)+2) * 7
+	}
+
+	next := 0
+	for _, series := range panel.Series {
+
+		name, c := series.label()
+		if c.A == 0 {
+			c = palette[next%len(palette)]
+			next++
+		}
+
+		series.draw(p, c)
+
+		if name != "" {
+			p.canvas.text(legend, p.top+14, name, c, anchorStart)
+			legend += float64(len(name)+2) * 7
+		}
+
+	}
+
+	for _, marker := range panel.Markers {
+		drawMarker(p, marker)
+	}
+
+	// frame the panel
+	p.canvas.polyline([]point{
+		{p.left, p.top}, {p.left + p.width, p.top},
+		{p.left + p.width, p.top + p.height}, {p.left, p.top + p.height},
+		{p.left, p.top},
+	}, frameColor, 1.0)
+
+	if bottom {
+		c.drawTimeAxis(p)
+	}
+
+}
+
+// drawMarker draws a trade signal marker.
+func drawMarker(p *plot, marker Marker) {
+
+	const size = 6.0
+
+	x, y := p.x(marker.Index), p.y(marker.Value)
+
+	if marker.Signal == MarkerSell {
+		p.canvas.polygon([]point{{x - size, y - 2*size}, {x + size, y - 2*size},
+			{x, y - 2}}, fallingColor)
+		if marker.Label != "" {
+			p.canvas.text(x, y-2*size-4, marker.Label, fallingColor,
+				anchorMiddle)
+		}
+		return
+	}
+
+	p.canvas.polygon([]point{{x - size, y + 2*size}, {x + size, y + 2*size},
+		{x, y + 2}}, risingColor)
+	if marker.Label != "" {
+		p.canvas.text(x, y+2*size+12, marker.Label, risingColor,
+			anchorMiddle)
+	}
+
+}
+
+// drawTimeAxis labels the time axis beneath a panel.
+func (c *Chart) drawTimeAxis(p *plot) {
+
+	const labels = 6
+
+	step := p.samples / labels
+	if step < 1 {
+		step = 1
+	}
+
+	layout := timeLayout(c.Times)
+
+	for i := 0; i < p.samples; i += step {
+
+		label := fmt.Sprint(i)
+		if i < len(c.Times) {
+			label = c.Times[i].UTC().Format(layout)
+		}
+
+		x := p.x(i)
+		p.canvas.polyline([]point{{x, p.top + p.height},
+			{x, p.top + p.height + 4}}, frameColor, 1.0)
+		p.canvas.text(x, p.top+p.height+16, label, textColor, anchorMiddle)
+
+	}
+
+}
+
+// timeLayout returns a layout for time axis labels suited to the span of the
+// timestamps.
+func timeLayout(times []time.Time) string {
+
+	if len(times) < 2 {
+		return "2006-01-02 15:04"
+	}
+
+	span := times[len(times)-1].Sub(times[0])
+	switch {
+	case span >= 365*24*time.Hour:
+		return "2006-01-02"
+	case span >= 3*24*time.Hour:
+		return "Jan 02 15:04"
+	case span >= time.Hour:
+		return "15:04"
+	}
+
+	return "15:04:05"
+
+}
+
+// ticks returns evenly spaced values at round numbers within a range, about
+// count of them.
+func ticks(min, max float64, count int) []float64 {
+
+	step := niceStep((max - min) / float64(count))
+	if step <= 0 || gomath.IsNaN(step) || gomath.IsInf(step, 0) {
+		return nil
+	}
+
+	var result []float64
+	for k := gomath.Ceil(min / step); k*step <= max; k++ {
+		// multiply rather than accumulate steps to avoid rounding error
+		tick := k * step
+		if k == 0 {
+			tick = 0
+		}
+		result = append(result, tick)
+	}
+
+	return result
+
+}
+
+// niceStep rounds a step up to 1, 2 or 5 times a power of ten.
+func niceStep(step float64) float64 {
+
+	if step <= 0 {
+		return 0
+	}
+
+	magnitude := gomath.Pow(10, gomath.Floor(gomath.Log10(step)))
+	for _, multiple := range []float64{1, 2, 5, 10} {
+		if multiple*magnitude >= step {
+			return multiple * magnitude
+		}
+	}
+
+	return 10 * magnitude
+
+}
+
+// formatTick formats a tick value compactly.
+func formatTick(value float64) string {
+	return fmt.Sprintf("%.6g", value)
+}
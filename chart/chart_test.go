@@ -0,0 +1,195 @@
+package chart_test
+
+import (
+	"bytes"
+	gomath "math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bsladewski/lapis/chart"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// testCandles returns hourly candles that rise and then fall.
+func testCandles() []stream.Candle {
+
+	closes := []float64{100, 104, 103, 108, 112, 109, 105, 101, 98, 102}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	candles := make([]stream.Candle, len(closes))
+	open := 100.0
+	for i, c := range closes {
+		candles[i] = stream.Candle{
+			Timestamp: start.Add(time.Duration(i) * time.Hour),
+			Open:      open,
+			High:      gomath.Max(open, c) + 1,
+			Low:       gomath.Min(open, c) - 1,
+			Close:     c,
+			Volume:    1,
+		}
+		open = c
+	}
+
+	return candles
+
+}
+
+// testChart returns a chart with a price panel and an oscillator panel.
+func testChart() *chart.Chart {
+
+	candles := testCandles()
+
+	times := make([]time.Time, len(candles))
+	closes := make([]float64, len(candles))
+	for i, candle := range candles {
+		times[i] = candle.Timestamp
+		closes[i] = candle.Close
+	}
+
+	nan := gomath.NaN()
+	upper := []float64{nan, nan, 106, 110, 114, 113, 111, 107, 103, 104}
+	lower := []float64{nan, nan, 98, 100, 104, 103, 99, 95, 93, 96}
+	histogram := []float64{nan, 1, 2, -1, 3, -2, -3, -1, nan, 2}
+
+	return &chart.Chart{
+		Title:  "BTC <USD>",
+		Width:  400,
+		Height: 300,
+		Times:  times,
+		Panels: []*chart.Panel{
+			{
+				Title:  "price",
+				Weight: 3,
+				Series: []chart.Series{
+					&chart.Candles{Name: "candles", Candles: candles},
+					&chart.Line{Name: "close", Values: closes},
+					&chart.Band{Name: "band", Upper: upper, Lower: lower},
+				},
+				Markers: []chart.Marker{
+					{Index: 1, Value: 104, Signal: chart.MarkerBuy},
+					{Index: 5, Value: 109, Signal: chart.MarkerSell, Label: "exit"},
+				},
+			},
+			{
+				Title: "oscillator",
+				Series: []chart.Series{
+					&chart.Histogram{Name: "histogram", Values: histogram},
+				},
+				Levels: []float64{0},
+			},
+		},
+	}
+
+}
+
+func TestSVG(t *testing.T) {
+
+	var buf bytes.Buffer
+	if err := testChart().SVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	svg := buf.String()
+
+	if !strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" `+
+		`width="400" height="300"`) {
+		t.Fatalf("unexpected svg header: %.80s", svg)
+	}
+
+	if !strings.HasSuffix(svg, "</svg>\n") {
+		t.Fatal("svg document is not closed")
+	}
+
+	// titles and legends are labeled with escaped text
+	for _, text := range []string{"BTC &lt;USD&gt;", ">price<",
+		">oscillator<", ">close<", ">band<", ">histogram<", ">exit<"} {
+		if !strings.Contains(svg, text) {
+			t.Errorf("expected svg to contain %q", text)
+		}
+	}
+
+	if strings.Contains(svg, "NaN") {
+		t.Error("expected missing values to be skipped")
+	}
+
+	for _, element := range []string{"<polyline", "<polygon", "<rect",
+		"<text"} {
+		if !strings.Contains(svg, element) {
+			t.Errorf("expected svg to contain %s elements", element)
+		}
+	}
+
+}
+
+func TestChartDefaultSize(t *testing.T) {
+
+	c := testChart()
+	c.Width, c.Height = 0, 0
+
+	var buf bytes.Buffer
+	if err := c.SVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), `width="1200" height="800"`) {
+		t.Fatalf("unexpected svg header: %.80s", buf.String())
+	}
+
+}
+
+func TestChartEmptySeries(t *testing.T) {
+
+	c := &chart.Chart{
+		Panels: []*chart.Panel{
+			{Series: []chart.Series{&chart.Line{Name: "empty"}}},
+			{Series: []chart.Series{&chart.Line{
+				Name:   "missing",
+				Values: []float64{gomath.NaN(), gomath.NaN()},
+			}}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := c.SVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(buf.String(), "NaN") ||
+		strings.Contains(buf.String(), "Inf") {
+		t.Fatal("expected empty series to be drawn without invalid values")
+	}
+
+}
+
+func TestChartInvalid(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		chart *chart.Chart
+	}{
+		{"no panels", &chart.Chart{}},
+		{"negative size", &chart.Chart{
+			Width:  -1,
+			Panels: []*chart.Panel{{}},
+		}},
+		{"nil panel", &chart.Chart{Panels: []*chart.Panel{nil}}},
+		{"negative weight", &chart.Chart{
+			Panels: []*chart.Panel{{Weight: -1}},
+		}},
+	}
+
+	for _, c := range cases {
+
+		var buf bytes.Buffer
+		if err := c.chart.SVG(&buf); err == nil {
+			t.Errorf("%s: expected svg error", c.name)
+		}
+
+		if err := c.chart.PNG(&buf); err == nil {
+			t.Errorf("%s: expected png error", c.name)
+		}
+
+	}
+
+}
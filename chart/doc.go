@@ -0,0 +1,5 @@
+// Package chart renders series of stream data as charts in SVG and PNG
+// formats without depending on a browser. A chart stacks panels that share a
+// time axis, such as price candles above the oscillators computed from them;
+// a recorder builds a chart from streams as they are read.
+package chart
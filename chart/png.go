@@ -0,0 +1,177 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	gomath "math"
+	"sort"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// rasterCanvas draws a chart on an image.
+type rasterCanvas struct {
+	img *image.RGBA
+}
+
+// PNG writes the chart to the supplied writer as a PNG image.
+func (c *Chart) PNG(w io.Writer) error {
+
+	img, err := c.Image()
+	if err != nil {
+		return err
+	}
+
+	return png.Encode(w, img)
+
+}
+
+// Image draws the chart on an image.
+func (c *Chart) Image() (*image.RGBA, error) {
+
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	width, height := c.size()
+
+	cv := &rasterCanvas{
+		img: image.NewRGBA(image.Rect(0, 0, int(width), int(height))),
+	}
+
+	c.draw(cv)
+
+	return cv.img, nil
+
+}
+
+// blend draws a pixel of a color over the image.
+func (r *rasterCanvas) blend(x, y int, c color.RGBA) {
+
+	if !(image.Point{x, y}.In(r.img.Rect)) || c.A == 0 {
+		return
+	}
+
+	if c.A == 255 {
+		r.img.SetRGBA(x, y, c)
+		return
+	}
+
+	dst := r.img.RGBAAt(x, y)
+	a := float64(c.A) / 255.0
+	mix := func(s, d uint8) uint8 {
+		return uint8(gomath.Round(float64(s)*a + float64(d)*(1-a)))
+	}
+
+	r.img.SetRGBA(x, y, color.RGBA{
+		R: mix(c.R, dst.R),
+		G: mix(c.G, dst.G),
+		B: mix(c.B, dst.B),
+		A: uint8(gomath.Round(255*a + float64(dst.A)*(1-a))),
+	})
+
+}
+
+func (r *rasterCanvas) polyline(points []point, c color.RGBA,
+	width float64) {
+
+	// draw each segment as a rectangle of the line width
+	half := gomath.Max(width, 1.0) / 2.0
+
+	for i := 1; i < len(points); i++ {
+
+		a, b := points[i-1], points[i]
+
+		dx, dy := b.x-a.x, b.y-a.y
+		length := gomath.Hypot(dx, dy)
+		if length == 0 {
+			continue
+		}
+
+		// offset the segment perpendicular to its direction, extending it by
+		// half the width at each end so that segments join without gaps
+		nx, ny := -dy/length*half, dx/length*half
+		ex, ey := dx/length*half, dy/length*half
+
+		r.polygon([]point{
+			{a.x + nx - ex, a.y + ny - ey},
+			{b.x + nx + ex, b.y + ny + ey},
+			{b.x - nx + ex, b.y - ny + ey},
+			{a.x - nx - ex, a.y - ny - ey},
+		}, c)
+
+	}
+
+}
+
+func (r *rasterCanvas) polygon(points []point, fill color.RGBA) {
+
+	if len(points) < 3 {
+		return
+	}
+
+	minY, maxY := points[0].y, points[0].y
+	for _, p := range points {
+		minY, maxY = gomath.Min(minY, p.y), gomath.Max(maxY, p.y)
+	}
+
+	// fill each row between pairs of edge crossings at the center of the row
+	// using the even-odd rule
+	for y := int(gomath.Floor(minY)); y <= int(gomath.Ceil(maxY)); y++ {
+
+		center := float64(y) + 0.5
+
+		var crossings []float64
+		for i := range points {
+			a, b := points[i], points[(i+1)%len(points)]
+			if (a.y <= center) != (b.y <= center) {
+				crossings = append(crossings,
+					a.x+(center-a.y)*(b.x-a.x)/(b.y-a.y))
+			}
+		}
+
+		sort.Float64s(crossings)
+
+		for i := 0; i+1 < len(crossings); i += 2 {
+			start := int(gomath.Ceil(crossings[i] - 0.5))
+			end := int(gomath.Ceil(crossings[i+1] - 0.5))
+			for x := start; x < end; x++ {
+				r.blend(x, y, fill)
+			}
+		}
+
+	}
+
+}
+
+func (r *rasterCanvas) rect(x, y, w, h float64, fill color.RGBA) {
+
+	r.polygon([]point{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}}, fill)
+
+}
+
+func (r *rasterCanvas) text(x, y float64, text string, c color.RGBA,
+	a anchor) {
+
+	d := &font.Drawer{
+		Dst:  r.img,
+		Src:  image.NewUniform(c),
+		Face: basicfont.Face7x13,
+	}
+
+	width := float64(d.MeasureString(text)) / 64.0
+	switch a {
+	case anchorMiddle:
+		x -= width / 2.0
+	case anchorEnd:
+		x -= width
+	}
+
+	d.Dot = fixed.P(int(gomath.Round(x)), int(gomath.Round(y)))
+	d.DrawString(text)
+
+}
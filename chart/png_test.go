@@ -0,0 +1,55 @@
+package chart_test
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestPNG(t *testing.T) {
+
+	var buf bytes.Buffer
+	if err := testChart().PNG(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dx() != 400 || bounds.Dy() != 300 {
+		t.Fatalf("expected 400x300 image, got %dx%d", bounds.Dx(),
+			bounds.Dy())
+	}
+
+	// the corners of the chart are background
+	white := color.RGBA{255, 255, 255, 255}
+	if c := color.RGBAModel.Convert(img.At(0, 0)); c != white {
+		t.Errorf("expected white background, got %v", c)
+	}
+
+	// rising and falling candles are drawn in distinct colors
+	rising := color.RGBA{38, 166, 91, 255}
+	falling := color.RGBA{214, 69, 65, 255}
+
+	var risingFound, fallingFound bool
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			switch color.RGBAModel.Convert(img.At(x, y)) {
+			case rising:
+				risingFound = true
+			case falling:
+				fallingFound = true
+			}
+		}
+	}
+
+	if !risingFound || !fallingFound {
+		t.Errorf("expected rising and falling candles, found rising: %t, "+
+			"falling: %t", risingFound, fallingFound)
+	}
+
+}
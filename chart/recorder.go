@@ -0,0 +1,376 @@
+package chart
+
+import (
+	"fmt"
+	gomath "math"
+	"time"
+
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// recording is a series recorded from a stream.
+type recording interface {
+	// series returns a snapshot of the recorded series.
+	series() Series
+}
+
+// recordedPanel holds the series and signals recorded for a panel.
+type recordedPanel struct {
+	title      string
+	weight     float64
+	levels     []float64
+	recordings []recording
+	signals    []*util.Series[float64]
+}
+
+// A Recorder builds a chart from streams as they are read; each recording
+// stream passes values through while recording them as a series in a panel.
+// Streams recorded together are expected to be read in step so that sample i
+// of every stream shares a position on the time axis. A chart may be taken
+// from the recorder at any time, including while streams are being read.
+type Recorder struct {
+	title  string
+	panels []*recordedPanel
+	times  *util.Series[time.Time]
+}
+
+// NewRecorder returns a recorder for a chart with the specified title.
+func NewRecorder(title string) *Recorder {
+
+	return &Recorder{
+		title: title,
+	}
+
+}
+
+// Panel adds a panel to the chart with the specified title and height
+// relative to other panels, returning the index of the panel; panels are drawn
+// from top to bottom in the order they are added.
+func (r *Recorder) Panel(title string, weight float64) int {
+
+	r.panels = append(r.panels, &recordedPanel{
+		title:  title,
+		weight: weight,
+	})
+
+	return len(r.panels) - 1
+
+}
+
+// panel returns the panel at an index, adding panels as needed.
+func (r *Recorder) panel(index int) *recordedPanel {
+
+	for len(r.panels) <= index {
+		r.Panel("", 0)
+	}
+
+	return r.panels[index]
+
+}
+
+// Levels marks values in a panel with horizontal lines.
+func (r *Recorder) Levels(panel int, levels ...float64) {
+
+	p := r.panel(panel)
+	p.levels = append(p.levels, levels...)
+
+}
+
+// recordedValues is a recording of the values of a stream.
+type recordedValues struct {
+	name      string
+	histogram bool
+	values    *util.Series[float64]
+	in        stream.Stream
+}
+
+func (v *recordedValues) Next() (float64, error) {
+
+	value, err := v.in.Next()
+	if err == nil {
+		v.values.Append(value)
+	} else if err == stream.ErrNotReady {
+		// values that are not ready hold their place on the time axis
+		v.values.Append(gomath.NaN())
+	}
+
+	return value, err
+
+}
+
+func (v *recordedValues) Close() {
+	v.in.Close()
+}
+
+func (v *recordedValues) series() Series {
+
+	if v.histogram {
+		return &Histogram{Name: v.name, Values: v.values.Snapshot()}
+	}
+
+	return &Line{Name: v.name, Values: v.values.Snapshot()}
+
+}
+
+// Line returns a stream that records the values of the input stream as a line
+// in a panel.
+func (r *Recorder) Line(panel int, name string,
+	in stream.Stream) stream.Stream {
+
+	v := &recordedValues{
+		name:   name,
+		values: util.NewSeries[float64](0),
+		in:     in,
+	}
+
+	p := r.panel(panel)
+	p.recordings = append(p.recordings, v)
+
+	return v
+
+}
+
+// Histogram returns a stream that records the values of the input stream as a
+// histogram in a panel.
+func (r *Recorder) Histogram(panel int, name string,
+	in stream.Stream) stream.Stream {
+
+	v := &recordedValues{
+		name:      name,
+		histogram: true,
+		values:    util.NewSeries[float64](0),
+		in:        in,
+	}
+
+	p := r.panel(panel)
+	p.recordings = append(p.recordings, v)
+
+	return v
+
+}
+
+// recordedBand is a recording of two components of a multi stream.
+type recordedBand struct {
+	name   string
+	upper  int
+	lower  int
+	values *util.Series[[2]float64]
+	in     stream.MultiStream
+}
+
+func (b *recordedBand) Components() []string {
+	return b.in.Components()
+}
+
+func (b *recordedBand) Next() ([]float64, error) {
+
+	values, err := b.in.Next()
+	if err == nil {
+		b.values.Append([2]float64{values[b.upper], values[b.lower]})
+	} else if err == stream.ErrNotReady {
+		b.values.Append([2]float64{gomath.NaN(), gomath.NaN()})
+	}
+
+	return values, err
+
+}
+
+func (b *recordedBand) Close() {
+	b.in.Close()
+}
+
+func (b *recordedBand) series() Series {
+
+	values := b.values.Snapshot()
+
+	band := &Band{
+		Name:  b.name,
+		Upper: make([]float64, len(values)),
+		Lower: make([]float64, len(values)),
+	}
+
+	for i, pair := range values {
+		band.Upper[i], band.Lower[i] = pair[0], pair[1]
+	}
+
+	return band
+
+}
+
+// Band returns a multi stream that records the named upper and lower
+// components of the input multi stream as a filled band in a panel.
+func (r *Recorder) Band(panel int, name string, in stream.MultiStream, upper,
+	lower string) (stream.MultiStream, error) {
+
+	b := &recordedBand{
+		name:   name,
+		upper:  -1,
+		lower:  -1,
+		values: util.NewSeries[[2]float64](0),
+		in:     in,
+	}
+
+	for i, component := range in.Components() {
+		if component == upper {
+			b.upper = i
+		}
+		if component == lower {
+			b.lower = i
+		}
+	}
+
+	if b.upper < 0 || b.lower < 0 {
+		return nil, fmt.Errorf("invalid band components: %s, %s", upper,
+			lower)
+	}
+
+	p := r.panel(panel)
+	p.recordings = append(p.recordings, b)
+
+	return b, nil
+
+}
+
+// recordedCandles is a recording of a candle stream.
+type recordedCandles struct {
+	name    string
+	candles *util.Series[stream.Candle]
+	times   *util.Series[time.Time]
+	in      stream.CandleStream
+}
+
+func (c *recordedCandles) Next() (stream.Candle, error) {
+
+	candle, err := c.in.Next()
+	if err == nil {
+		c.candles.Append(candle)
+		if c.times != nil {
+			c.times.Append(candle.Timestamp)
+		}
+	}
+
+	return candle, err
+
+}
+
+func (c *recordedCandles) Close() {
+	c.in.Close()
+}
+
+func (c *recordedCandles) series() Series {
+	return &Candles{Name: c.name, Candles: c.candles.Snapshot()}
+}
+
+// Candles returns a candle stream that records the input candles as
+// candlesticks in a panel; the timestamps of the first candle stream recorded
+// label the time axis of the chart.
+func (r *Recorder) Candles(panel int, name string,
+	in stream.CandleStream) stream.CandleStream {
+
+	c := &recordedCandles{
+		name:    name,
+		candles: util.NewSeries[stream.Candle](0),
+		in:      in,
+	}
+
+	if r.times == nil {
+		r.times = util.NewSeries[time.Time](0)
+		c.times = r.times
+	}
+
+	p := r.panel(panel)
+	p.recordings = append(p.recordings, c)
+
+	return c
+
+}
+
+// Signals returns a stream that records trade signals in a panel, a buy
+// signal for each positive value of the input stream and a sell signal for
+// each negative value, e.g. the output of a pattern signal stream. Markers
+// point at the close of the first candles recorded in the panel, or at the
+// value of the first line if the panel has no candles.
+func (r *Recorder) Signals(panel int, in stream.Stream) stream.Stream {
+
+	v := &recordedValues{
+		values: util.NewSeries[float64](0),
+		in:     in,
+	}
+
+	p := r.panel(panel)
+	p.signals = append(p.signals, v.values)
+
+	return v
+
+}
+
+// Chart returns a chart of the data recorded so far.
+func (r *Recorder) Chart() *Chart {
+
+	c := &Chart{Title: r.title}
+	if r.times != nil {
+		c.Times = r.times.Snapshot()
+	}
+
+	for _, recorded := range r.panels {
+
+		panel := &Panel{
+			Title:  recorded.title,
+			Weight: recorded.weight,
+			Levels: append([]float64{}, recorded.levels...),
+		}
+
+		for _, recording := range recorded.recordings {
+			panel.Series = append(panel.Series, recording.series())
+		}
+
+		prices := signalPrices(panel.Series)
+		for _, signals := range recorded.signals {
+			for i, signal := range signals.Snapshot() {
+
+				if i >= len(prices) || gomath.IsNaN(prices[i]) ||
+					signal == 0 || gomath.IsNaN(signal) {
+					continue
+				}
+
+				marker := Marker{Index: i, Value: prices[i]}
+				if signal < 0 {
+					marker.Signal = MarkerSell
+				}
+
+				panel.Markers = append(panel.Markers, marker)
+
+			}
+		}
+
+		c.Panels = append(c.Panels, panel)
+
+	}
+
+	return c
+
+}
+
+// signalPrices returns the prices that signal markers in a panel point at.
+func signalPrices(series []Series) []float64 {
+
+	for _, s := range series {
+		if candles, ok := s.(*Candles); ok {
+			prices := make([]float64, len(candles.Candles))
+			for i, candle := range candles.Candles {
+				prices[i] = candle.Close
+			}
+			return prices
+		}
+	}
+
+	for _, s := range series {
+		if line, ok := s.(*Line); ok {
+			return line.Values
+		}
+	}
+
+	return nil
+
+}
@@ -0,0 +1,160 @@
+package chart_test
+
+import (
+	"bytes"
+	gomath "math"
+	"testing"
+
+	"github.com/bsladewski/lapis/chart"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// notReadyStream returns each value of a list, flagging the first values as
+// not ready.
+type notReadyStream struct {
+	warmUp int
+	stream.Stream
+}
+
+func (s *notReadyStream) Next() (float64, error) {
+
+	value, err := s.Stream.Next()
+	if err == nil && s.warmUp > 0 {
+		s.warmUp--
+		return value, stream.ErrNotReady
+	}
+
+	return value, err
+
+}
+
+func TestRecorder(t *testing.T) {
+
+	candles := testCandles()
+	closes := make([]float64, len(candles))
+	for i, candle := range candles {
+		closes[i] = candle.Close
+	}
+
+	r := chart.NewRecorder("recorded")
+	price := r.Panel("price", 3)
+	oscillator := r.Panel("oscillator", 1)
+	r.Levels(oscillator, -1, 1)
+
+	cs := r.Candles(price, "candles", input.NewCandleListStream(candles))
+	line := r.Line(price, "close", input.NewListStream(closes))
+	histogram := r.Histogram(oscillator, "histogram", &notReadyStream{
+		warmUp: 2,
+		Stream: input.NewListStream(closes),
+	})
+	signals := r.Signals(price, input.NewListStream(
+		[]float64{0, 1, 0, 0, 0, -1, 0, 0, 0, gomath.NaN()}))
+
+	band, err := r.Band(price, "band", input.NewMultiListStream(
+		[]string{"upper", "middle", "lower"},
+		[][]float64{{3, 2, 1}, {4, 3, 2}}), "upper", "lower")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range candles {
+
+		if _, err := cs.Next(); err != nil {
+			t.Fatal(err)
+		}
+
+		if value, err := line.Next(); err != nil || value != closes[i] {
+			t.Fatalf("expected line to pass %f, got %f, %v", closes[i],
+				value, err)
+		}
+
+		if _, err := histogram.Next(); err != nil &&
+			err != stream.ErrNotReady {
+			t.Fatal(err)
+		}
+
+		if _, err := signals.Next(); err != nil {
+			t.Fatal(err)
+		}
+
+	}
+
+	for {
+		if _, err := band.Next(); err == stream.ErrEndOfStream {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the end of a recorded stream is not recorded
+	if _, err := line.Next(); err != stream.ErrEndOfStream {
+		t.Fatalf("expected end of stream, got %v", err)
+	}
+
+	c := r.Chart()
+
+	if c.Title != "recorded" || len(c.Times) != len(candles) ||
+		len(c.Panels) != 2 {
+		t.Fatalf("unexpected chart: %q, %d times, %d panels", c.Title,
+			len(c.Times), len(c.Panels))
+	}
+
+	if c.Panels[0].Weight != 3 || len(c.Panels[0].Series) != 3 ||
+		len(c.Panels[1].Series) != 1 || len(c.Panels[1].Levels) != 2 {
+		t.Fatal("unexpected chart panels")
+	}
+
+	if recorded := c.Panels[0].Series[1].(*chart.Line); len(
+		recorded.Values) != len(closes) {
+		t.Fatalf("expected %d line values, got %d", len(closes),
+			len(recorded.Values))
+	}
+
+	recorded := c.Panels[1].Series[0].(*chart.Histogram).Values
+	if !gomath.IsNaN(recorded[0]) || !gomath.IsNaN(recorded[1]) ||
+		recorded[2] != closes[2] {
+		t.Fatalf("expected values that are not ready to be missing, got %v",
+			recorded[:3])
+	}
+
+	b := c.Panels[0].Series[2].(*chart.Band)
+	if len(b.Upper) != 2 || b.Upper[1] != 4 || b.Lower[1] != 2 {
+		t.Fatalf("unexpected band: %v, %v", b.Upper, b.Lower)
+	}
+
+	// signals point at the candle close
+	expected := []chart.Marker{
+		{Index: 1, Value: closes[1], Signal: chart.MarkerBuy},
+		{Index: 5, Value: closes[5], Signal: chart.MarkerSell},
+	}
+
+	markers := c.Panels[0].Markers
+	if len(markers) != len(expected) {
+		t.Fatalf("expected %d markers, got %v", len(expected), markers)
+	}
+
+	for i := range expected {
+		if markers[i] != expected[i] {
+			t.Errorf("expected marker %v, got %v", expected[i], markers[i])
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := c.SVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+}
+
+func TestRecorderInvalidBand(t *testing.T) {
+
+	r := chart.NewRecorder("")
+
+	if _, err := r.Band(0, "band", input.NewMultiListStream(
+		[]string{"upper", "lower"}, nil), "upper", "missing"); err == nil {
+		t.Fatal("expected invalid component error")
+	}
+
+}
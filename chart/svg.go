@@ -0,0 +1,111 @@
+package chart
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// svgCanvas draws a chart as SVG elements.
+type svgCanvas struct {
+	w *bufio.Writer
+}
+
+// SVG writes the chart to the supplied writer as an SVG document.
+func (c *Chart) SVG(w io.Writer) error {
+
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	width, height := c.size()
+
+	cv := &svgCanvas{w: bufio.NewWriter(w)}
+
+	fmt.Fprintf(cv.w, `<svg xmlns="http://www.w3.org/2000/svg" `+
+		`width="%d" height="%d" viewBox="0 0 %d %d" `+
+		`font-family="monospace" font-size="11">`+"\n",
+		int(width), int(height), int(width), int(height))
+
+	c.draw(cv)
+
+	fmt.Fprintln(cv.w, "</svg>")
+
+	return cv.w.Flush()
+
+}
+
+// svgColor formats a color as an SVG color and opacity.
+func svgColor(c color.RGBA) (string, string) {
+
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B),
+		strconv.FormatFloat(float64(c.A)/255.0, 'f', 3, 64)
+
+}
+
+// svgPoints formats points as an SVG points attribute.
+func svgPoints(points []point) string {
+
+	var b strings.Builder
+	for i, p := range points {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%.2f,%.2f", p.x, p.y)
+	}
+
+	return b.String()
+
+}
+
+func (s *svgCanvas) polyline(points []point, c color.RGBA, width float64) {
+
+	stroke, opacity := svgColor(c)
+	fmt.Fprintf(s.w, `<polyline points="%s" fill="none" stroke="%s" `+
+		`stroke-opacity="%s" stroke-width="%.2f" stroke-linejoin="round"/>`+
+		"\n", svgPoints(points), stroke, opacity, width)
+
+}
+
+func (s *svgCanvas) polygon(points []point, fill color.RGBA) {
+
+	paint, opacity := svgColor(fill)
+	fmt.Fprintf(s.w, `<polygon points="%s" fill="%s" fill-opacity="%s"/>`+
+		"\n", svgPoints(points), paint, opacity)
+
+}
+
+func (s *svgCanvas) rect(x, y, w, h float64, fill color.RGBA) {
+
+	paint, opacity := svgColor(fill)
+	fmt.Fprintf(s.w, `<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" `+
+		`fill="%s" fill-opacity="%s"/>`+"\n", x, y, w, h, paint, opacity)
+
+}
+
+func (s *svgCanvas) text(x, y float64, text string, c color.RGBA,
+	a anchor) {
+
+	fill, _ := svgColor(c)
+	anchors := map[anchor]string{
+		anchorStart:  "start",
+		anchorMiddle: "middle",
+		anchorEnd:    "end",
+	}
+
+	fmt.Fprintf(s.w, `<text x="%.2f" y="%.2f" fill="%s" `+
+		`text-anchor="%s">%s</text>`+"\n", x, y, fill, anchors[a],
+		svgEscape(text))
+
+}
+
+// svgEscape escapes text for use in an SVG document.
+func svgEscape(text string) string {
+
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;",
+		`"`, "&quot;").Replace(text)
+
+}
@@ -0,0 +1,360 @@
+package dashboard
+
+import (
+	_ "embed" // embedded dashboard page
+	"encoding/json"
+	"fmt"
+	gomath "math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bsladewski/lapis/output"
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// DefaultHistory is the number of values of each series sent to browsers when
+// they connect if no history is specified.
+const DefaultHistory = 2000
+
+// clientBuffer is the number of events queued for a browser before it is
+// considered too slow and disconnected; browsers reconnect automatically.
+const clientBuffer = 1024
+
+//go:embed index.html
+var page []byte
+
+// A sample is a timestamped value of a series.
+type sample struct {
+	time  time.Time
+	value float64
+}
+
+// MarshalJSON encodes a sample as a pair of the unix time in milliseconds and
+// the value, encoding values that are not finite as null.
+func (s sample) MarshalJSON() ([]byte, error) {
+
+	value := "null"
+	if !gomath.IsNaN(s.value) && !gomath.IsInf(s.value, 0) {
+		value = strconv.FormatFloat(s.value, 'g', -1, 64)
+	}
+
+	return []byte(fmt.Sprintf("[%d,%s]", s.time.UnixMilli(), value)), nil
+
+}
+
+// series holds the recent history of a stream attached to the dashboard.
+type series struct {
+	id      int
+	panel   string
+	name    string
+	history *util.Series[sample]
+}
+
+// A Dashboard is an HTTP handler that serves a page plotting the streams
+// attached to it; the page is served at the root of the handler and events
+// are served at events relative to the root, so the handler may be mounted
+// under any prefix with http.StripPrefix.
+type Dashboard struct {
+	title   string
+	history int
+	clock   func() time.Time
+
+	// mu guards the series and clients and orders each value appended to a
+	// series with the events sent to clients
+	mu      sync.Mutex
+	panels  []string
+	series  []*series
+	clients map[chan []byte]struct{}
+	closed  bool
+}
+
+// NewDashboard returns a dashboard with the specified title that sends
+// browsers up to history recent values of each series when they connect, or
+// DefaultHistory values if history is zero. Values are timestamped by the
+// supplied clock, or by the current time if clock is nil.
+func NewDashboard(title string, history int,
+	clock func() time.Time) *Dashboard {
+
+	if history <= 0 {
+		history = DefaultHistory
+	}
+
+	if clock == nil {
+		clock = time.Now
+	}
+
+	return &Dashboard{
+		title:   title,
+		history: history,
+		clock:   clock,
+		clients: map[chan []byte]struct{}{},
+	}
+
+}
+
+// add adds a series to a panel, creating the panel if it does not exist, and
+// sends the new layout to connected clients.
+func (d *Dashboard) add(panel, name string) *series {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	found := false
+	for _, p := range d.panels {
+		if p == panel {
+			found = true
+			break
+		}
+	}
+	if !found {
+		d.panels = append(d.panels, panel)
+	}
+
+	s := &series{
+		id:      len(d.series),
+		panel:   panel,
+		name:    name,
+		history: util.NewSeries[sample](d.history),
+	}
+	d.series = append(d.series, s)
+
+	// clients rebuild their charts from the layout event
+	d.broadcast(d.layout())
+
+	return s
+
+}
+
+// publish appends a value to a series and sends it to connected clients.
+func (d *Dashboard) publish(s *series, value float64) {
+
+	point := sample{time: d.clock(), value: value}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s.history.Append(point)
+
+	data, err := json.Marshal(struct {
+		ID     int    `json:"id"`
+		Sample sample `json:"sample"`
+	}{s.id, point})
+	if err != nil {
+		return
+	}
+
+	d.broadcast(event("sample", data))
+
+}
+
+// layout returns an event describing every panel and series along with the
+// recent history of each series; the caller must hold the lock.
+func (d *Dashboard) layout() []byte {
+
+	type seriesLayout struct {
+		ID      int      `json:"id"`
+		Name    string   `json:"name"`
+		Samples []sample `json:"samples"`
+	}
+
+	type panelLayout struct {
+		Name   string         `json:"name"`
+		Series []seriesLayout `json:"series"`
+	}
+
+	layout := struct {
+		Title  string        `json:"title"`
+		Panels []panelLayout `json:"panels"`
+	}{Title: d.title, Panels: []panelLayout{}}
+
+	for _, panel := range d.panels {
+
+		p := panelLayout{Name: panel, Series: []seriesLayout{}}
+		for _, s := range d.series {
+			if s.panel == panel {
+				p.Series = append(p.Series, seriesLayout{
+					ID:      s.id,
+					Name:    s.name,
+					Samples: s.history.Snapshot(),
+				})
+			}
+		}
+
+		layout.Panels = append(layout.Panels, p)
+
+	}
+
+	data, err := json.Marshal(layout)
+	if err != nil {
+		return nil
+	}
+
+	return event("layout", data)
+
+}
+
+// event formats a server-sent event.
+func event(name string, data []byte) []byte {
+
+	return []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", name, data))
+
+}
+
+// broadcast queues an event for every connected client, disconnecting clients
+// that have fallen too far behind; the caller must hold the lock.
+func (d *Dashboard) broadcast(message []byte) {
+
+	if message == nil {
+		return
+	}
+
+	for client := range d.clients {
+		select {
+		case client <- message:
+		default:
+			delete(d.clients, client)
+			close(client)
+		}
+	}
+
+}
+
+// Close disconnects every client and refuses new connections so that an HTTP
+// server serving the dashboard may shut down; attached streams continue to
+// pass values through.
+func (d *Dashboard) Close() {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.closed = true
+	for client := range d.clients {
+		delete(d.clients, client)
+		close(client)
+	}
+
+}
+
+// attached is a stream attached to a dashboard.
+type attached struct {
+	dashboard *Dashboard
+	series    *series
+	in        stream.Stream
+}
+
+// Attach adds a series to the named panel that plots each value read from the
+// input stream, returning an output that passes values through and returns
+// the recent history of the series from GetData. Values that are not ready
+// are plotted as gaps. Any output may be attached, since outputs are streams.
+func (d *Dashboard) Attach(panel, name string,
+	in stream.Stream) output.Output[[]float64] {
+
+	return &attached{
+		dashboard: d,
+		series:    d.add(panel, name),
+		in:        in,
+	}
+
+}
+
+func (a *attached) Next() (float64, error) {
+
+	value, err := a.in.Next()
+	if err == nil {
+		a.dashboard.publish(a.series, value)
+	} else if err == stream.ErrNotReady {
+		a.dashboard.publish(a.series, gomath.NaN())
+	}
+
+	return value, err
+
+}
+
+func (a *attached) GetData() []float64 {
+	return values(a.series)
+}
+
+func (a *attached) Close() {
+	a.in.Close()
+}
+
+// values returns the recent history of a series.
+func values(s *series) []float64 {
+
+	history := s.history.Snapshot()
+
+	data := make([]float64, len(history))
+	for i, point := range history {
+		data[i] = point.value
+	}
+
+	return data
+
+}
+
+// attachedMulti is a multi stream attached to a dashboard.
+type attachedMulti struct {
+	dashboard *Dashboard
+	series    []*series
+	in        stream.MultiStream
+}
+
+// AttachMulti adds a series to the named panel for each component of the
+// input multi stream, named by joining the specified name and the component
+// with a dot. The returned output passes sets of values through and returns
+// the recent history of each component from GetData.
+func (d *Dashboard) AttachMulti(panel, name string,
+	in stream.MultiStream) output.MultiOutput[map[string][]float64] {
+
+	a := &attachedMulti{
+		dashboard: d,
+		in:        in,
+	}
+
+	for _, component := range in.Components() {
+		a.series = append(a.series, d.add(panel, name+"."+component))
+	}
+
+	return a
+
+}
+
+func (a *attachedMulti) Components() []string {
+	return a.in.Components()
+}
+
+func (a *attachedMulti) Next() ([]float64, error) {
+
+	values, err := a.in.Next()
+	if err != nil && err != stream.ErrNotReady {
+		return values, err
+	}
+
+	for i, s := range a.series {
+		value := gomath.NaN()
+		if err == nil && i < len(values) {
+			value = values[i]
+		}
+		a.dashboard.publish(s, value)
+	}
+
+	return values, err
+
+}
+
+func (a *attachedMulti) GetData() map[string][]float64 {
+
+	data := map[string][]float64{}
+	for i, component := range a.in.Components() {
+		data[component] = values(a.series[i])
+	}
+
+	return data
+
+}
+
+func (a *attachedMulti) Close() {
+	a.in.Close()
+}
@@ -0,0 +1,129 @@
+package dashboard_test
+
+import (
+	gomath "math"
+	"testing"
+	"time"
+
+	"github.com/bsladewski/lapis/dashboard"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// testClock returns a clock that advances by a second each time it is read.
+func testClock() func() time.Time {
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	return func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+}
+
+// notReady flags the first value of a stream as not ready.
+type notReady struct {
+	flagged bool
+	stream.Stream
+}
+
+func (s *notReady) Next() (float64, error) {
+
+	value, err := s.Stream.Next()
+	if err == nil && !s.flagged {
+		s.flagged = true
+		return value, stream.ErrNotReady
+	}
+
+	return value, err
+
+}
+
+func TestAttach(t *testing.T) {
+
+	d := dashboard.NewDashboard("test", 3, testClock())
+
+	values := []float64{1, 2, 3, 4, 5}
+	out := d.Attach("price", "close", &notReady{
+		Stream: input.NewListStream(values),
+	})
+	defer out.Close()
+
+	for i, expected := range values {
+
+		value, err := out.Next()
+		if i == 0 && err != stream.ErrNotReady {
+			t.Fatalf("expected not ready, got %v", err)
+		} else if i > 0 && err != nil {
+			t.Fatal(err)
+		}
+
+		if value != expected {
+			t.Fatalf("expected value %f, got %f", expected, value)
+		}
+
+	}
+
+	if _, err := out.Next(); err != stream.ErrEndOfStream {
+		t.Fatalf("expected end of stream, got %v", err)
+	}
+
+	// only the most recent history is kept
+	data := out.GetData()
+	if len(data) != 3 || data[0] != 3 || data[2] != 5 {
+		t.Fatalf("expected history [3 4 5], got %v", data)
+	}
+
+}
+
+func TestAttachNotReadyHistory(t *testing.T) {
+
+	d := dashboard.NewDashboard("test", 0, testClock())
+
+	out := d.Attach("price", "close", &notReady{
+		Stream: input.NewListStream([]float64{1, 2}),
+	})
+
+	for i := 0; i < 2; i++ {
+		out.Next()
+	}
+
+	data := out.GetData()
+	if len(data) != 2 || !gomath.IsNaN(data[0]) || data[1] != 2 {
+		t.Fatalf("expected values that are not ready to be gaps, got %v",
+			data)
+	}
+
+}
+
+func TestAttachMulti(t *testing.T) {
+
+	d := dashboard.NewDashboard("test", 0, testClock())
+
+	out := d.AttachMulti("bands", "bb", input.NewMultiListStream(
+		[]string{"upper", "lower"}, [][]float64{{3, 1}, {4, 2}}))
+	defer out.Close()
+
+	if components := out.Components(); len(components) != 2 ||
+		components[0] != "upper" || components[1] != "lower" {
+		t.Fatalf("unexpected components: %v", components)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := out.Next(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := out.Next(); err != stream.ErrEndOfStream {
+		t.Fatalf("expected end of stream, got %v", err)
+	}
+
+	data := out.GetData()
+	if len(data["upper"]) != 2 || data["upper"][1] != 4 ||
+		len(data["lower"]) != 2 || data["lower"][1] != 2 {
+		t.Fatalf("unexpected data: %v", data)
+	}
+
+}
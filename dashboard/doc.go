@@ -0,0 +1,5 @@
+// Package dashboard serves a web page that plots the outputs of running
+// pipelines in real time. Streams are attached to named chart panels and each
+// value read through them is pushed to connected browsers as a server-sent
+// event.
+package dashboard
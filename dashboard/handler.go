@@ -0,0 +1,116 @@
+package dashboard
+
+import (
+	"net/http"
+	"time"
+)
+
+// heartbeat is the interval between comments sent to idle clients so that
+// proxies do not close the connection.
+const heartbeat = 15 * time.Second
+
+// ServeHTTP serves the dashboard page at the root and the event stream at
+// events.
+func (d *Dashboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	switch r.URL.Path {
+	case "/", "":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	case "/events":
+		d.serveEvents(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+
+}
+
+// connect registers a client, returning its event queue seeded with the
+// current layout, or nil if the dashboard has been closed.
+func (d *Dashboard) connect() chan []byte {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return nil
+	}
+
+	client := make(chan []byte, clientBuffer)
+	client <- d.layout()
+	d.clients[client] = struct{}{}
+
+	return client
+
+}
+
+// disconnect removes a client if it is still registered.
+func (d *Dashboard) disconnect(client chan []byte) {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.clients[client]; ok {
+		delete(d.clients, client)
+		close(client)
+	}
+
+}
+
+// serveEvents streams events to a client until it disconnects, falls too far
+// behind or the dashboard is closed.
+func (d *Dashboard) serveEvents(w http.ResponseWriter, r *http.Request) {
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	client := d.connect()
+	if client == nil {
+		http.Error(w, "dashboard closed", http.StatusServiceUnavailable)
+		return
+	}
+	defer d.disconnect(client)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if _, err := w.Write([]byte(": ping\n\n")); err != nil {
+				return
+			}
+			flusher.Flush()
+		case message, ok := <-client:
+			if !ok {
+				return
+			}
+			if _, err := w.Write(message); err != nil {
+				return
+			}
+			// send any events already queued in the same flush
+			for pending := len(client); pending > 0; pending-- {
+				message, ok := <-client
+				if !ok {
+					break
+				}
+				if _, err := w.Write(message); err != nil {
+					return
+				}
+			}
+			flusher.Flush()
+		}
+	}
+
+}
@@ -0,0 +1,166 @@
+package dashboard_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bsladewski/lapis/dashboard"
+	"github.com/bsladewski/lapis/input"
+)
+
+// readEvent reads the next server-sent event, skipping comments.
+func readEvent(t *testing.T, r *bufio.Reader) (string, string) {
+
+	t.Helper()
+
+	var name, data string
+	for {
+
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && name != "":
+			return name, data
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+
+	}
+
+}
+
+func TestServePage(t *testing.T) {
+
+	server := httptest.NewServer(dashboard.NewDashboard("test", 0, nil))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusOK ||
+		!strings.Contains(string(body), `new EventSource("events")`) {
+		t.Fatalf("unexpected page: %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected not found, got %d", resp.StatusCode)
+	}
+
+}
+
+func TestServeEvents(t *testing.T) {
+
+	d := dashboard.NewDashboard("test", 0, testClock())
+
+	out := d.Attach("price", "close", input.NewListStream([]float64{1, 2}))
+	if _, err := out.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(d)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if contentType := resp.Header.Get("Content-Type"); contentType !=
+		"text/event-stream" {
+		t.Fatalf("unexpected content type: %s", contentType)
+	}
+
+	r := bufio.NewReader(resp.Body)
+
+	// clients are sent the layout with the history of each series
+	name, data := readEvent(t, r)
+	if name != "layout" {
+		t.Fatalf("expected layout event, got %s", name)
+	}
+
+	var layout struct {
+		Title  string `json:"title"`
+		Panels []struct {
+			Name   string `json:"name"`
+			Series []struct {
+				ID      int          `json:"id"`
+				Name    string       `json:"name"`
+				Samples [][2]float64 `json:"samples"`
+			} `json:"series"`
+		} `json:"panels"`
+	}
+	if err := json.Unmarshal([]byte(data), &layout); err != nil {
+		t.Fatal(err)
+	}
+
+	if layout.Title != "test" || len(layout.Panels) != 1 ||
+		layout.Panels[0].Name != "price" ||
+		len(layout.Panels[0].Series) != 1 ||
+		layout.Panels[0].Series[0].Name != "close" ||
+		len(layout.Panels[0].Series[0].Samples) != 1 ||
+		layout.Panels[0].Series[0].Samples[0][1] != 1 {
+		t.Fatalf("unexpected layout: %s", data)
+	}
+
+	// values read after connecting are sent as samples
+	if _, err := out.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	name, data = readEvent(t, r)
+	if name != "sample" || data != `{"id":0,"sample":[1577836802000,2]}` {
+		t.Fatalf("unexpected event %s: %s", name, data)
+	}
+
+	// attaching a stream sends a new layout
+	d.Attach("volume", "volume", input.NewListStream(nil))
+
+	name, data = readEvent(t, r)
+	if name != "layout" || !strings.Contains(data, `"name":"volume"`) {
+		t.Fatalf("unexpected event %s: %s", name, data)
+	}
+
+	// closing the dashboard ends the event stream
+	d.Close()
+
+	if _, err := io.ReadAll(r); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err = http.Get(server.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected service unavailable, got %d", resp.StatusCode)
+	}
+
+}
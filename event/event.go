@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/jinzhu/gorm"
 	_ "github.com/mattn/go-sqlite3" // support for SQLite database
@@ -35,6 +36,14 @@ func init() {
 
 }
 
+// DB returns the database used for persistent storage, so that other packages
+// may store their data alongside lapis events.
+func DB() *gorm.DB {
+
+	return db
+
+}
+
 // A Worker is used to execute a lapis event.
 type Worker interface {
 	GetID() uint
@@ -119,6 +128,90 @@ const (
 	Failed
 )
 
+// String returns the name of a worker state.
+func (s WorkerState) String() string {
+
+	switch s {
+	case Pending:
+		return "pending"
+	case Running:
+		return "running"
+	case Finished:
+		return "finished"
+	case Failed:
+		return "failed"
+	}
+
+	return fmt.Sprintf("state(%d)", int(s))
+
+}
+
+// CountWorkers returns the number of event workers in each state; states
+// without workers are included with a count of zero.
+func CountWorkers() (map[WorkerState]int, error) {
+
+	var rows []struct {
+		State WorkerState
+		Count int
+	}
+
+	if err := db.Model(&worker{}).Select("state, count(*) as count").
+		Group("state").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := map[WorkerState]int{
+		Pending:  0,
+		Running:  0,
+		Finished: 0,
+		Failed:   0,
+	}
+
+	for _, row := range rows {
+		counts[row.State] = row.Count
+	}
+
+	return counts, nil
+
+}
+
+// A WorkerRecord describes an event worker as it is stored.
+type WorkerRecord struct {
+	ID        uint
+	Name      string
+	State     WorkerState
+	Notes     string
+	Errors    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ListWorkers returns up to limit event workers, most recently updated first.
+func ListWorkers(limit int) ([]WorkerRecord, error) {
+
+	var workers []worker
+	if err := db.Order("updated_at desc, id desc").Limit(limit).
+		Find(&workers).Error; err != nil {
+		return nil, err
+	}
+
+	records := make([]WorkerRecord, len(workers))
+	for i, w := range workers {
+		records[i] = WorkerRecord{
+			ID:        w.ID,
+			Name:      w.Name,
+			State:     w.State,
+			Notes:     w.Notes,
+			Errors:    w.Errors,
+			CreatedAt: w.CreatedAt,
+			UpdatedAt: w.UpdatedAt,
+		}
+	}
+
+	return records, nil
+
+}
+
 // workItem represents a function to be executed as part of processing an event.
 type workItem struct {
 	critical bool
@@ -146,3 +146,77 @@ func TestEventNegative(t *testing.T) {
 	}
 
 }
+
+// TestCountWorkers tests counting event workers by state.
+func TestCountWorkers(t *testing.T) {
+
+	before, err := event.CountWorkers()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// run a worker that fails
+	w, err := event.NewWorker("count")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.AddWork(func() error {
+
+		return errors.New("failed")
+
+	})
+
+	if err := w.Do(); err == nil {
+		t.Fatal("expected worker error")
+	}
+
+	after, err := event.CountWorkers()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert that the failed worker has been counted
+	if after[event.Failed] != before[event.Failed]+1 {
+		t.Fatalf("expected %d failed workers, got %d",
+			before[event.Failed]+1, after[event.Failed])
+	}
+
+	// assert that every state is counted
+	for _, state := range []event.WorkerState{event.Pending, event.Running,
+		event.Finished, event.Failed} {
+		if _, ok := after[state]; !ok {
+			t.Fatalf("expected count of %s workers", state)
+		}
+	}
+
+}
+
+// TestListWorkers tests listing recent event workers.
+func TestListWorkers(t *testing.T) {
+
+	w, err := event.NewWorker("list")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := event.ListWorkers(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert that the most recently updated worker is listed first
+	if len(records) != 1 {
+		t.Fatalf("expected 1 worker, got %d", len(records))
+	}
+
+	if records[0].ID != w.GetID() || records[0].Name != "list" ||
+		records[0].State != event.Finished {
+		t.Fatalf("unexpected worker: %+v", records[0])
+	}
+
+}
@@ -0,0 +1,109 @@
+package indicator
+
+import (
+	"errors"
+	gomath "math"
+
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// alma is the concrete implementation of a stream that applies an Arnaud
+// Legoux Moving Average function to input data.
+type alma struct {
+	period  int
+	offset  float64
+	sigma   float64
+	weights []float64
+	frame   *util.Ring[float64]
+	in      stream.Stream
+}
+
+// NewALMAStream returns a stream that applies an Arnaud Legoux Moving Average
+// function to input data; the offset, between 0 and 1, positions the peak of
+// the gaussian weights within the window and sigma controls the width of the
+// curve. Common values are an offset of 0.85 and a sigma of 6.
+func NewALMAStream(in stream.Stream, period int, offset,
+	sigma float64) Indicator {
+
+	return &alma{
+		period:  period,
+		offset:  offset,
+		sigma:   sigma,
+		weights: almaWeights(period, offset, sigma),
+		frame:   util.NewRing[float64](period),
+		in:      in,
+	}
+
+}
+
+// almaWeights returns the gaussian weights applied to a window of the specified
+// size ordered from the oldest to the newest value.
+func almaWeights(size int, offset, sigma float64) []float64 {
+
+	if size <= 0 {
+		return nil
+	}
+
+	m := offset * float64(size-1)
+	s := float64(size) / sigma
+
+	weights := make([]float64, size)
+	for i := range weights {
+		weights[i] = gomath.Exp(-gomath.Pow(float64(i)-m, 2) / (2 * s * s))
+	}
+
+	return weights
+
+}
+
+func (a *alma) Next() (float64, error) {
+
+	if a.period <= 0 {
+		return 0.0, errors.New(
+			"arnaud legoux moving average period cannot be negative or zero")
+	}
+
+	if a.sigma <= 0.0 {
+		return 0.0, errors.New(
+			"arnaud legoux moving average sigma must be greater than zero")
+	}
+
+	// retrieve the next piece of input data
+	next, err := a.in.Next()
+	if err != nil && err != stream.ErrNotReady {
+		return 0.0, err
+	}
+
+	// values flagged as not ready, or marked as not ready with NaN, are
+	// passed through unchanged rather than poisoning the average
+	if err == stream.ErrNotReady || gomath.IsNaN(next) {
+		return next, err
+	}
+
+	a.frame.Add(next)
+
+	// until the frame is full weight the values using a curve fitted to the
+	// values received so far
+	weights := a.weights
+	if !a.frame.IsFull() {
+		weights = almaWeights(a.frame.Size(), a.offset, a.sigma)
+	}
+
+	sum, norm := 0.0, 0.0
+	for i, weight := range weights {
+		sum += weight * a.frame.Get(i)
+		norm += weight
+	}
+
+	return sum / norm, nil
+
+}
+
+func (a *alma) Close() {
+	a.in.Close()
+}
+
+func (a *alma) WarmUp() int {
+	return a.period - 1 + inputWarmUp(a.in)
+}
@@ -0,0 +1,35 @@
+package indicator_test
+
+import (
+	"testing"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+)
+
+// TestALMAStream tests calculations performed by an Arnaud Legoux Moving
+// Average stream.
+func TestALMAStream(t *testing.T) {
+
+	// a constant input results in a constant average
+	as := indicator.NewALMAStream(
+		input.NewListStream([]float64{2.0, 2.0, 2.0, 2.0}), 3, 0.85, 6.0)
+	defer as.Close()
+
+	assertStreamOutput(t, as, []float64{2.0, 2.0, 2.0, 2.0})
+
+	// assert that the stream matches the reference values once ready
+	as = indicator.NewALMAStream(input.NewListStream(referencePrices), 9,
+		0.85, 6.0)
+	defer as.Close()
+
+	assertReadyOutput(t, as, []float64{
+		9641.2373459806, 9662.4154973508, 9671.9867013807, 9665.1294117791,
+		9648.5287160106, 9640.7362245994, 9652.0187825258, 9667.1591816236,
+		9678.6139636012, 9690.3043798094, 9703.6392893539, 9695.3662592744,
+		9678.1630939344, 9650.8609439139, 9609.7562099621, 9570.0921220773,
+		9557.6222248756, 9561.5893291281, 9567.4961511241, 9620.2390537663,
+		9685.2280876169, 9738.5090458425,
+	})
+
+}
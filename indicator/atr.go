@@ -0,0 +1,116 @@
+package indicator
+
+import (
+	"errors"
+	gomath "math"
+
+	"github.com/bsladewski/lapis/stream"
+)
+
+// trueRangeState holds the previous close price needed to calculate the true
+// range of a candle.
+type trueRangeState struct {
+	previous float64
+	started  bool
+}
+
+// update returns the true range of a candle; the true range is the greater of
+// the candle's range and the distance from the previous close to the candle's
+// high or low.
+func (t *trueRangeState) update(candle stream.Candle) float64 {
+
+	trueRange := candle.High - candle.Low
+	if t.started {
+		trueRange = gomath.Max(trueRange, gomath.Abs(candle.High-t.previous))
+		trueRange = gomath.Max(trueRange, gomath.Abs(candle.Low-t.previous))
+	}
+
+	t.previous = candle.Close
+	t.started = true
+
+	return trueRange
+
+}
+
+// trueRange is the concrete implementation of a stream that outputs the true
+// range of input candles.
+type trueRange struct {
+	state trueRangeState
+	in    stream.CandleStream
+}
+
+// NewTrueRangeStream returns a stream that outputs the true range of input
+// candles; the first candle has no previous close so its range is output.
+func NewTrueRangeStream(in stream.CandleStream) Indicator {
+
+	return &trueRange{
+		in: in,
+	}
+
+}
+
+func (t *trueRange) Next() (float64, error) {
+
+	// retrieve the next candle
+	candle, err := t.in.Next()
+	if err != nil {
+		return 0.0, err
+	}
+
+	return t.state.update(candle), nil
+
+}
+
+func (t *trueRange) Close() {
+	t.in.Close()
+}
+
+func (t *trueRange) WarmUp() int {
+	return 0
+}
+
+// atr is the concrete implementation of a stream that applies an Average True
+// Range function to input candles.
+type atr struct {
+	period    int
+	trueRange trueRangeState
+	average   *emaState
+	in        stream.CandleStream
+}
+
+// NewATRStream returns a stream that applies Wilder's Average True Range
+// function to input candles.
+func NewATRStream(in stream.CandleStream, period int) Indicator {
+
+	return &atr{
+		period:  period,
+		average: newEMAState(period, 1.0/float64(period), SeedSMA),
+		in:      in,
+	}
+
+}
+
+func (a *atr) Next() (float64, error) {
+
+	if a.period <= 0 {
+		return 0.0, errors.New(
+			"average true range period cannot be negative or zero")
+	}
+
+	// retrieve the next candle
+	candle, err := a.in.Next()
+	if err != nil {
+		return 0.0, err
+	}
+
+	return a.average.update(a.trueRange.update(candle)), nil
+
+}
+
+func (a *atr) Close() {
+	a.in.Close()
+}
+
+func (a *atr) WarmUp() int {
+	return a.period - 1
+}
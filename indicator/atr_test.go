@@ -0,0 +1,36 @@
+package indicator_test
+
+import (
+	"testing"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+)
+
+// TestTrueRangeStream tests calculations performed by a true range stream.
+func TestTrueRangeStream(t *testing.T) {
+
+	// define expected output
+	expectedOutput := []float64{2.0, 2.0, 2.0, 3.0, 2.0}
+
+	ts := indicator.NewTrueRangeStream(
+		input.NewCandleListStream(oscillatorCandles))
+	defer ts.Close()
+
+	assertStreamOutput(t, ts, expectedOutput)
+
+}
+
+// TestATRStream tests calculations performed by an Average True Range stream.
+func TestATRStream(t *testing.T) {
+
+	// define expected output
+	expectedOutput := []float64{2.0, 2.0, 2.0, 2.5, 2.25}
+
+	as := indicator.NewATRStream(input.NewCandleListStream(oscillatorCandles),
+		2)
+	defer as.Close()
+
+	assertStreamOutput(t, as, expectedOutput)
+
+}
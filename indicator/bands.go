@@ -0,0 +1,228 @@
+package indicator
+
+import (
+	"errors"
+	gomath "math"
+
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+const (
+	// BandUpper names the upper band component of a band stream.
+	BandUpper = "upper"
+	// BandMiddle names the middle band component of a band stream.
+	BandMiddle = "middle"
+	// BandLower names the lower band component of a band stream.
+	BandLower = "lower"
+	// BandPercentB names the %B component of a Bollinger Band stream.
+	BandPercentB = "percent_b"
+	// BandBandwidth names the bandwidth component of a Bollinger Band stream.
+	BandBandwidth = "bandwidth"
+)
+
+// bollinger is the concrete implementation of a multi stream that applies a
+// Bollinger Band function to input data.
+type bollinger struct {
+	period int
+	width  float64
+	state  *util.WelfordWindow
+	in     stream.Stream
+}
+
+// NewBollingerStream returns a multi stream that applies a Bollinger Band
+// function to input data; the middle band is the simple moving average of the
+// input and the upper and lower bands are offset from the middle band by width
+// population standard deviations. The stream also outputs %B, the position of
+// the input within the bands, and the bandwidth, the distance between the
+// bands relative to the middle band.
+func NewBollingerStream(in stream.Stream, period int,
+	width float64) MultiIndicator {
+
+	return &bollinger{
+		period: period,
+		width:  width,
+		state:  util.NewWelfordWindow(period),
+		in:     in,
+	}
+
+}
+
+func (b *bollinger) Components() []string {
+	return []string{BandUpper, BandMiddle, BandLower, BandPercentB,
+		BandBandwidth}
+}
+
+func (b *bollinger) Next() ([]float64, error) {
+
+	if b.period <= 0 {
+		return nil, errors.New(
+			"bollinger band period cannot be negative or zero")
+	}
+
+	// retrieve the next piece of input data
+	next, err := b.in.Next()
+	if err != nil && err != stream.ErrNotReady {
+		return nil, err
+	}
+
+	// values flagged as not ready, or marked as not ready with NaN, are
+	// passed through unchanged rather than poisoning the running statistics
+	if err == stream.ErrNotReady || gomath.IsNaN(next) {
+		return []float64{next, next, next, next, next}, err
+	}
+
+	b.state.Add(next)
+
+	middle := b.state.Mean()
+	offset := b.width * gomath.Sqrt(b.state.Variance())
+	upper, lower := middle+offset, middle-offset
+
+	// while the bands have no width the input lies at their center
+	percentB := 0.5
+	if upper != lower {
+		percentB = (next - lower) / (upper - lower)
+	}
+
+	bandwidth := 0.0
+	if middle != 0.0 {
+		bandwidth = (upper - lower) / middle
+	}
+
+	return []float64{upper, middle, lower, percentB, bandwidth}, nil
+
+}
+
+func (b *bollinger) Close() {
+	b.in.Close()
+}
+
+func (b *bollinger) WarmUp() int {
+	return b.period - 1 + inputWarmUp(b.in)
+}
+
+// keltner is the concrete implementation of a multi stream that applies a
+// Keltner Channel function to input candles.
+type keltner struct {
+	emaPeriod  int
+	atrPeriod  int
+	multiplier float64
+	average    *emaState
+	trueRange  trueRangeState
+	atr        *emaState
+	in         stream.CandleStream
+}
+
+// NewKeltnerStream returns a multi stream that applies a Keltner Channel
+// function to input candles; the middle band is an exponential moving average
+// of the close price and the upper and lower bands are offset from the middle
+// band by a multiple of the average true range.
+func NewKeltnerStream(in stream.CandleStream, emaPeriod, atrPeriod int,
+	multiplier float64) MultiIndicator {
+
+	return &keltner{
+		emaPeriod:  emaPeriod,
+		atrPeriod:  atrPeriod,
+		multiplier: multiplier,
+		average: newEMAState(emaPeriod, SmoothingAlpha(emaPeriod, 2.0),
+			SeedSMA),
+		atr: newEMAState(atrPeriod, 1.0/float64(atrPeriod), SeedSMA),
+		in:  in,
+	}
+
+}
+
+func (k *keltner) Components() []string {
+	return []string{BandUpper, BandMiddle, BandLower}
+}
+
+func (k *keltner) Next() ([]float64, error) {
+
+	if k.emaPeriod <= 0 || k.atrPeriod <= 0 {
+		return nil, errors.New(
+			"keltner channel periods cannot be negative or zero")
+	}
+
+	// retrieve the next candle
+	candle, err := k.in.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	middle := k.average.update(candle.Close)
+	offset := k.multiplier * k.atr.update(k.trueRange.update(candle))
+
+	return []float64{middle + offset, middle, middle - offset}, nil
+
+}
+
+func (k *keltner) Close() {
+	k.in.Close()
+}
+
+func (k *keltner) WarmUp() int {
+
+	if k.atrPeriod > k.emaPeriod {
+		return k.atrPeriod - 1
+	}
+
+	return k.emaPeriod - 1
+
+}
+
+// donchian is the concrete implementation of a multi stream that applies a
+// Donchian Channel function to input candles.
+type donchian struct {
+	period int
+	highs  *extrema
+	lows   *extrema
+	in     stream.CandleStream
+}
+
+// NewDonchianStream returns a multi stream that applies a Donchian Channel
+// function to input candles; the upper and lower bands are the highest high
+// and lowest low over the period and the middle band lies halfway between.
+func NewDonchianStream(in stream.CandleStream, period int) MultiIndicator {
+
+	return &donchian{
+		period: period,
+		highs:  newExtrema(period),
+		lows:   newExtrema(period),
+		in:     in,
+	}
+
+}
+
+func (d *donchian) Components() []string {
+	return []string{BandUpper, BandMiddle, BandLower}
+}
+
+func (d *donchian) Next() ([]float64, error) {
+
+	if d.period <= 0 {
+		return nil, errors.New(
+			"donchian channel period cannot be negative or zero")
+	}
+
+	// retrieve the next candle
+	candle, err := d.in.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	d.highs.update(candle.High)
+	d.lows.update(candle.Low)
+
+	upper, lower := d.highs.max(), d.lows.min()
+
+	return []float64{upper, (upper + lower) / 2.0, lower}, nil
+
+}
+
+func (d *donchian) Close() {
+	d.in.Close()
+}
+
+func (d *donchian) WarmUp() int {
+	return d.period - 1
+}
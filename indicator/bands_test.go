@@ -0,0 +1,78 @@
+package indicator_test
+
+import (
+	"testing"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+)
+
+// TestBollingerStream tests calculations performed by a Bollinger Band stream.
+func TestBollingerStream(t *testing.T) {
+
+	// define input data
+	inputData := []float64{3.0, 4.0, 2.0, 6.0, 4.0, 5.0, 0.0, 1.0}
+
+	// define expected output
+	expectedOutput := [][]float64{
+		{3.0, 3.0, 3.0, 0.5, 0.0},
+		{4.5, 3.5, 2.5, 0.75, 0.5714285714285714},
+		{4.6329931618554525, 3.0, 1.367006838144548, 0.1938137821521027,
+			1.088662107903635},
+		{7.265986323710904, 4.0, 0.7340136762890959, 0.8061862178478972,
+			1.632993161855452},
+		{7.265986323710904, 4.0, 0.7340136762890959, 0.5, 1.632993161855452},
+		{6.6329931618554525, 5.0, 3.367006838144548, 0.5, 0.6531972647421809},
+		{7.320493798938574, 3.0, -1.3204937989385739, 0.15281746258529325,
+			2.8803291992923827},
+		{6.320493798938574, 2.0, -2.320493798938574, 0.3842724875284311,
+			4.320493798938574},
+	}
+
+	bs := indicator.NewBollingerStream(input.NewListStream(inputData), 3, 2.0)
+	defer bs.Close()
+
+	assertMultiStreamOutput(t, bs, expectedOutput)
+
+}
+
+// TestKeltnerStream tests calculations performed by a Keltner Channel stream.
+func TestKeltnerStream(t *testing.T) {
+
+	// define expected output
+	expectedOutput := [][]float64{
+		{7.0, 3.0, -1.0},
+		{7.5, 3.5, -0.5},
+		{8.5, 4.5, 0.5},
+		{7.833333333333334, 2.8333333333333335, -2.1666666666666665},
+		{7.444444444444445, 2.9444444444444446, -1.5555555555555554},
+	}
+
+	ks := indicator.NewKeltnerStream(
+		input.NewCandleListStream(oscillatorCandles), 2, 2, 2.0)
+	defer ks.Close()
+
+	assertMultiStreamOutput(t, ks, expectedOutput)
+
+}
+
+// TestDonchianStream tests calculations performed by a Donchian Channel
+// stream.
+func TestDonchianStream(t *testing.T) {
+
+	// define expected output
+	expectedOutput := [][]float64{
+		{4.0, 3.0, 2.0},
+		{5.0, 3.5, 2.0},
+		{6.0, 4.0, 2.0},
+		{6.0, 4.0, 2.0},
+		{6.0, 3.5, 1.0},
+	}
+
+	ds := indicator.NewDonchianStream(
+		input.NewCandleListStream(oscillatorCandles), 3)
+	defer ds.Close()
+
+	assertMultiStreamOutput(t, ds, expectedOutput)
+
+}
@@ -0,0 +1,206 @@
+package indicator
+
+import (
+	"errors"
+	gomath "math"
+
+	"github.com/bsladewski/lapis/stream"
+)
+
+// Seed determines how an exponential moving average chooses its initial value.
+type Seed int
+
+const (
+	// SeedFirst seeds an exponential moving average with the first input
+	// value.
+	SeedFirst Seed = iota
+	// SeedSMA seeds an exponential moving average with the simple moving
+	// average of the first period input values; until period values have been
+	// received the simple moving average of the values received so far is
+	// output.
+	SeedSMA
+)
+
+// SmoothingAlpha returns the alpha of an exponential moving average with the
+// specified period and smoothing factor; a smoothing factor of 2 produces the
+// conventional exponential moving average.
+func SmoothingAlpha(period int, smoothing float64) float64 {
+
+	return smoothing / float64(period+1)
+
+}
+
+// emaState holds the state of a single exponential moving average calculation
+// so that averages can be chained within one stream.
+type emaState struct {
+	alpha  float64
+	period int
+	seed   Seed
+	n      int
+	sum    float64
+	value  float64
+}
+
+// newEMAState returns the initial state of an exponential moving average.
+func newEMAState(period int, alpha float64, seed Seed) *emaState {
+
+	return &emaState{
+		alpha:  alpha,
+		period: period,
+		seed:   seed,
+	}
+
+}
+
+// update adds a value to the exponential moving average and returns the
+// updated average.
+func (e *emaState) update(value float64) float64 {
+
+	e.n++
+
+	// while seeding from a simple moving average, output the average of the
+	// values received so far
+	if e.seed == SeedSMA && e.n <= e.period {
+		e.sum += value
+		e.value = e.sum / float64(e.n)
+		return e.value
+	}
+
+	if e.n == 1 {
+		e.value = value
+		return e.value
+	}
+
+	e.value += e.alpha * (value - e.value)
+
+	return e.value
+
+}
+
+// ema is the concrete implementation of a stream that applies an Exponential
+// Moving Average function to input data; multiple averages may be chained,
+// each averaging the output of the previous once it is ready, with the output
+// of the stream being a weighted sum of the chained averages.
+type ema struct {
+	period  int
+	alpha   float64
+	states  []*emaState
+	weights []float64
+	in      stream.Stream
+}
+
+// newEMA returns an exponential moving average stream that chains one average
+// for each of the supplied weights.
+func newEMA(in stream.Stream, period int, alpha float64, seed Seed,
+	weights ...float64) *ema {
+
+	states := make([]*emaState, len(weights))
+	for i := range states {
+		states[i] = newEMAState(period, alpha, seed)
+	}
+
+	return &ema{
+		period:  period,
+		alpha:   alpha,
+		states:  states,
+		weights: weights,
+		in:      in,
+	}
+
+}
+
+// NewEMAStream returns a stream that applies an Exponential Moving Average
+// function to input data.
+func NewEMAStream(in stream.Stream, period int, seed Seed) Indicator {
+
+	return newEMA(in, period, SmoothingAlpha(period, 2.0), seed, 1.0)
+
+}
+
+// NewEMAAlphaStream returns a stream that applies an Exponential Moving Average
+// function with the specified alpha to input data; the period determines the
+// number of values used to seed the average and the warm-up length.
+func NewEMAAlphaStream(in stream.Stream, period int, alpha float64,
+	seed Seed) Indicator {
+
+	return newEMA(in, period, alpha, seed, 1.0)
+
+}
+
+// NewWilderStream returns a stream that applies Wilder's smoothing function,
+// also known as a Running Moving Average, to input data.
+func NewWilderStream(in stream.Stream, period int, seed Seed) Indicator {
+
+	return newEMA(in, period, 1.0/float64(period), seed, 1.0)
+
+}
+
+// NewDEMAStream returns a stream that applies a Double Exponential Moving
+// Average function to input data.
+func NewDEMAStream(in stream.Stream, period int, seed Seed) Indicator {
+
+	return newEMA(in, period, SmoothingAlpha(period, 2.0), seed, 2.0, -1.0)
+
+}
+
+// NewTEMAStream returns a stream that applies a Triple Exponential Moving
+// Average function to input data.
+func NewTEMAStream(in stream.Stream, period int, seed Seed) Indicator {
+
+	return newEMA(in, period, SmoothingAlpha(period, 2.0), seed, 3.0, -3.0,
+		1.0)
+
+}
+
+func (e *ema) Next() (float64, error) {
+
+	if e.period <= 0 {
+		return 0.0, errors.New(
+			"exponential moving average period cannot be negative or zero")
+	}
+
+	if e.alpha <= 0.0 || e.alpha > 1.0 {
+		return 0.0, errors.New(
+			"exponential moving average alpha must be within (0, 1]")
+	}
+
+	// retrieve the next piece of input data
+	next, err := e.in.Next()
+	if err != nil && err != stream.ErrNotReady {
+		return 0.0, err
+	}
+
+	// values flagged as not ready, or marked as not ready with NaN, are
+	// passed through unchanged rather than poisoning the average
+	if err == stream.ErrNotReady || gomath.IsNaN(next) {
+		return next, err
+	}
+
+	// feed each average in the chain with the output of the previous average
+	// once the previous average is ready, so that each average is seeded from
+	// period outputs of the previous; an average that has not been fed yet
+	// takes the value of the previous average in the weighted sum
+	result := 0.0
+	ready := true
+	for i, state := range e.states {
+		if ready {
+			next = state.update(next)
+			ready = state.n >= e.period
+		}
+		result += e.weights[i] * next
+	}
+
+	return result, nil
+
+}
+
+func (e *ema) Close() {
+	e.in.Close()
+}
+
+func (e *ema) WarmUp() int {
+
+	// each chained average must fill its own window
+	return len(e.states)*(e.period-1) + inputWarmUp(e.in)
+
+}
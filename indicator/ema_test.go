@@ -0,0 +1,136 @@
+package indicator_test
+
+import (
+	"testing"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// TestEMAStreams tests calculations performed by the family of Exponential
+// Moving Average streams.
+func TestEMAStreams(t *testing.T) {
+
+	// define input data
+	inputData := []float64{3.0, 4.0, 2.0, 6.0, 4.0, 5.0, 0.0, 1.0}
+
+	// define test cases
+	cases := []struct {
+		name           string
+		newStream      func(in stream.Stream) indicator.Indicator
+		warmUp         int
+		expectedOutput []float64
+	}{
+		{"TestEMASeedFirst", func(in stream.Stream) indicator.Indicator {
+			return indicator.NewEMAStream(in, 3, indicator.SeedFirst)
+		}, 2, []float64{3.0, 3.5, 2.75, 4.375, 4.1875, 4.59375, 2.296875,
+			1.6484375}},
+		{"TestEMASeedSMA", func(in stream.Stream) indicator.Indicator {
+			return indicator.NewEMAStream(in, 3, indicator.SeedSMA)
+		}, 2, []float64{3.0, 3.5, 3.0, 4.5, 4.25, 4.625, 2.3125, 1.65625}},
+		{"TestEMAAlpha", func(in stream.Stream) indicator.Indicator {
+			return indicator.NewEMAAlphaStream(in, 3, 0.5, indicator.SeedFirst)
+		}, 2, []float64{3.0, 3.5, 2.75, 4.375, 4.1875, 4.59375, 2.296875,
+			1.6484375}},
+		{"TestWilder", func(in stream.Stream) indicator.Indicator {
+			return indicator.NewWilderStream(in, 3, indicator.SeedSMA)
+		}, 2, []float64{3.0, 3.5, 3.0, 4.0, 4.0, 4.333333333333333,
+			2.888888888888889, 2.2592592592592595}},
+	}
+
+	for _, tc := range cases {
+
+		t.Run(tc.name, func(t *testing.T) {
+
+			// create the stream under test
+			es := tc.newStream(input.NewListStream(inputData))
+			defer es.Close()
+
+			if es.WarmUp() != tc.warmUp {
+				t.Fatalf("expected warm-up %d, got %d", tc.warmUp, es.WarmUp())
+			}
+
+			// assert that expected output matches data from the stream
+			for i, value := range tc.expectedOutput {
+
+				streamValue, err := es.Next()
+				if err != nil {
+					t.Fatalf("index %d; err: %v", i, err)
+				}
+
+				if util.CompareFloat(streamValue, value) != 0 {
+					t.Fatalf("index %d; expected %.4f, got %.4f", i, value,
+						streamValue)
+				}
+
+			}
+
+			// assert that next results in end of stream error
+			if _, err := es.Next(); err != stream.ErrEndOfStream {
+				t.Fatalf("expected end of stream error, got %v", err)
+			}
+
+		})
+
+	}
+
+}
+
+// TestDEMATEMAStreams tests that Double and Triple Exponential Moving Average
+// streams match reference values once ready; each chained average is seeded
+// from the simple moving average of the first period outputs of the previous
+// average.
+func TestDEMATEMAStreams(t *testing.T) {
+
+	// define input data
+	inputData := []float64{3.0, 4.0, 2.0, 6.0, 4.0, 5.0, 0.0, 1.0}
+
+	ds := indicator.NewDEMAStream(input.NewListStream(inputData), 3,
+		indicator.SeedSMA)
+	defer ds.Close()
+
+	if ds.WarmUp() != 4 {
+		t.Fatalf("expected warm-up 4, got %d", ds.WarmUp())
+	}
+
+	assertReadyOutput(t, ds, []float64{4.5833333333, 4.9791666667,
+		1.3333333333, 0.8385416667})
+
+	ts := indicator.NewTEMAStream(input.NewListStream(inputData), 3,
+		indicator.SeedSMA)
+	defer ts.Close()
+
+	if ts.WarmUp() != 6 {
+		t.Fatalf("expected warm-up 6, got %d", ts.WarmUp())
+	}
+
+	assertReadyOutput(t, ts, []float64{0.8888888889, 0.6970486111})
+
+	ds = indicator.NewDEMAStream(input.NewListStream(referencePrices), 5,
+		indicator.SeedSMA)
+	defer ds.Close()
+
+	assertReadyOutput(t, ds, []float64{
+		9646.0130814815, 9676.6144356653, 9668.8034335620, 9644.9570511203,
+		9627.8345421328, 9639.1540334570, 9670.2278036614, 9677.3330566787,
+		9683.1972739444, 9703.4161178464, 9720.6004798199, 9677.5568096059,
+		9660.8321995546, 9626.3854618035, 9563.3296381582, 9529.9904234094,
+		9555.6151698088, 9561.8089271187, 9557.3018273544, 9687.3862466420,
+		9749.1952944762, 9774.9253941274,
+	})
+
+	ts = indicator.NewTEMAStream(input.NewListStream(referencePrices), 5,
+		indicator.SeedSMA)
+	defer ts.Close()
+
+	assertReadyOutput(t, ts, []float64{
+		9622.2104501468, 9640.6332943140, 9680.6647096789, 9685.4599751309,
+		9689.2194615977, 9711.7422036665, 9728.7910437600, 9669.0682490307,
+		9651.0324259862, 9611.8137921568, 9539.5553123410, 9510.8173983948,
+		9555.8980965295, 9567.2679025596, 9562.3038685302, 9727.7021918785,
+		9787.4508264752, 9799.9106174176,
+	})
+
+}
@@ -0,0 +1,302 @@
+package indicator
+
+import (
+	"errors"
+	gomath "math"
+	"time"
+
+	"github.com/bsladewski/lapis/stream"
+)
+
+const (
+	// IchimokuTenkan names the conversion line component of an Ichimoku
+	// Cloud stream.
+	IchimokuTenkan = "tenkan"
+	// IchimokuKijun names the base line component of an Ichimoku Cloud
+	// stream.
+	IchimokuKijun = "kijun"
+	// IchimokuSenkouA names the leading span A component of an Ichimoku Cloud
+	// stream.
+	IchimokuSenkouA = "senkou_a"
+	// IchimokuSenkouB names the leading span B component of an Ichimoku Cloud
+	// stream.
+	IchimokuSenkouB = "senkou_b"
+)
+
+// ichimokuState holds the state of an Ichimoku Cloud calculation; each line is
+// the midpoint of the highest high and lowest low over its period.
+type ichimokuState struct {
+	tenkanPeriod int
+	kijunPeriod  int
+	senkouPeriod int
+	displacement int
+	highs        [3]*extrema
+	lows         [3]*extrema
+	leading      [][2]float64
+}
+
+// newIchimokuState returns the initial state of an Ichimoku Cloud calculation.
+func newIchimokuState(tenkanPeriod, kijunPeriod, senkouPeriod,
+	displacement int) *ichimokuState {
+
+	s := &ichimokuState{
+		tenkanPeriod: tenkanPeriod,
+		kijunPeriod:  kijunPeriod,
+		senkouPeriod: senkouPeriod,
+		displacement: displacement,
+	}
+
+	for i, period := range []int{tenkanPeriod, kijunPeriod, senkouPeriod} {
+		s.highs[i] = newExtrema(period)
+		s.lows[i] = newExtrema(period)
+	}
+
+	return s
+
+}
+
+// validate returns an error if the periods of the calculation are invalid.
+func (s *ichimokuState) validate() error {
+
+	if s.tenkanPeriod <= 0 || s.kijunPeriod <= 0 || s.senkouPeriod <= 0 {
+		return errors.New("ichimoku periods cannot be negative or zero")
+	}
+
+	if s.displacement < 0 {
+		return errors.New("ichimoku displacement cannot be negative")
+	}
+
+	return nil
+
+}
+
+// update adds a candle to the calculation returning the conversion and base
+// lines for the candle along with the leading spans that apply to the candle,
+// which were calculated displacement candles earlier; the leading spans are NaN
+// until displacement candles have been received.
+func (s *ichimokuState) update(candle stream.Candle) (tenkan, kijun, senkouA,
+	senkouB float64) {
+
+	var midpoints [3]float64
+	for i := range midpoints {
+		s.highs[i].update(candle.High)
+		s.lows[i].update(candle.Low)
+		midpoints[i] = (s.highs[i].max() + s.lows[i].min()) / 2.0
+	}
+
+	tenkan, kijun = midpoints[0], midpoints[1]
+
+	// queue the leading spans calculated from this candle until the candle
+	// they apply to has been received
+	s.leading = append(s.leading, [2]float64{(tenkan + kijun) / 2.0,
+		midpoints[2]})
+
+	senkouA, senkouB = gomath.NaN(), gomath.NaN()
+	if len(s.leading) > s.displacement {
+		senkouA, senkouB = s.leading[0][0], s.leading[0][1]
+		s.leading = s.leading[1:]
+	}
+
+	return tenkan, kijun, senkouA, senkouB
+
+}
+
+// ichimokuCloud is the concrete implementation of a multi stream that outputs
+// the Ichimoku Cloud values that apply to each input candle as it is received.
+type ichimokuCloud struct {
+	state *ichimokuState
+	in    stream.CandleStream
+}
+
+// NewIchimokuCloudStream returns a multi stream that outputs the conversion
+// line, base line and leading spans of an Ichimoku Cloud that apply to each
+// input candle; the leading spans are calculated displacement candles before
+// the candle they apply to and are NaN until then. The lagging span cannot be
+// known when a candle is received so it is not output, see NewIchimokuStream.
+// Conventional periods are 9, 26 and 52 with a displacement of 26.
+func NewIchimokuCloudStream(in stream.CandleStream, tenkanPeriod, kijunPeriod,
+	senkouPeriod, displacement int) MultiIndicator {
+
+	return &ichimokuCloud{
+		state: newIchimokuState(tenkanPeriod, kijunPeriod, senkouPeriod,
+			displacement),
+		in: in,
+	}
+
+}
+
+func (i *ichimokuCloud) Components() []string {
+	return []string{IchimokuTenkan, IchimokuKijun, IchimokuSenkouA,
+		IchimokuSenkouB}
+}
+
+func (i *ichimokuCloud) Next() ([]float64, error) {
+
+	if err := i.state.validate(); err != nil {
+		return nil, err
+	}
+
+	// retrieve the next candle
+	candle, err := i.in.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	tenkan, kijun, senkouA, senkouB := i.state.update(candle)
+
+	return []float64{tenkan, kijun, senkouA, senkouB}, nil
+
+}
+
+func (i *ichimokuCloud) Close() {
+	i.in.Close()
+}
+
+func (i *ichimokuCloud) WarmUp() int {
+
+	// the leading spans applying to a candle are ready once the longest
+	// period has filled displacement candles earlier
+	longest := i.state.tenkanPeriod
+	for _, period := range []int{i.state.kijunPeriod, i.state.senkouPeriod} {
+		if period > longest {
+			longest = period
+		}
+	}
+
+	return longest - 1 + i.state.displacement
+
+}
+
+// An IchimokuPoint holds the values of an Ichimoku Cloud that apply to a
+// timestamp; values that are not known for the timestamp are NaN.
+type IchimokuPoint struct {
+	Timestamp time.Time
+	Tenkan    float64
+	Kijun     float64
+	SenkouA   float64
+	SenkouB   float64
+	Chikou    float64
+}
+
+// An IchimokuStream provides the values of an Ichimoku Cloud keyed to the
+// timestamps they apply to.
+type IchimokuStream interface {
+	// Next gets the next point in the stream.
+	Next() (IchimokuPoint, error)
+	// Close closes any resources the stream is currently reading.
+	Close()
+}
+
+// ichimoku is the concrete implementation of a stream that outputs Ichimoku
+// Cloud values keyed to the timestamps they apply to.
+type ichimoku struct {
+	state    *ichimokuState
+	pending  []IchimokuPoint
+	ready    []IchimokuPoint
+	previous time.Time
+	interval time.Duration
+	ended    bool
+	in       stream.CandleStream
+}
+
+// NewIchimokuStream returns a stream that outputs the values of an Ichimoku
+// Cloud keyed to the timestamp of the candle they apply to. The lagging span of
+// a candle is the close price displacement candles later, so each point is
+// output once that candle has been received and the stream lags its input by
+// displacement candles. When the input ends the remaining points are output
+// without a lagging span followed by points for the displacement candles after
+// the last candle, which hold only the leading spans projected forward; their
+// timestamps are extrapolated from the interval between the last two candles.
+func NewIchimokuStream(in stream.CandleStream, tenkanPeriod, kijunPeriod,
+	senkouPeriod, displacement int) IchimokuStream {
+
+	return &ichimoku{
+		state: newIchimokuState(tenkanPeriod, kijunPeriod, senkouPeriod,
+			displacement),
+		in: in,
+	}
+
+}
+
+func (i *ichimoku) Next() (IchimokuPoint, error) {
+
+	if err := i.state.validate(); err != nil {
+		return IchimokuPoint{}, err
+	}
+
+	// read candles until a point is ready to be output
+	for len(i.ready) == 0 {
+
+		if i.ended {
+			return IchimokuPoint{}, stream.ErrEndOfStream
+		}
+
+		candle, err := i.in.Next()
+		if err == stream.ErrEndOfStream {
+			i.flush()
+			continue
+		} else if err != nil {
+			return IchimokuPoint{}, err
+		}
+
+		if !i.previous.IsZero() {
+			i.interval = candle.Timestamp.Sub(i.previous)
+		}
+		i.previous = candle.Timestamp
+
+		point := IchimokuPoint{
+			Timestamp: candle.Timestamp,
+			Chikou:    gomath.NaN(),
+		}
+		point.Tenkan, point.Kijun, point.SenkouA, point.SenkouB =
+			i.state.update(candle)
+
+		// the close price of this candle is the lagging span of the candle
+		// displacement candles earlier
+		i.pending = append(i.pending, point)
+		if len(i.pending) > i.state.displacement {
+			i.pending[0].Chikou = candle.Close
+			i.ready = append(i.ready, i.pending[0])
+			i.pending = i.pending[1:]
+		}
+
+	}
+
+	point := i.ready[0]
+	i.ready = i.ready[1:]
+
+	return point, nil
+
+}
+
+// flush marks the end of input, readying the points that will never receive a
+// lagging span followed by the projected leading spans.
+func (i *ichimoku) flush() {
+
+	i.ended = true
+	i.ready = append(i.ready, i.pending...)
+	i.pending = nil
+
+	// the interval between candles is unknown with fewer than two candles
+	if i.interval <= 0 {
+		return
+	}
+
+	for n, leading := range i.state.leading {
+		i.ready = append(i.ready, IchimokuPoint{
+			Timestamp: i.previous.Add(time.Duration(n+1) * i.interval),
+			Tenkan:    gomath.NaN(),
+			Kijun:     gomath.NaN(),
+			SenkouA:   leading[0],
+			SenkouB:   leading[1],
+			Chikou:    gomath.NaN(),
+		})
+	}
+
+	i.state.leading = nil
+
+}
+
+func (i *ichimoku) Close() {
+	i.in.Close()
+}
@@ -0,0 +1,122 @@
+package indicator_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// ichimokuCandles returns the hourly candle input used to test Ichimoku Cloud
+// streams.
+func ichimokuCandles() []stream.Candle {
+
+	start := time.Date(2020, 5, 19, 0, 0, 0, 0, time.UTC)
+
+	candles := append([]stream.Candle{}, trendCandles[:5]...)
+	for i := range candles {
+		candles[i].Timestamp = start.Add(time.Duration(i) * time.Hour)
+	}
+
+	return candles
+
+}
+
+// TestIchimokuStream tests that an Ichimoku Cloud stream outputs each value
+// keyed to the timestamp it applies to.
+func TestIchimokuStream(t *testing.T) {
+
+	nan := math.NaN()
+	start := time.Date(2020, 5, 19, 0, 0, 0, 0, time.UTC)
+
+	// define expected output; the lagging span is the close two candles later
+	// and the leading spans are those calculated two candles earlier, the last
+	// two points project the leading spans beyond the final candle
+	expectedOutput := []indicator.IchimokuPoint{
+		{start, 9.5, 9.5, nan, nan, 11.9},
+		{start.Add(1 * time.Hour), 10.5, 10.0, nan, nan, 12.8},
+		{start.Add(2 * time.Hour), 11.5, 11.0, 9.5, 9.5, 10.2},
+		{start.Add(3 * time.Hour), 12.5, 12.0, 10.25, 10.0, nan},
+		{start.Add(4 * time.Hour), 11.25, 11.5, 11.25, 10.5, nan},
+		{start.Add(5 * time.Hour), nan, nan, 12.25, 11.5, nan},
+		{start.Add(6 * time.Hour), nan, nan, 11.375, 11.5, nan},
+	}
+
+	is := indicator.NewIchimokuStream(
+		input.NewCandleListStream(ichimokuCandles()), 1, 2, 3, 2)
+	defer is.Close()
+
+	for i, expected := range expectedOutput {
+
+		point, err := is.Next()
+		if err != nil {
+			t.Fatalf("index %d; err: %v", i, err)
+		}
+
+		if !point.Timestamp.Equal(expected.Timestamp) {
+			t.Fatalf("index %d; expected timestamp %v, got %v", i,
+				expected.Timestamp, point.Timestamp)
+		}
+
+		expectedValues := []float64{expected.Tenkan, expected.Kijun,
+			expected.SenkouA, expected.SenkouB, expected.Chikou}
+		values := []float64{point.Tenkan, point.Kijun, point.SenkouA,
+			point.SenkouB, point.Chikou}
+
+		for j, value := range expectedValues {
+
+			if math.IsNaN(value) != math.IsNaN(values[j]) ||
+				!math.IsNaN(value) && util.CompareFloat(values[j], value) != 0 {
+				t.Fatalf("index %d, value %d; expected %.4f, got %.4f", i, j,
+					value, values[j])
+			}
+
+		}
+
+	}
+
+	// assert that next results in end of stream error
+	if _, err := is.Next(); err != stream.ErrEndOfStream {
+		t.Fatalf("expected end of stream error, got %v", err)
+	}
+
+}
+
+// TestIchimokuCloudStream tests that an Ichimoku Cloud multi stream outputs
+// the values that apply to each candle as it is received.
+func TestIchimokuCloudStream(t *testing.T) {
+
+	is := indicator.NewIchimokuCloudStream(
+		input.NewCandleListStream(ichimokuCandles()), 1, 2, 3, 2)
+	defer is.Close()
+
+	if is.WarmUp() != 4 {
+		t.Fatalf("expected warm-up 4, got %d", is.WarmUp())
+	}
+
+	// skip the candles for which the leading spans are unknown
+	for i := 0; i < 2; i++ {
+
+		values, err := is.Next()
+		if err != nil {
+			t.Fatalf("index %d; err: %v", i, err)
+		}
+
+		if !math.IsNaN(values[2]) || !math.IsNaN(values[3]) {
+			t.Fatalf("index %d; expected NaN leading spans, got %v", i,
+				values)
+		}
+
+	}
+
+	assertMultiStreamOutput(t, is, [][]float64{
+		{11.5, 11.0, 9.5, 9.5},
+		{12.5, 12.0, 10.25, 10.0},
+		{11.25, 11.5, 11.25, 10.5},
+	})
+
+}
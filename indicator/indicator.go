@@ -0,0 +1,65 @@
+package indicator
+
+import (
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// An Indicator is a stream that applies a technical indicator function to input
+// data.
+type Indicator interface {
+	stream.Stream
+	// WarmUp returns the number of values output by the indicator before it
+	// has received enough input to fill its window; these leading values are
+	// calculated from partial data.
+	WarmUp() int
+}
+
+// composite attaches a warm-up length to an indicator that is built by
+// combining other streams.
+type composite struct {
+	stream.Stream
+	warmUp int
+}
+
+func (c *composite) WarmUp() int {
+	return c.warmUp
+}
+
+// inputWarmUp returns the warm-up length of an input stream if that stream is
+// an indicator, otherwise the input is assumed to be ready immediately.
+func inputWarmUp(in stream.Stream) int {
+
+	if ind, ok := in.(Indicator); ok {
+		return ind.WarmUp()
+	}
+
+	return 0
+
+}
+
+// A MultiIndicator is a multi stream that applies a technical indicator
+// function with several related outputs to input data.
+type MultiIndicator interface {
+	stream.MultiStream
+	// WarmUp returns the number of sets of values output by the indicator
+	// before every component has received enough input to fill its window.
+	WarmUp() int
+}
+
+// tapIndicators returns an indicator for every component of a multi indicator,
+// each with the supplied warm-up length; the components share the calculation
+// performed by the multi indicator.
+func tapIndicators(in MultiIndicator, warmUps ...int) []Indicator {
+
+	// tapping every component of a multi stream cannot fail
+	taps, _ := input.NewTapStreams(in)
+
+	indicators := make([]Indicator, len(taps))
+	for i, tap := range taps {
+		indicators[i] = &composite{Stream: tap, warmUp: warmUps[i]}
+	}
+
+	return indicators
+
+}
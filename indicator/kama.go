@@ -0,0 +1,106 @@
+package indicator
+
+import (
+	"errors"
+	gomath "math"
+
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// kama is the concrete implementation of a stream that applies Kaufman's
+// Adaptive Moving Average function to input data.
+type kama struct {
+	period     int
+	fastPeriod int
+	slowPeriod int
+	prices     *util.Ring[float64]
+	changes    *util.Ring[float64]
+	volatility util.KahanSum
+	value      float64
+	in         stream.Stream
+}
+
+// NewKAMAStream returns a stream that applies Kaufman's Adaptive Moving Average
+// function to input data; the period determines the window over which the
+// efficiency ratio is measured while the fast and slow periods bound the
+// smoothing applied to the average. Kaufman's recommended values are 10, 2 and
+// 30 respectively. Until period changes have been received the average
+// follows the input.
+func NewKAMAStream(in stream.Stream, period, fastPeriod,
+	slowPeriod int) Indicator {
+
+	return &kama{
+		period:     period,
+		fastPeriod: fastPeriod,
+		slowPeriod: slowPeriod,
+		prices:     util.NewRing[float64](period + 1),
+		changes:    util.NewRing[float64](period),
+		in:         in,
+	}
+
+}
+
+func (k *kama) Next() (float64, error) {
+
+	if k.period <= 0 || k.fastPeriod <= 0 || k.slowPeriod <= 0 {
+		return 0.0, errors.New(
+			"adaptive moving average periods cannot be negative or zero")
+	}
+
+	// retrieve the next piece of input data
+	next, err := k.in.Next()
+	if err != nil && err != stream.ErrNotReady {
+		return 0.0, err
+	}
+
+	// values flagged as not ready, or marked as not ready with NaN, are
+	// passed through unchanged rather than poisoning the average
+	if err == stream.ErrNotReady || gomath.IsNaN(next) {
+		return next, err
+	}
+
+	// track the sum of absolute price changes over the period
+	if k.prices.Size() > 0 {
+		change := gomath.Abs(next - k.prices.Get(k.prices.Size()-1))
+		if evicted, ok := k.changes.Add(change); ok {
+			k.volatility.Add(-evicted)
+		}
+		k.volatility.Add(change)
+	}
+	k.prices.Add(next)
+
+	// until the efficiency ratio can be measured over the full period the
+	// average follows the input, so that the first smoothed value starts from
+	// the previous price
+	if !k.prices.IsFull() {
+		k.value = next
+		return k.value, nil
+	}
+
+	// the efficiency ratio compares the net price change over the period to
+	// the sum of the individual price changes
+	efficiency := 0.0
+	if volatility := k.volatility.Value(); volatility > 0.0 {
+		efficiency = gomath.Abs(next-k.prices.Get(0)) / volatility
+	}
+
+	// scale the smoothing constant between the fast and slow periods using the
+	// efficiency ratio
+	fast := 2.0 / float64(k.fastPeriod+1)
+	slow := 2.0 / float64(k.slowPeriod+1)
+	smoothing := gomath.Pow(efficiency*(fast-slow)+slow, 2)
+
+	k.value += smoothing * (next - k.value)
+
+	return k.value, nil
+
+}
+
+func (k *kama) Close() {
+	k.in.Close()
+}
+
+func (k *kama) WarmUp() int {
+	return k.period + inputWarmUp(k.in)
+}
@@ -0,0 +1,41 @@
+package indicator_test
+
+import (
+	"testing"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+)
+
+// TestKAMAStream tests calculations performed by a Kaufman's Adaptive Moving
+// Average stream.
+func TestKAMAStream(t *testing.T) {
+
+	// the average follows the input until the period has been received; a
+	// steady trend has an efficiency ratio of one so the average then moves
+	// towards the input using the fast smoothing constant
+	inputData := []float64{1.0, 2.0, 3.0, 4.0}
+	expectedOutput := []float64{1.0, 2.0, 2.0 + 4.0/9.0}
+	expectedOutput = append(expectedOutput,
+		expectedOutput[2]+(4.0-expectedOutput[2])*4.0/9.0)
+
+	ks := indicator.NewKAMAStream(input.NewListStream(inputData), 2, 2, 30)
+	defer ks.Close()
+
+	assertStreamOutput(t, ks, expectedOutput)
+
+	// assert that the stream matches the reference values once ready; the
+	// first ready value smooths from the previous price as TA-Lib does
+	ks = indicator.NewKAMAStream(input.NewListStream(referencePrices), 10,
+		2, 30)
+	defer ks.Close()
+
+	assertReadyOutput(t, ks, []float64{
+		9703.3125699902, 9698.1045771908, 9691.9948351530, 9690.1689905119,
+		9690.9502687532, 9690.6222831590, 9690.4291793029, 9691.2331747605,
+		9693.0119318416, 9690.7944422762, 9690.4229020678, 9689.2824744243,
+		9681.1089299595, 9666.4989057961, 9662.6556205827, 9658.1220026106,
+		9651.7055586144, 9654.9177318303, 9656.7691351435, 9662.7598996253,
+	})
+
+}
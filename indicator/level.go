@@ -0,0 +1,30 @@
+package indicator
+
+import "github.com/bsladewski/lapis/stream"
+
+// pendingLevels holds changes to active levels that have not yet been read
+// from a level stream.
+type pendingLevels []stream.Level
+
+// push queues a change to the active levels.
+func (p *pendingLevels) push(level stream.Level) {
+	*p = append(*p, level)
+}
+
+// withdraw queues the withdrawal of an active level.
+func (p *pendingLevels) withdraw(level stream.Level) {
+
+	level.Active = false
+	p.push(level)
+
+}
+
+// pop removes and returns the oldest change to the active levels.
+func (p *pendingLevels) pop() stream.Level {
+
+	level := (*p)[0]
+	*p = (*p)[1:]
+
+	return level
+
+}
@@ -2,29 +2,32 @@ package indicator
 
 import (
 	"errors"
-	"fmt"
+	gomath "math"
 
-	"github.com/bsladewski/gollections"
 	"github.com/bsladewski/lapis/input"
 	"github.com/bsladewski/lapis/math"
 	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
 )
 
 // ma is the concrete implementation of a stream that applies a Moving Average
-// function to input data.
+// function to input data; the average is maintained as a running sum over a
+// ring buffer so that each value is calculated in constant time regardless of
+// the period.
 type ma struct {
 	period int
-	frame  gollections.Queue
+	frame  *util.Ring[float64]
+	sum    util.KahanSum
 	in     stream.Stream
 }
 
 // NewMAStream returns a stream that applies a Moving Average function to input
 // data.
-func NewMAStream(in stream.Stream, period int) stream.Stream {
+func NewMAStream(in stream.Stream, period int) Indicator {
 
 	return &ma{
 		period: period,
-		frame:  gollections.NewLinkedQueue(),
+		frame:  util.NewRing[float64](period),
 		in:     in,
 	}
 
@@ -38,47 +41,55 @@ func (m *ma) Next() (float64, error) {
 
 	// retrieve the next piece of input data
 	next, err := m.in.Next()
-	if err != nil {
+	if err != nil && err != stream.ErrNotReady {
 		return 0.0, err
 	}
 
+	// values flagged as not ready, or marked as not ready with NaN, are
+	// passed through unchanged rather than poisoning the running sum
+	if err == stream.ErrNotReady || gomath.IsNaN(next) {
+		return next, err
+	}
+
 	// add input data to the frame that makes up the current average; if the
-	// size of the frame exceeds the period, remove all excess elements
-	m.frame.Add(next)
-	for m.period > 0 && m.frame.Size() > m.period {
-		m.frame.PopFirst()
+	// frame is full the oldest value is evicted and removed from the sum
+	if evicted, ok := m.frame.Add(next); ok {
+		m.sum.Add(-evicted)
 	}
+	m.sum.Add(next)
 
 	// calculate the average of current frame
-	sum := 0.0
-	for _, valueI := range m.frame.ToArray() {
-
-		if value, ok := valueI.(float64); ok {
-			sum += value
-		} else {
-			return 0.0, fmt.Errorf("received invalid value: %v type: %T",
-				valueI, valueI)
-		}
-
-	}
+	return m.sum.Value() / float64(m.frame.Size()), nil
 
-	return sum / float64(m.frame.Size()), nil
 }
 
 func (m *ma) Close() {
 	m.in.Close()
 }
 
+func (m *ma) WarmUp() int {
+	return m.period - 1 + inputWarmUp(m.in)
+}
+
 // NewMAOscillatorStream returns a stream that applies a Moving Average
 // oscillator function to input data.
 func NewMAOscillatorStream(in stream.Stream, fastPeriod,
-	slowPeriod int) stream.Stream {
+	slowPeriod int) Indicator {
 
 	splitter := input.NewSplitterStream(in, 2)
 
-	return math.NewSubStream(
-		NewMAStream(splitter, fastPeriod),
-		NewMAStream(splitter, slowPeriod),
-	)
+	// the oscillator is ready once the slower of the two averages is ready
+	warmUp := fastPeriod - 1
+	if slowPeriod > fastPeriod {
+		warmUp = slowPeriod - 1
+	}
+
+	return &composite{
+		Stream: math.NewSubStream(
+			NewMAStream(splitter, fastPeriod),
+			NewMAStream(splitter, slowPeriod),
+		),
+		warmUp: warmUp + inputWarmUp(in),
+	}
 
 }
@@ -1,8 +1,11 @@
 package indicator_test
 
 import (
+	"fmt"
+	"os"
 	"testing"
 
+	"github.com/bsladewski/gollections"
 	"github.com/bsladewski/lapis/indicator"
 	"github.com/bsladewski/lapis/input"
 	"github.com/bsladewski/lapis/stream"
@@ -87,3 +90,277 @@ func TestMAOscillatorStream(t *testing.T) {
 	}
 
 }
+
+// legacyMA is the original Moving Average implementation which re-sums the
+// entire frame on every value; it is kept as a reference for comparing
+// accuracy and performance.
+type legacyMA struct {
+	period int
+	frame  gollections.Queue
+	in     stream.Stream
+}
+
+func (m *legacyMA) Next() (float64, error) {
+
+	next, err := m.in.Next()
+	if err != nil {
+		return 0.0, err
+	}
+
+	m.frame.Add(next)
+	for m.frame.Size() > m.period {
+		m.frame.PopFirst()
+	}
+
+	sum := 0.0
+	for _, valueI := range m.frame.ToArray() {
+
+		if value, ok := valueI.(float64); ok {
+			sum += value
+		} else {
+			return 0.0, fmt.Errorf("received invalid value: %v type: %T",
+				valueI, valueI)
+		}
+
+	}
+
+	return sum / float64(m.frame.Size()), nil
+
+}
+
+func (m *legacyMA) Close() {
+	m.in.Close()
+}
+
+// loadMockData reads the close prices from the mock coinbase data file.
+func loadMockData(t testing.TB) []float64 {
+
+	mockData, err := os.Open("../input/mock_data.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mockData.Close()
+
+	ms, err := input.NewCoinbaseMockStream(mockData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ms.Close()
+
+	var prices []float64
+	for {
+
+		price, err := ms.Next()
+		if err == stream.ErrEndOfStream {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		prices = append(prices, price)
+
+	}
+
+	return prices
+
+}
+
+// assertStreamOutput asserts that a stream outputs the expected values followed
+// by an end of stream error.
+func assertStreamOutput(t *testing.T, s stream.Stream, expected []float64) {
+
+	t.Helper()
+
+	for i, value := range expected {
+
+		streamValue, err := s.Next()
+		if err != nil {
+			t.Fatalf("index %d; err: %v", i, err)
+		}
+
+		if util.CompareFloat(streamValue, value) != 0 {
+			t.Fatalf("index %d; expected %.9f, got %.9f", i, value,
+				streamValue)
+		}
+
+	}
+
+	// assert that next results in end of stream error
+	if _, err := s.Next(); err != stream.ErrEndOfStream {
+		t.Fatalf("expected end of stream error, got %v", err)
+	}
+
+}
+
+// referencePrices, referenceHighs, referenceLows and referenceVolumes are the
+// close prices, high and low prices and volumes of the first thirty hours of
+// the mock coinbase data; the expected values of
+// indicators over these prices are calculated independently, using the
+// standard definition of each indicator, and only cover values output once the
+// indicator is ready.
+var (
+	referencePrices = []float64{
+		9855.27, 9774, 9779.09, 9579.64, 9570.01, 9626.4, 9627.4, 9664.22,
+		9655.7, 9707.31, 9662.63, 9627.19, 9619, 9654.84, 9698.58, 9680.84,
+		9685.01, 9716.35, 9728.52, 9635.71, 9648.41, 9602.27, 9521.15, 9520.02,
+		9594.81, 9577.62, 9561.39, 9798.33, 9783.33, 9773.37,
+	}
+	referenceHighs = []float64{
+		9895, 9879.92, 9805.02, 9793.12, 9606.23, 9652.81, 9686.21, 9668.22,
+		9691.26, 9729.21, 9715, 9702.15, 9660.25, 9664.58, 9716, 9712.44, 9720,
+		9745.68, 9749.74, 9737.3, 9661.76, 9651.1, 9625.95, 9565, 9598,
+		9610.11, 9614.3, 9825, 9811.36, 9790.5,
+	}
+	referenceLows = []float64{
+		9847.34, 9721, 9736.89, 9442.97, 9534.25, 9550.71, 9620.38, 9563.44,
+		9630.82, 9627.35, 9643.01, 9600, 9544.08, 9607.65, 9652.29, 9650,
+		9671.22, 9666.14, 9695.64, 9570, 9622.85, 9535.89, 9477, 9463.22, 9515,
+		9575, 9540.67, 9561.39, 9763.12, 9751.87,
+	}
+	referenceVolumes = []float64{
+		410.85, 567.48, 279.16, 1072.67, 744.53, 693.25, 345.46, 578.85, 527.1,
+		857.61, 523.61, 738.69, 735.51, 386.52, 459.14, 304.43, 347.08, 445.95,
+		427.19, 1010.27, 315.05, 917.6, 680.35, 620.32, 460.6, 192.92, 197.97,
+		1411.48, 426.91, 131.87,
+	}
+)
+
+// referenceCandles returns candles of the reference high, low and close prices
+// and volumes.
+func referenceCandles() []stream.Candle {
+
+	candles := make([]stream.Candle, len(referencePrices))
+	for i := range candles {
+		candles[i] = stream.Candle{
+			High:   referenceHighs[i],
+			Low:    referenceLows[i],
+			Close:  referencePrices[i],
+			Volume: referenceVolumes[i],
+		}
+	}
+
+	return candles
+
+}
+
+// assertReadyOutput asserts that the values output by an indicator once it is
+// ready match the expected values.
+func assertReadyOutput(t *testing.T, ind indicator.Indicator,
+	expected []float64) {
+
+	t.Helper()
+
+	assertStreamOutput(t,
+		indicator.NewWarmUpStream(ind, indicator.WarmUpSuppress), expected)
+
+}
+
+// TestMAStreamDrift tests that the running sum used by the Moving Average
+// stream does not drift from an average calculated over the full frame.
+func TestMAStreamDrift(t *testing.T) {
+
+	prices := loadMockData(t)
+
+	mas := indicator.NewMAStream(input.NewListStream(prices), 200)
+	defer mas.Close()
+
+	ref := &legacyMA{
+		period: 200,
+		frame:  gollections.NewLinkedQueue(),
+		in:     input.NewListStream(prices),
+	}
+	defer ref.Close()
+
+	for i := range prices {
+
+		expected, err := ref.Next()
+		if err != nil {
+			t.Fatalf("index %d; err: %v", i, err)
+		}
+
+		streamValue, err := mas.Next()
+		if err != nil {
+			t.Fatalf("index %d; err: %v", i, err)
+		}
+
+		if util.CompareFloat(streamValue, expected) != 0 {
+			t.Fatalf("index %d; expected %.9f, got %.9f", i, expected,
+				streamValue)
+		}
+
+	}
+
+}
+
+// cycle is a stream that endlessly repeats a list of values; it is used to
+// supply benchmarks with input without measuring the cost of the input.
+type cycle struct {
+	index  int
+	values []float64
+}
+
+func (c *cycle) Next() (float64, error) {
+
+	value := c.values[c.index]
+	c.index = (c.index + 1) % len(c.values)
+
+	return value, nil
+
+}
+
+func (c *cycle) Close() {}
+
+// benchmarkMA reads b.N values from a moving average stream.
+func benchmarkMA(b *testing.B, newMA func(in stream.Stream) stream.Stream) {
+
+	in := &cycle{values: loadMockData(b)}
+	mas := newMA(in)
+	defer mas.Close()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := mas.Next(); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+}
+
+// BenchmarkMAStream26 benchmarks a 26 period Moving Average stream.
+func BenchmarkMAStream26(b *testing.B) {
+
+	benchmarkMA(b, func(in stream.Stream) stream.Stream {
+		return indicator.NewMAStream(in, 26)
+	})
+
+}
+
+// BenchmarkMAStream200 benchmarks a 200 period Moving Average stream.
+func BenchmarkMAStream200(b *testing.B) {
+
+	benchmarkMA(b, func(in stream.Stream) stream.Stream {
+		return indicator.NewMAStream(in, 200)
+	})
+
+}
+
+// BenchmarkLegacyMAStream26 benchmarks the original 26 period Moving Average
+// implementation.
+func BenchmarkLegacyMAStream26(b *testing.B) {
+
+	benchmarkMA(b, func(in stream.Stream) stream.Stream {
+		return &legacyMA{period: 26, frame: gollections.NewLinkedQueue(), in: in}
+	})
+
+}
+
+// BenchmarkLegacyMAStream200 benchmarks the original 200 period Moving Average
+// implementation.
+func BenchmarkLegacyMAStream200(b *testing.B) {
+
+	benchmarkMA(b, func(in stream.Stream) stream.Stream {
+		return &legacyMA{period: 200, frame: gollections.NewLinkedQueue(), in: in}
+	})
+
+}
@@ -0,0 +1,135 @@
+package indicator
+
+import (
+	"errors"
+	gomath "math"
+
+	"github.com/bsladewski/lapis/stream"
+)
+
+const (
+	// MACDLine names the MACD line component of a MACD stream.
+	MACDLine = "macd"
+	// MACDSignal names the signal line component of a MACD stream.
+	MACDSignal = "signal"
+	// MACDHistogram names the histogram component of a MACD stream.
+	MACDHistogram = "histogram"
+)
+
+// macd is the concrete implementation of a multi stream that applies a Moving
+// Average Convergence Divergence function to input data.
+type macd struct {
+	fastPeriod   int
+	slowPeriod   int
+	signalPeriod int
+	fast         *emaState
+	slow         *emaState
+	signal       *emaState
+	// position counts every value read, including values that are not ready
+	position int
+	in       stream.Stream
+}
+
+// NewMACDStream returns a multi stream that applies a Moving Average
+// Convergence Divergence function to input data; the MACD line is the
+// difference between a fast and a slow exponential moving average, the signal
+// line is an exponential moving average of the MACD line and the histogram is
+// the difference between the two. The signal line averages the MACD line only
+// once the MACD line is ready; until then it follows the MACD line and the
+// histogram is zero.
+func NewMACDStream(in stream.Stream, fastPeriod, slowPeriod, signalPeriod int,
+	seed Seed) MultiIndicator {
+
+	return &macd{
+		fastPeriod:   fastPeriod,
+		slowPeriod:   slowPeriod,
+		signalPeriod: signalPeriod,
+		fast: newEMAState(fastPeriod, SmoothingAlpha(fastPeriod, 2.0),
+			seed),
+		slow: newEMAState(slowPeriod, SmoothingAlpha(slowPeriod, 2.0),
+			seed),
+		signal: newEMAState(signalPeriod, SmoothingAlpha(signalPeriod, 2.0),
+			seed),
+		in: in,
+	}
+
+}
+
+// NewMACDStreams returns streams that output the MACD line, signal line and
+// histogram of a Moving Average Convergence Divergence calculation over input
+// data. The streams share a single calculation so values are computed once
+// regardless of which stream reads them first; the input is closed once all
+// three streams have been closed. Values are held for each open stream until
+// it reads them, so a stream that will not be read should be closed at once;
+// use NewMACDStream to read the components together without holding values.
+func NewMACDStreams(in stream.Stream, fastPeriod, slowPeriod, signalPeriod int,
+	seed Seed) (line, signal, histogram Indicator) {
+
+	m := NewMACDStream(in, fastPeriod, slowPeriod, signalPeriod, seed).(*macd)
+
+	components := tapIndicators(m, m.lineWarmUp(), m.WarmUp(), m.WarmUp())
+
+	return components[0], components[1], components[2]
+
+}
+
+func (m *macd) Components() []string {
+	return []string{MACDLine, MACDSignal, MACDHistogram}
+}
+
+func (m *macd) Next() ([]float64, error) {
+
+	if m.fastPeriod <= 0 || m.slowPeriod <= 0 || m.signalPeriod <= 0 {
+		return nil, errors.New("macd periods cannot be negative or zero")
+	}
+
+	// retrieve the next piece of input data
+	next, err := m.in.Next()
+	if err != nil && err != stream.ErrNotReady {
+		return nil, err
+	}
+
+	m.position++
+
+	// values flagged as not ready, or marked as not ready with NaN, are
+	// passed through unchanged rather than poisoning the averages
+	if err == stream.ErrNotReady || gomath.IsNaN(next) {
+		return []float64{next, next, next}, err
+	}
+
+	line := m.fast.update(next) - m.slow.update(next)
+
+	// the signal line is seeded only from values of the MACD line that are
+	// ready
+	signal := line
+	if m.position > m.lineWarmUp() {
+		signal = m.signal.update(line)
+	}
+
+	return []float64{line, signal, line - signal}, nil
+
+}
+
+func (m *macd) Close() {
+	m.in.Close()
+}
+
+// lineWarmUp returns the warm-up length of the MACD line which is ready once
+// the slower of the two averages is ready.
+func (m *macd) lineWarmUp() int {
+
+	warmUp := m.fastPeriod - 1
+	if m.slowPeriod > m.fastPeriod {
+		warmUp = m.slowPeriod - 1
+	}
+
+	return warmUp + inputWarmUp(m.in)
+
+}
+
+func (m *macd) WarmUp() int {
+
+	// the signal line and histogram must also fill the signal period
+	return m.lineWarmUp() + m.signalPeriod - 1
+
+}
@@ -0,0 +1,237 @@
+package indicator_test
+
+import (
+	"testing"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/math"
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// TestMACDStreams tests calculations performed by Moving Average Convergence
+// Divergence streams.
+func TestMACDStreams(t *testing.T) {
+
+	// define input data
+	inputData := []float64{3.0, 4.0, 2.0, 6.0, 4.0, 5.0, 0.0, 1.0}
+
+	// define expected output for each component
+	expectedLine := []float64{0.0, 0.0, -0.5, 1.083333333333333,
+		0.4277777777777776, 0.449259259259259, -0.9995802469135802,
+		-0.7561267489711931}
+
+	// the signal line follows the MACD line until the MACD line is ready and
+	// is then seeded from the average of its first three ready values
+	expectedSignal := []float64{0.0, 0.0, -0.5, 1.0833333333333333,
+		0.7555555555555555, 0.6534567901234568, -0.17306172839506173,
+		-0.46459423868312755}
+	expectedHistogram := []float64{0.0, 0.0, 0.0, 0.0, -0.3277777777777778,
+		-0.20419753086419754, -0.8265185185185185, -0.2915325102880658}
+
+	line, signal, histogram := indicator.NewMACDStreams(
+		input.NewListStream(inputData), 2, 4, 3, indicator.SeedSMA)
+	defer line.Close()
+	defer signal.Close()
+	defer histogram.Close()
+
+	if line.WarmUp() != 3 || signal.WarmUp() != 5 || histogram.WarmUp() != 5 {
+		t.Fatalf("expected warm-up 3, 5, 5, got %d, %d, %d", line.WarmUp(),
+			signal.WarmUp(), histogram.WarmUp())
+	}
+
+	// read the histogram ahead of the other components, the remaining
+	// components should be read from the values already computed
+	assertStreamOutput(t, histogram, expectedHistogram)
+	assertStreamOutput(t, line, expectedLine)
+	assertStreamOutput(t, signal, expectedSignal)
+
+}
+
+// TestMACDStreamsClosed tests reading a single MACD component once the other
+// components have been closed.
+func TestMACDStreamsClosed(t *testing.T) {
+
+	// define input data
+	inputData := []float64{3.0, 4.0, 2.0, 6.0, 4.0, 5.0, 0.0, 1.0}
+
+	line, signal, histogram := indicator.NewMACDStreams(
+		input.NewListStream(inputData), 2, 4, 3, indicator.SeedSMA)
+	defer line.Close()
+
+	// closed components no longer hold the values read by the line
+	signal.Close()
+	histogram.Close()
+
+	if _, err := signal.Next(); err != stream.ErrEndOfStream {
+		t.Fatalf("expected end of stream error, got %v", err)
+	}
+
+	assertStreamOutput(t, line, []float64{0.0, 0.0, -0.5, 1.083333333333333,
+		0.4277777777777776, 0.449259259259259, -0.9995802469135802,
+		-0.7561267489711931})
+
+}
+
+// TestMACDLine tests that the MACD line matches the difference of two
+// exponential moving average streams over mock data.
+func TestMACDLine(t *testing.T) {
+
+	prices := loadMockData(t)
+
+	line, signal, histogram := indicator.NewMACDStreams(
+		input.NewListStream(prices), 12, 26, 9, indicator.SeedSMA)
+	defer line.Close()
+	defer signal.Close()
+	defer histogram.Close()
+
+	splitter := input.NewSplitterStream(input.NewListStream(prices), 2)
+	ref := math.NewSubStream(
+		indicator.NewEMAStream(splitter, 12, indicator.SeedSMA),
+		indicator.NewEMAStream(splitter, 26, indicator.SeedSMA),
+	)
+	defer ref.Close()
+
+	for i := range prices {
+
+		expected, err := ref.Next()
+		if err != nil {
+			t.Fatalf("index %d; err: %v", i, err)
+		}
+
+		// read each component in lockstep
+		lineValue, err := line.Next()
+		if err != nil {
+			t.Fatalf("index %d; err: %v", i, err)
+		}
+
+		signalValue, err := signal.Next()
+		if err != nil {
+			t.Fatalf("index %d; err: %v", i, err)
+		}
+
+		histogramValue, err := histogram.Next()
+		if err != nil {
+			t.Fatalf("index %d; err: %v", i, err)
+		}
+
+		if util.CompareFloat(lineValue, expected) != 0 {
+			t.Fatalf("index %d; expected %.9f, got %.9f", i, expected,
+				lineValue)
+		}
+
+		if util.CompareFloat(histogramValue, lineValue-signalValue) != 0 {
+			t.Fatalf("index %d; expected %.9f, got %.9f", i,
+				lineValue-signalValue, histogramValue)
+		}
+
+	}
+
+	// assert that next results in end of stream error
+	if _, err := line.Next(); err != stream.ErrEndOfStream {
+		t.Fatalf("expected end of stream error, got %v", err)
+	}
+
+}
+
+// TestMACDReference tests that the MACD components match reference values
+// once ready; each average is seeded from the simple moving average of its
+// first period inputs and the signal line averages only ready values of the
+// MACD line.
+func TestMACDReference(t *testing.T) {
+
+	line, signal, histogram := indicator.NewMACDStreams(
+		input.NewListStream(referencePrices), 5, 10, 4, indicator.SeedSMA)
+	defer line.Close()
+	defer signal.Close()
+	defer histogram.Close()
+
+	assertReadyOutput(t, line, []float64{
+		-7.1011440329, -7.9574293553, -13.3119226005, -16.6666811827,
+		-12.0561445801, -2.1833582769, 0.6462438447, 2.7823150720,
+		8.5273045249, 12.9880681947, 0.5719351543, -4.3109213620,
+		-13.7039387731, -30.2877701627, -37.6690860623, -28.0804652764,
+		-23.7530075583, -22.4120907692, 15.5776269200, 33.0824652402,
+		39.1164700669,
+	})
+
+	assertReadyOutput(t, signal, []float64{
+		-11.2592942928, -11.5780344077, -7.8201639554, -4.4336008353,
+		-1.5472344724, 2.4825811265, 6.6847759538, 4.2396396340,
+		0.8194152356, -4.9899263679, -15.1090638858, -24.1330727564,
+		-25.7120297644, -24.9284208820, -23.9218888368, -8.1220825341,
+		8.3597365756, 20.6624299722,
+	})
+
+	assertReadyOutput(t, histogram, []float64{
+		-5.4073868898, -0.4781101724, 5.6368056785, 5.0798446801,
+		4.3295495444, 6.0447233984, 6.3032922409, -3.6677044797,
+		-5.1303365976, -8.7140124052, -15.1787062769, -13.5360133059,
+		-2.3684355120, 1.1754133236, 1.5097980677, 23.6997094541,
+		24.7227286646, 18.4540400948,
+	})
+
+}
+
+// TestMACDStream tests that a MACD multi stream outputs every component of the
+// calculation together.
+func TestMACDStream(t *testing.T) {
+
+	// define input data
+	inputData := []float64{3.0, 4.0, 2.0, 6.0}
+
+	// define expected output
+	expectedOutput := [][]float64{
+		{0.0, 0.0, 0.0},
+		{0.0, 0.0, 0.0},
+		{-0.5, -0.5, 0.0},
+		{1.083333333333333, 1.083333333333333, 0.0},
+	}
+
+	ms := indicator.NewMACDStream(input.NewListStream(inputData), 2, 4, 3,
+		indicator.SeedSMA)
+	defer ms.Close()
+
+	components := ms.Components()
+	if len(components) != 3 || components[0] != indicator.MACDLine ||
+		components[1] != indicator.MACDSignal ||
+		components[2] != indicator.MACDHistogram {
+		t.Fatalf("unexpected components: %v", components)
+	}
+
+	assertMultiStreamOutput(t, ms, expectedOutput)
+
+}
+
+// assertMultiStreamOutput asserts that a multi stream outputs the expected
+// sets of values followed by an end of stream error.
+func assertMultiStreamOutput(t *testing.T, ms stream.MultiStream,
+	expected [][]float64) {
+
+	t.Helper()
+
+	components := ms.Components()
+
+	for i, expectedValues := range expected {
+
+		values, err := ms.Next()
+		if err != nil {
+			t.Fatalf("index %d; err: %v", i, err)
+		}
+
+		for j, value := range expectedValues {
+			if util.CompareFloat(values[j], value) != 0 {
+				t.Fatalf("index %d, component %s; expected %.9f, got %.9f",
+					i, components[j], value, values[j])
+			}
+		}
+
+	}
+
+	// assert that next results in end of stream error
+	if _, err := ms.Next(); err != stream.ErrEndOfStream {
+		t.Fatalf("expected end of stream error, got %v", err)
+	}
+
+}
@@ -0,0 +1,86 @@
+package indicator
+
+import (
+	"errors"
+	gomath "math"
+
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// momentum is the concrete implementation of a stream that compares each input
+// value to the value received a number of periods earlier.
+type momentum struct {
+	period  int
+	percent bool
+	frame   *util.Ring[float64]
+	in      stream.Stream
+}
+
+// NewMomentumStream returns a stream that outputs the difference between each
+// input value and the value received period values earlier; until period
+// values have been received the earliest value is used.
+func NewMomentumStream(in stream.Stream, period int) Indicator {
+
+	return &momentum{
+		period: period,
+		frame:  util.NewRing[float64](period + 1),
+		in:     in,
+	}
+
+}
+
+// NewROCStream returns a stream that outputs the Rate of Change, as a
+// percentage, between each input value and the value received period values
+// earlier; until period values have been received the earliest value is used.
+func NewROCStream(in stream.Stream, period int) Indicator {
+
+	return &momentum{
+		period:  period,
+		percent: true,
+		frame:   util.NewRing[float64](period + 1),
+		in:      in,
+	}
+
+}
+
+func (m *momentum) Next() (float64, error) {
+
+	if m.period <= 0 {
+		return 0.0, errors.New("momentum period cannot be negative or zero")
+	}
+
+	// retrieve the next piece of input data
+	next, err := m.in.Next()
+	if err != nil && err != stream.ErrNotReady {
+		return 0.0, err
+	}
+
+	// values flagged as not ready, or marked as not ready with NaN, are
+	// passed through unchanged rather than storing them in the frame
+	if err == stream.ErrNotReady || gomath.IsNaN(next) {
+		return next, err
+	}
+
+	m.frame.Add(next)
+	previous := m.frame.Get(0)
+
+	if !m.percent {
+		return next - previous, nil
+	}
+
+	if previous == 0.0 {
+		return 0.0, errors.New("rate of change from zero is undefined")
+	}
+
+	return 100.0 * (next - previous) / previous, nil
+
+}
+
+func (m *momentum) Close() {
+	m.in.Close()
+}
+
+func (m *momentum) WarmUp() int {
+	return m.period + inputWarmUp(m.in)
+}
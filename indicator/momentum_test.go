@@ -0,0 +1,56 @@
+package indicator_test
+
+import (
+	"testing"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+)
+
+// TestMomentumStream tests calculations performed by a Momentum stream.
+func TestMomentumStream(t *testing.T) {
+
+	// define input data
+	inputData := []float64{3.0, 4.0, 2.0, 6.0, 4.0, 5.0, 0.0, 1.0}
+
+	// define expected output
+	expectedOutput := []float64{0.0, 1.0, -1.0, 2.0, 2.0, -1.0, -4.0, -4.0}
+
+	ms := indicator.NewMomentumStream(input.NewListStream(inputData), 2)
+	defer ms.Close()
+
+	assertStreamOutput(t, ms, expectedOutput)
+
+}
+
+// TestROCStream tests calculations performed by a Rate of Change stream.
+func TestROCStream(t *testing.T) {
+
+	// define input data
+	inputData := []float64{3.0, 4.0, 2.0, 6.0, 4.0, 5.0, 0.0, 1.0}
+
+	// define expected output
+	expectedOutput := []float64{0.0, 33.333333333333336, -33.333333333333336,
+		50.0, 100.0, -16.666666666666668, -100.0, -80.0}
+
+	rs := indicator.NewROCStream(input.NewListStream(inputData), 2)
+	defer rs.Close()
+
+	assertStreamOutput(t, rs, expectedOutput)
+
+	// assert that a rate of change from zero results in an error
+	rs = indicator.NewROCStream(input.NewListStream([]float64{1.0, 0.0, 1.0}),
+		1)
+	defer rs.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := rs.Next(); err != nil {
+			t.Fatalf("index %d; err: %v", i, err)
+		}
+	}
+
+	if _, err := rs.Next(); err == nil {
+		t.Fatal("expected rate of change from zero error")
+	}
+
+}
@@ -0,0 +1,249 @@
+package indicator
+
+import (
+	"errors"
+	gomath "math"
+
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// williamsR is the concrete implementation of a stream that applies a Williams
+// %R function to input candles.
+type williamsR struct {
+	period int
+	highs  *extrema
+	lows   *extrema
+	in     stream.CandleStream
+}
+
+// NewWilliamsRStream returns a stream that applies a Williams %R function to
+// input candles; the output is the position of the close price within the high
+// and low of the period scaled from -100 to 0, or -50 if the price has not
+// moved over the period.
+func NewWilliamsRStream(in stream.CandleStream, period int) Indicator {
+
+	return &williamsR{
+		period: period,
+		highs:  newExtrema(period),
+		lows:   newExtrema(period),
+		in:     in,
+	}
+
+}
+
+func (w *williamsR) Next() (float64, error) {
+
+	if w.period <= 0 {
+		return 0.0, errors.New("williams %r period cannot be negative or zero")
+	}
+
+	// retrieve the next candle
+	candle, err := w.in.Next()
+	if err != nil {
+		return 0.0, err
+	}
+
+	w.highs.update(candle.High)
+	w.lows.update(candle.Low)
+
+	highest, lowest := w.highs.max(), w.lows.min()
+	if highest == lowest {
+		return -50.0, nil
+	}
+
+	return -100.0 * ((highest - candle.Close) / (highest - lowest)), nil
+
+}
+
+func (w *williamsR) Close() {
+	w.in.Close()
+}
+
+func (w *williamsR) WarmUp() int {
+	return w.period - 1
+}
+
+// cci is the concrete implementation of a stream that applies a Commodity
+// Channel Index function to input candles.
+type cci struct {
+	period int
+	frame  *util.Ring[float64]
+	sum    util.KahanSum
+	in     stream.CandleStream
+}
+
+// NewCCIStream returns a stream that applies a Commodity Channel Index function
+// to input candles; the index measures the deviation of the typical price from
+// its moving average relative to the mean absolute deviation over the period.
+func NewCCIStream(in stream.CandleStream, period int) Indicator {
+
+	return &cci{
+		period: period,
+		frame:  util.NewRing[float64](period),
+		in:     in,
+	}
+
+}
+
+func (c *cci) Next() (float64, error) {
+
+	if c.period <= 0 {
+		return 0.0, errors.New(
+			"commodity channel index period cannot be negative or zero")
+	}
+
+	// retrieve the next candle
+	candle, err := c.in.Next()
+	if err != nil {
+		return 0.0, err
+	}
+
+	typical := typicalPrice(candle)
+
+	if evicted, ok := c.frame.Add(typical); ok {
+		c.sum.Add(-evicted)
+	}
+	c.sum.Add(typical)
+
+	mean := c.sum.Value() / float64(c.frame.Size())
+
+	// the mean deviation must be measured from the current mean so it cannot
+	// be maintained incrementally
+	deviation := 0.0
+	for i := 0; i < c.frame.Size(); i++ {
+		deviation += gomath.Abs(c.frame.Get(i) - mean)
+	}
+	deviation /= float64(c.frame.Size())
+
+	if deviation == 0.0 {
+		return 0.0, nil
+	}
+
+	return (typical - mean) / (0.015 * deviation), nil
+
+}
+
+func (c *cci) Close() {
+	c.in.Close()
+}
+
+func (c *cci) WarmUp() int {
+	return c.period - 1
+}
+
+// typicalPrice returns the average of the high, low and close prices of a
+// candle.
+func typicalPrice(candle stream.Candle) float64 {
+	return (candle.High + candle.Low + candle.Close) / 3.0
+}
+
+// ultimateOscillator is the concrete implementation of a stream that applies
+// an Ultimate Oscillator function to input candles.
+type ultimateOscillator struct {
+	periods   [3]int
+	pressures [3]*util.Ring[float64]
+	ranges    [3]*util.Ring[float64]
+	pressure  [3]util.KahanSum
+	trueRange [3]util.KahanSum
+	previous  float64
+	started   bool
+	in        stream.CandleStream
+}
+
+// NewUltimateOscillatorStream returns a stream that applies Williams' Ultimate
+// Oscillator function to input candles; buying pressure relative to the true
+// range is averaged over a short, medium and long period, conventionally 7, 14
+// and 28, and the averages are weighted 4:2:1. The output ranges from 0 to 100.
+func NewUltimateOscillatorStream(in stream.CandleStream, shortPeriod,
+	mediumPeriod, longPeriod int) Indicator {
+
+	u := &ultimateOscillator{
+		periods: [3]int{shortPeriod, mediumPeriod, longPeriod},
+		in:      in,
+	}
+
+	for i, period := range u.periods {
+		u.pressures[i] = util.NewRing[float64](period)
+		u.ranges[i] = util.NewRing[float64](period)
+	}
+
+	return u
+
+}
+
+func (u *ultimateOscillator) Next() (float64, error) {
+
+	for _, period := range u.periods {
+		if period <= 0 {
+			return 0.0, errors.New(
+				"ultimate oscillator periods cannot be negative or zero")
+		}
+	}
+
+	// retrieve the next candle
+	candle, err := u.in.Next()
+	if err != nil {
+		return 0.0, err
+	}
+
+	// the true low and high include the previous close
+	low, high := candle.Low, candle.High
+	if u.started {
+		low = gomath.Min(low, u.previous)
+		high = gomath.Max(high, u.previous)
+	}
+	u.previous = candle.Close
+	u.started = true
+
+	pressure := candle.Close - low
+	trueRange := high - low
+
+	// calculate the weighted average of buying pressure relative to true range
+	// for each period; a period without range contributes its midpoint
+	weights := [3]float64{4.0, 2.0, 1.0}
+	result := 0.0
+	for i := range u.periods {
+
+		if evicted, ok := u.pressures[i].Add(pressure); ok {
+			u.pressure[i].Add(-evicted)
+		}
+		u.pressure[i].Add(pressure)
+
+		if evicted, ok := u.ranges[i].Add(trueRange); ok {
+			u.trueRange[i].Add(-evicted)
+		}
+		u.trueRange[i].Add(trueRange)
+
+		average := 0.5
+		if rangeSum := u.trueRange[i].Value(); util.CompareFloat(rangeSum,
+			0.0) != 0 {
+			average = u.pressure[i].Value() / rangeSum
+		}
+
+		result += weights[i] * average
+
+	}
+
+	return 100.0 * result / 7.0, nil
+
+}
+
+func (u *ultimateOscillator) Close() {
+	u.in.Close()
+}
+
+func (u *ultimateOscillator) WarmUp() int {
+
+	longest := 0
+	for _, period := range u.periods {
+		if period > longest {
+			longest = period
+		}
+	}
+
+	// the first candle has no previous close so an additional candle is needed
+	// to fill the longest period with true ranges
+	return longest
+
+}
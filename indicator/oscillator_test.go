@@ -0,0 +1,83 @@
+package indicator_test
+
+import (
+	"testing"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// oscillatorCandles is the candle input used to test oscillator streams.
+var oscillatorCandles = []stream.Candle{
+	{High: 4.0, Low: 2.0, Close: 3.0},
+	{High: 5.0, Low: 3.0, Close: 4.0},
+	{High: 6.0, Low: 4.0, Close: 5.0},
+	{High: 5.0, Low: 2.0, Close: 2.0},
+	{High: 3.0, Low: 1.0, Close: 3.0},
+}
+
+// TestWilliamsRStream tests calculations performed by a Williams %R stream.
+func TestWilliamsRStream(t *testing.T) {
+
+	// define expected output
+	expectedOutput := []float64{-50.0, -33.333333333333336, -25.0, -100.0,
+		-60.0}
+
+	ws := indicator.NewWilliamsRStream(
+		input.NewCandleListStream(oscillatorCandles), 3)
+	defer ws.Close()
+
+	assertStreamOutput(t, ws, expectedOutput)
+
+	// assert that the oscillator is bounded over mock data
+	ws = indicator.NewWilliamsRStream(
+		input.NewCandleListStream(loadMockCandles(t)), 14)
+	defer ws.Close()
+
+	assertStreamBounds(t, ws, -100.0, 0.0)
+
+}
+
+// TestCCIStream tests calculations performed by a Commodity Channel Index
+// stream.
+func TestCCIStream(t *testing.T) {
+
+	// define expected output
+	expectedOutput := []float64{0.0, 66.66666666666667, 100.00000000000001,
+		-100.00000000000001, -71.42857142857144}
+
+	cs := indicator.NewCCIStream(input.NewCandleListStream(oscillatorCandles),
+		3)
+	defer cs.Close()
+
+	assertStreamOutput(t, cs, expectedOutput)
+
+}
+
+// TestUltimateOscillatorStream tests calculations performed by an Ultimate
+// Oscillator stream.
+func TestUltimateOscillatorStream(t *testing.T) {
+
+	// define expected output
+	expectedOutput := []float64{50.0, 50.0, 50.0, 9.795918367346939,
+		74.6938775510204}
+
+	us := indicator.NewUltimateOscillatorStream(
+		input.NewCandleListStream(oscillatorCandles), 1, 2, 3)
+	defer us.Close()
+
+	if us.WarmUp() != 3 {
+		t.Fatalf("expected warm-up 3, got %d", us.WarmUp())
+	}
+
+	assertStreamOutput(t, us, expectedOutput)
+
+	// assert that the oscillator is bounded over mock data
+	us = indicator.NewUltimateOscillatorStream(
+		input.NewCandleListStream(loadMockCandles(t)), 7, 14, 28)
+	defer us.Close()
+
+	assertStreamBounds(t, us, 0.0, 100.0)
+
+}
@@ -0,0 +1,481 @@
+package indicator
+
+import (
+	"errors"
+	gomath "math"
+
+	"github.com/bsladewski/lapis/stream"
+)
+
+// A Pattern names a candlestick pattern.
+type Pattern string
+
+// The candlestick patterns recognized by a pattern stream.
+const (
+	// PatternDoji is a candle whose open and close are nearly equal.
+	PatternDoji Pattern = "doji"
+	// PatternHammer is a candle with a small body at the top of its range and
+	// a long lower wick following a downtrend.
+	PatternHammer Pattern = "hammer"
+	// PatternHangingMan is a candle with a small body at the top of its range
+	// and a long lower wick following an uptrend.
+	PatternHangingMan Pattern = "hanging_man"
+	// PatternInvertedHammer is a candle with a small body at the bottom of its
+	// range and a long upper wick following a downtrend.
+	PatternInvertedHammer Pattern = "inverted_hammer"
+	// PatternShootingStar is a candle with a small body at the bottom of its
+	// range and a long upper wick following an uptrend.
+	PatternShootingStar Pattern = "shooting_star"
+	// PatternBullishEngulfing is a rising candle whose body engulfs the body of
+	// the falling candle before it.
+	PatternBullishEngulfing Pattern = "bullish_engulfing"
+	// PatternBearishEngulfing is a falling candle whose body engulfs the body
+	// of the rising candle before it.
+	PatternBearishEngulfing Pattern = "bearish_engulfing"
+	// PatternBullishHarami is a rising candle whose body lies within the body
+	// of the long falling candle before it.
+	PatternBullishHarami Pattern = "bullish_harami"
+	// PatternBearishHarami is a falling candle whose body lies within the body
+	// of the long rising candle before it.
+	PatternBearishHarami Pattern = "bearish_harami"
+	// PatternMorningStar is a long falling candle, a small bodied candle below
+	// its close and a long rising candle closing above its midpoint.
+	PatternMorningStar Pattern = "morning_star"
+	// PatternEveningStar is a long rising candle, a small bodied candle above
+	// its close and a long falling candle closing below its midpoint.
+	PatternEveningStar Pattern = "evening_star"
+	// PatternThreeWhiteSoldiers is three long rising candles, each opening
+	// within the body of the one before and closing higher.
+	PatternThreeWhiteSoldiers Pattern = "three_white_soldiers"
+	// PatternThreeBlackCrows is three long falling candles, each opening within
+	// the body of the one before and closing lower.
+	PatternThreeBlackCrows Pattern = "three_black_crows"
+)
+
+// Signal returns 1 for a bullish pattern, -1 for a bearish pattern and 0 for a
+// pattern that signals indecision.
+func (p Pattern) Signal() int {
+
+	switch p {
+	case PatternHammer, PatternInvertedHammer, PatternBullishEngulfing,
+		PatternBullishHarami, PatternMorningStar, PatternThreeWhiteSoldiers:
+		return 1
+	case PatternHangingMan, PatternShootingStar, PatternBearishEngulfing,
+		PatternBearishHarami, PatternEveningStar, PatternThreeBlackCrows:
+		return -1
+	}
+
+	return 0
+
+}
+
+// PatternThresholds configures the proportions of candles used to recognize
+// candlestick patterns; bodies are measured relative to the range of their
+// candle.
+type PatternThresholds struct {
+	// DojiBody is the largest body of a doji.
+	DojiBody float64
+	// SmallBody is the largest body of a small bodied candle, such as a hammer
+	// or the middle candle of a star.
+	SmallBody float64
+	// LongBody is the smallest body of a long candle, such as the candles of
+	// three white soldiers.
+	LongBody float64
+	// LongWick is the smallest length of the long wick of a hammer or shooting
+	// star relative to its body.
+	LongWick float64
+	// ShortWick is the largest length of the short wick of a hammer or shooting
+	// star relative to its range.
+	ShortWick float64
+	// TrendPeriod is the number of candles over which the trend preceding a
+	// single candle pattern is measured.
+	TrendPeriod int
+}
+
+// DefaultPatternThresholds returns commonly used pattern thresholds.
+func DefaultPatternThresholds() PatternThresholds {
+
+	return PatternThresholds{
+		DojiBody:    0.1,
+		SmallBody:   0.3,
+		LongBody:    0.6,
+		LongWick:    2.0,
+		ShortWick:   0.1,
+		TrendPeriod: 5,
+	}
+
+}
+
+// validate returns an error if any threshold is out of range.
+func (t PatternThresholds) validate() error {
+
+	for _, threshold := range []float64{t.DojiBody, t.SmallBody, t.LongBody,
+		t.LongWick, t.ShortWick} {
+		if threshold < 0 || gomath.IsNaN(threshold) {
+			return errors.New("pattern thresholds cannot be negative")
+		}
+	}
+
+	if t.TrendPeriod <= 0 {
+		return errors.New("pattern trend period cannot be negative or zero")
+	}
+
+	return nil
+
+}
+
+// shape holds the proportions of a candle.
+type shape struct {
+	candle stream.Candle
+	body   float64
+	span   float64
+	upper  float64
+	lower  float64
+}
+
+// newShape measures the proportions of a candle.
+func newShape(candle stream.Candle) shape {
+
+	top := gomath.Max(candle.Open, candle.Close)
+	bottom := gomath.Min(candle.Open, candle.Close)
+
+	return shape{
+		candle: candle,
+		body:   top - bottom,
+		span:   candle.High - candle.Low,
+		upper:  candle.High - top,
+		lower:  bottom - candle.Low,
+	}
+
+}
+
+// rising returns whether the candle closed above its open.
+func (s shape) rising() bool {
+	return s.candle.Close > s.candle.Open
+}
+
+// falling returns whether the candle closed below its open.
+func (s shape) falling() bool {
+	return s.candle.Close < s.candle.Open
+}
+
+// top returns the top of the candle's body.
+func (s shape) top() float64 {
+	return gomath.Max(s.candle.Open, s.candle.Close)
+}
+
+// bottom returns the bottom of the candle's body.
+func (s shape) bottom() float64 {
+	return gomath.Min(s.candle.Open, s.candle.Close)
+}
+
+// midpoint returns the midpoint of the candle's body.
+func (s shape) midpoint() float64 {
+	return (s.candle.Open + s.candle.Close) / 2.0
+}
+
+// within returns whether the candle's body lies within the body of another
+// candle.
+func (s shape) within(other shape) bool {
+	return s.top() <= other.top() && s.bottom() >= other.bottom()
+}
+
+// opensWithin returns whether the candle opens within the body of another
+// candle.
+func (s shape) opensWithin(other shape) bool {
+	return s.candle.Open <= other.top() && s.candle.Open >= other.bottom()
+}
+
+// patternState holds the candles needed to recognize candlestick patterns.
+type patternState struct {
+	thresholds PatternThresholds
+	history    []shape
+}
+
+// update adds a candle to the state and returns the patterns completed by the
+// candle.
+func (p *patternState) update(candle stream.Candle) []Pattern {
+
+	// retain the candles needed by triple candle patterns and the trend before
+	// a single candle pattern
+	limit := p.thresholds.TrendPeriod + 2
+	if limit < 3 {
+		limit = 3
+	}
+
+	p.history = append(p.history, newShape(candle))
+	if len(p.history) > limit {
+		p.history = p.history[1:]
+	}
+
+	var patterns []Pattern
+
+	p.single(&patterns)
+
+	if len(p.history) >= 2 {
+		p.double(&patterns)
+	}
+
+	if len(p.history) >= 3 {
+		p.triple(&patterns)
+	}
+
+	return patterns
+
+}
+
+// small returns whether the body of a candle is small.
+func (p *patternState) small(s shape) bool {
+	return s.body <= p.thresholds.SmallBody*s.span
+}
+
+// long returns whether the body of a candle is long.
+func (p *patternState) long(s shape) bool {
+	return s.span > 0 && s.body >= p.thresholds.LongBody*s.span
+}
+
+// trend returns 1 if the close before the latest candle is above the close
+// trend period candles before it, -1 if it is below and 0 if it is level or
+// there are not enough candles to measure the trend.
+func (p *patternState) trend() int {
+
+	last := len(p.history) - 2
+	first := last - p.thresholds.TrendPeriod
+	if first < 0 {
+		return 0
+	}
+
+	change := p.history[last].candle.Close - p.history[first].candle.Close
+	if change > 0 {
+		return 1
+	} else if change < 0 {
+		return -1
+	}
+
+	return 0
+
+}
+
+// single appends the single candle patterns formed by the latest candle.
+func (p *patternState) single(patterns *[]Pattern) {
+
+	s := p.history[len(p.history)-1]
+	t := p.thresholds
+
+	if s.span <= 0 {
+		return
+	}
+
+	if s.body <= t.DojiBody*s.span {
+		*patterns = append(*patterns, PatternDoji)
+	}
+
+	if !p.small(s) {
+		return
+	}
+
+	// a hammer and hanging man share a shape, as do an inverted hammer and
+	// shooting star; the preceding trend tells them apart
+	trend := p.trend()
+
+	if s.lower > 0 && s.lower >= t.LongWick*s.body &&
+		s.upper <= t.ShortWick*s.span {
+		if trend < 0 {
+			*patterns = append(*patterns, PatternHammer)
+		} else if trend > 0 {
+			*patterns = append(*patterns, PatternHangingMan)
+		}
+	}
+
+	if s.upper > 0 && s.upper >= t.LongWick*s.body &&
+		s.lower <= t.ShortWick*s.span {
+		if trend < 0 {
+			*patterns = append(*patterns, PatternInvertedHammer)
+		} else if trend > 0 {
+			*patterns = append(*patterns, PatternShootingStar)
+		}
+	}
+
+}
+
+// double appends the double candle patterns formed by the latest candles.
+func (p *patternState) double(patterns *[]Pattern) {
+
+	first := p.history[len(p.history)-2]
+	second := p.history[len(p.history)-1]
+
+	engulfs := first.within(second) && second.body > first.body
+
+	if first.falling() && second.rising() {
+		if engulfs {
+			*patterns = append(*patterns, PatternBullishEngulfing)
+		} else if p.long(first) && second.within(first) &&
+			second.body < first.body {
+			*patterns = append(*patterns, PatternBullishHarami)
+		}
+	}
+
+	if first.rising() && second.falling() {
+		if engulfs {
+			*patterns = append(*patterns, PatternBearishEngulfing)
+		} else if p.long(first) && second.within(first) &&
+			second.body < first.body {
+			*patterns = append(*patterns, PatternBearishHarami)
+		}
+	}
+
+}
+
+// triple appends the triple candle patterns formed by the latest candles.
+func (p *patternState) triple(patterns *[]Pattern) {
+
+	first := p.history[len(p.history)-3]
+	second := p.history[len(p.history)-2]
+	third := p.history[len(p.history)-1]
+
+	if p.long(first) && p.small(second) && p.long(third) {
+
+		if first.falling() && third.rising() &&
+			second.top() < first.candle.Close &&
+			third.candle.Close > first.midpoint() {
+			*patterns = append(*patterns, PatternMorningStar)
+		}
+
+		if first.rising() && third.falling() &&
+			second.bottom() > first.candle.Close &&
+			third.candle.Close < first.midpoint() {
+			*patterns = append(*patterns, PatternEveningStar)
+		}
+
+	}
+
+	if !p.long(first) || !p.long(second) || !p.long(third) {
+		return
+	}
+
+	if first.rising() && second.rising() && third.rising() &&
+		second.opensWithin(first) && third.opensWithin(second) &&
+		second.candle.Close > first.candle.Close &&
+		third.candle.Close > second.candle.Close {
+		*patterns = append(*patterns, PatternThreeWhiteSoldiers)
+	}
+
+	if first.falling() && second.falling() && third.falling() &&
+		second.opensWithin(first) && third.opensWithin(second) &&
+		second.candle.Close < first.candle.Close &&
+		third.candle.Close < second.candle.Close {
+		*patterns = append(*patterns, PatternThreeBlackCrows)
+	}
+
+}
+
+// A PatternStream provides the candlestick patterns completed by each candle
+// in a candle stream.
+type PatternStream interface {
+	// Next gets the patterns completed by the next candle in the stream.
+	Next() ([]Pattern, error)
+	// Close closes any resources the stream is currently reading.
+	Close()
+}
+
+// patterns is the concrete implementation of a stream that recognizes
+// candlestick patterns in input candles.
+type patterns struct {
+	state patternState
+	in    stream.CandleStream
+}
+
+// NewPatternStream returns a stream that outputs the candlestick patterns
+// completed by each input candle, in the order single, double then triple
+// candle patterns; a candle that completes no pattern outputs an empty set.
+// Hammers, hanging men, inverted hammers and shooting stars are only
+// recognized once the trend before them can be measured.
+func NewPatternStream(in stream.CandleStream,
+	thresholds PatternThresholds) PatternStream {
+
+	return &patterns{
+		state: patternState{thresholds: thresholds},
+		in:    in,
+	}
+
+}
+
+func (p *patterns) Next() ([]Pattern, error) {
+
+	if err := p.state.thresholds.validate(); err != nil {
+		return nil, err
+	}
+
+	// retrieve the next candle
+	candle, err := p.in.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.state.update(candle), nil
+
+}
+
+func (p *patterns) Close() {
+	p.in.Close()
+}
+
+// patternSignal is the concrete implementation of a stream that outputs the
+// signal of candlestick patterns in input candles.
+type patternSignal struct {
+	state patternState
+	in    stream.CandleStream
+}
+
+// NewPatternSignalStream returns a stream that outputs 1 when the bullish
+// candlestick patterns completed by an input candle outnumber the bearish
+// patterns, -1 when the bearish patterns outnumber the bullish patterns and 0
+// otherwise.
+func NewPatternSignalStream(in stream.CandleStream,
+	thresholds PatternThresholds) Indicator {
+
+	return &patternSignal{
+		state: patternState{thresholds: thresholds},
+		in:    in,
+	}
+
+}
+
+func (p *patternSignal) Next() (float64, error) {
+
+	if err := p.state.thresholds.validate(); err != nil {
+		return 0.0, err
+	}
+
+	// retrieve the next candle
+	candle, err := p.in.Next()
+	if err != nil {
+		return 0.0, err
+	}
+
+	var signal int
+	for _, pattern := range p.state.update(candle) {
+		signal += pattern.Signal()
+	}
+
+	if signal > 0 {
+		return 1.0, nil
+	} else if signal < 0 {
+		return -1.0, nil
+	}
+
+	return 0.0, nil
+
+}
+
+func (p *patternSignal) Close() {
+	p.in.Close()
+}
+
+func (p *patternSignal) WarmUp() int {
+
+	// measuring the trend before a single candle pattern takes the most
+	// candles
+	return p.state.thresholds.TrendPeriod + 1
+
+}
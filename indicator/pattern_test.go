@@ -0,0 +1,215 @@
+package indicator_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// patternCandles returns hourly candles with the supplied open, high, low and
+// close prices.
+func patternCandles(prices ...[4]float64) []stream.Candle {
+
+	start := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	candles := make([]stream.Candle, len(prices))
+	for i, p := range prices {
+		candles[i] = stream.Candle{
+			Timestamp: start.Add(time.Duration(i) * time.Hour),
+			Open:      p[0],
+			High:      p[1],
+			Low:       p[2],
+			Close:     p[3],
+		}
+	}
+
+	return candles
+
+}
+
+var (
+	// downtrend is a series of falling candles preceding a pattern.
+	downtrend = [][4]float64{{20, 20, 18, 18}, {18, 18, 16, 16},
+		{16, 16, 14, 14}}
+	// uptrend is a series of rising candles preceding a pattern.
+	uptrend = [][4]float64{{10, 12, 10, 12}, {12, 14, 12, 14},
+		{14, 16, 14, 16}}
+)
+
+// patternCases are candle inputs along with the patterns completed by the last
+// candle of each input.
+var patternCases = []struct {
+	name     string
+	prices   [][4]float64
+	expected []indicator.Pattern
+}{
+	{"TestDoji", [][4]float64{{10, 12, 8, 10.1}},
+		[]indicator.Pattern{indicator.PatternDoji}},
+	{"TestHammer", append(downtrend, [4]float64{11.6, 12.3, 9, 12.2}),
+		[]indicator.Pattern{indicator.PatternHammer}},
+	{"TestHangingMan", append(uptrend, [4]float64{17.4, 18.1, 14.8, 18}),
+		[]indicator.Pattern{indicator.PatternHangingMan}},
+	{"TestInvertedHammer",
+		append(downtrend, [4]float64{12.2, 15.2, 11.9, 12.8}),
+		[]indicator.Pattern{indicator.PatternInvertedHammer}},
+	{"TestShootingStar", append(uptrend, [4]float64{17.4, 20.6, 17.3, 18}),
+		[]indicator.Pattern{indicator.PatternShootingStar}},
+	{"TestBullishEngulfing",
+		[][4]float64{{12, 12.5, 10.5, 11}, {10.8, 12.6, 10.7, 12.3}},
+		[]indicator.Pattern{indicator.PatternBullishEngulfing}},
+	{"TestBearishEngulfing",
+		[][4]float64{{11, 12.5, 10.5, 12}, {12.2, 12.3, 10.4, 10.7}},
+		[]indicator.Pattern{indicator.PatternBearishEngulfing}},
+	{"TestBullishHarami",
+		[][4]float64{{14, 14.2, 9.8, 10}, {11, 12.5, 10.5, 12}},
+		[]indicator.Pattern{indicator.PatternBullishHarami}},
+	{"TestBearishHarami",
+		[][4]float64{{10, 14.2, 9.8, 14}, {13, 13.5, 11.5, 12}},
+		[]indicator.Pattern{indicator.PatternBearishHarami}},
+	{"TestMorningStar", [][4]float64{{20, 20.2, 15.8, 16},
+		{15, 15.6, 14, 15.2}, {15.5, 19.2, 15.3, 19}},
+		[]indicator.Pattern{indicator.PatternMorningStar}},
+	{"TestEveningStar", [][4]float64{{16, 20.2, 15.8, 20},
+		{21, 22, 20.4, 21.2}, {20.5, 20.7, 16.8, 17}},
+		[]indicator.Pattern{indicator.PatternEveningStar}},
+	{"TestThreeWhiteSoldiers", [][4]float64{{10, 12.1, 9.9, 12},
+		{11, 13.1, 10.9, 13}, {12, 14.1, 11.9, 14}},
+		[]indicator.Pattern{indicator.PatternThreeWhiteSoldiers}},
+	{"TestThreeBlackCrows", [][4]float64{{14, 14.1, 11.9, 12},
+		{13, 13.1, 10.9, 11}, {12, 12.1, 9.9, 10}},
+		[]indicator.Pattern{indicator.PatternThreeBlackCrows}},
+	{"TestNoPattern", [][4]float64{{10, 11, 9, 10.5}}, nil},
+}
+
+// patternThresholds returns the default pattern thresholds with a trend
+// period short enough for the test cases.
+func patternThresholds() indicator.PatternThresholds {
+
+	thresholds := indicator.DefaultPatternThresholds()
+	thresholds.TrendPeriod = 2
+
+	return thresholds
+
+}
+
+// TestPatternStream tests recognizing candlestick patterns.
+func TestPatternStream(t *testing.T) {
+
+	for _, c := range patternCases {
+		t.Run(c.name, func(t *testing.T) {
+
+			ps := indicator.NewPatternStream(input.NewCandleListStream(
+				patternCandles(c.prices...)), patternThresholds())
+			defer ps.Close()
+
+			var patterns []indicator.Pattern
+			for range c.prices {
+				var err error
+				if patterns, err = ps.Next(); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if len(patterns) != len(c.expected) {
+				t.Fatalf("expected %v, got %v", c.expected, patterns)
+			}
+
+			for i := range patterns {
+				if patterns[i] != c.expected[i] {
+					t.Fatalf("expected %v, got %v", c.expected, patterns)
+				}
+			}
+
+			if _, err := ps.Next(); err != stream.ErrEndOfStream {
+				t.Fatalf("expected end of stream error, got %v", err)
+			}
+
+		})
+	}
+
+}
+
+// TestPatternStreamTrend tests that hammers are not recognized before the
+// preceding trend can be measured.
+func TestPatternStreamTrend(t *testing.T) {
+
+	thresholds := patternThresholds()
+	thresholds.TrendPeriod = 3
+
+	ps := indicator.NewPatternStream(input.NewCandleListStream(
+		patternCandles(append(downtrend,
+			[4]float64{11.6, 12.3, 9, 12.2})...)), thresholds)
+	defer ps.Close()
+
+	for i := range downtrend {
+		if _, err := ps.Next(); err != nil {
+			t.Fatalf("index %d; err: %v", i, err)
+		}
+	}
+
+	patterns, err := ps.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(patterns) != 0 {
+		t.Fatalf("expected no patterns, got %v", patterns)
+	}
+
+}
+
+// TestPatternSignalStream tests the signal output for bullish, bearish and
+// indecisive patterns.
+func TestPatternSignalStream(t *testing.T) {
+
+	for _, c := range patternCases {
+		t.Run(c.name, func(t *testing.T) {
+
+			var expected float64
+			for _, pattern := range c.expected {
+				expected += float64(pattern.Signal())
+			}
+
+			ps := indicator.NewPatternSignalStream(input.NewCandleListStream(
+				patternCandles(c.prices...)), patternThresholds())
+			defer ps.Close()
+
+			if ps.WarmUp() != 3 {
+				t.Fatalf("expected warm-up 3, got %d", ps.WarmUp())
+			}
+
+			var signal float64
+			for range c.prices {
+				var err error
+				if signal, err = ps.Next(); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if signal != expected {
+				t.Fatalf("expected %v, got %v", expected, signal)
+			}
+
+		})
+	}
+
+}
+
+// TestPatternStreamThresholds tests that invalid thresholds return an error.
+func TestPatternStreamThresholds(t *testing.T) {
+
+	thresholds := indicator.DefaultPatternThresholds()
+	thresholds.LongWick = -1
+
+	ps := indicator.NewPatternStream(input.NewCandleListStream(
+		patternCandles([4]float64{1, 1, 1, 1})), thresholds)
+	defer ps.Close()
+
+	if _, err := ps.Next(); err == nil {
+		t.Fatal("expected error for negative threshold")
+	}
+
+}
@@ -0,0 +1,286 @@
+package indicator
+
+import (
+	"errors"
+	gomath "math"
+	"time"
+
+	"github.com/bsladewski/lapis/stream"
+)
+
+// PivotMethod identifies a method of calculating pivot points.
+type PivotMethod int
+
+const (
+	// PivotClassic calculates floor trader pivot points.
+	PivotClassic PivotMethod = iota
+	// PivotFibonacci calculates support and resistance levels at Fibonacci
+	// ratios of the previous session's range from the classic pivot.
+	PivotFibonacci
+	// PivotCamarilla calculates four levels of support and resistance at
+	// fractions of the previous session's range from its close.
+	PivotCamarilla
+	// PivotWoodie calculates floor trader pivot points from a pivot weighted
+	// towards the previous session's close.
+	PivotWoodie
+)
+
+const (
+	// PivotPoint names the pivot component of a pivot stream.
+	PivotPoint = "pivot"
+	// PivotR1 names the first resistance component of a pivot stream.
+	PivotR1 = "r1"
+	// PivotR2 names the second resistance component of a pivot stream.
+	PivotR2 = "r2"
+	// PivotR3 names the third resistance component of a pivot stream.
+	PivotR3 = "r3"
+	// PivotR4 names the fourth resistance component of a Camarilla pivot
+	// stream.
+	PivotR4 = "r4"
+	// PivotS1 names the first support component of a pivot stream.
+	PivotS1 = "s1"
+	// PivotS2 names the second support component of a pivot stream.
+	PivotS2 = "s2"
+	// PivotS3 names the third support component of a pivot stream.
+	PivotS3 = "s3"
+	// PivotS4 names the fourth support component of a Camarilla pivot stream.
+	PivotS4 = "s4"
+)
+
+// components returns the names of the levels calculated by a pivot method.
+func (m PivotMethod) components() []string {
+
+	if m == PivotCamarilla {
+		return []string{PivotPoint, PivotR1, PivotR2, PivotR3, PivotR4,
+			PivotS1, PivotS2, PivotS3, PivotS4}
+	}
+
+	return []string{PivotPoint, PivotR1, PivotR2, PivotR3, PivotS1, PivotS2,
+		PivotS3}
+
+}
+
+// levels returns the levels calculated by a pivot method from the high, low
+// and close of a session, ordered by component.
+func (m PivotMethod) levels(high, low, close float64) []float64 {
+
+	span := high - low
+	pivot := (high + low + close) / 3.0
+
+	switch m {
+
+	case PivotFibonacci:
+		return []float64{
+			pivot,
+			pivot + 0.382*span, pivot + 0.618*span, pivot + span,
+			pivot - 0.382*span, pivot - 0.618*span, pivot - span,
+		}
+
+	case PivotCamarilla:
+		return []float64{
+			pivot,
+			close + span*1.1/12.0, close + span*1.1/6.0,
+			close + span*1.1/4.0, close + span*1.1/2.0,
+			close - span*1.1/12.0, close - span*1.1/6.0,
+			close - span*1.1/4.0, close - span*1.1/2.0,
+		}
+
+	case PivotWoodie:
+		pivot = (high + low + 2.0*close) / 4.0
+
+	}
+
+	return []float64{
+		pivot,
+		2.0*pivot - low, pivot + span, high + 2.0*(pivot-low),
+		2.0*pivot - high, pivot - span, low - 2.0*(high-pivot),
+	}
+
+}
+
+// pivotState holds the prices of the session in progress along with the pivot
+// levels calculated from the previous session.
+type pivotState struct {
+	session time.Duration
+	method  PivotMethod
+	start   time.Time
+	started bool
+	high    float64
+	low     float64
+	close   float64
+	levels  []float64
+}
+
+// validate returns an error if the pivot configuration is invalid.
+func (p *pivotState) validate() error {
+
+	if p.session <= 0 {
+		return errors.New("pivot session cannot be negative or zero")
+	}
+
+	if p.method < PivotClassic || p.method > PivotWoodie {
+		return errors.New("invalid pivot method")
+	}
+
+	return nil
+
+}
+
+// update adds a candle to the session in progress and returns whether the
+// candle started a new session, calculating the levels for the new session
+// from the session that ended.
+func (p *pivotState) update(candle stream.Candle) bool {
+
+	start := candle.Timestamp.Truncate(p.session)
+	changed := p.started && !start.Equal(p.start)
+
+	if changed {
+		p.levels = p.method.levels(p.high, p.low, p.close)
+	}
+
+	if !p.started || changed {
+		p.start = start
+		p.high, p.low = candle.High, candle.Low
+		p.started = true
+	}
+
+	p.high = gomath.Max(p.high, candle.High)
+	p.low = gomath.Min(p.low, candle.Low)
+	p.close = candle.Close
+
+	return changed
+
+}
+
+// pivot is the concrete implementation of a multi stream that outputs the
+// pivot levels that apply to each input candle.
+type pivot struct {
+	state pivotState
+	in    stream.CandleStream
+}
+
+// NewPivotStream returns a multi stream that outputs the pivot point, support
+// and resistance levels that apply to each input candle, calculated by the
+// specified method from the high, low and close of the previous session.
+// Sessions are consecutive intervals of the specified length measured from the
+// zero time, so a session of 24 hours begins at midnight UTC; levels are NaN
+// during the first session.
+func NewPivotStream(in stream.CandleStream, session time.Duration,
+	method PivotMethod) MultiIndicator {
+
+	return &pivot{
+		state: pivotState{session: session, method: method},
+		in:    in,
+	}
+
+}
+
+func (p *pivot) Components() []string {
+	return p.state.method.components()
+}
+
+func (p *pivot) Next() ([]float64, error) {
+
+	if err := p.state.validate(); err != nil {
+		return nil, err
+	}
+
+	// retrieve the next candle
+	candle, err := p.in.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	p.state.update(candle)
+
+	if p.state.levels == nil {
+		levels := make([]float64, len(p.Components()))
+		for i := range levels {
+			levels[i] = gomath.NaN()
+		}
+		return levels, nil
+	}
+
+	return append([]float64{}, p.state.levels...), nil
+
+}
+
+func (p *pivot) Close() {
+	p.in.Close()
+}
+
+func (p *pivot) WarmUp() int {
+
+	// the number of candles in the first session is not known in advance
+	return 0
+
+}
+
+// pivotLevels is the concrete implementation of a level stream that outputs
+// the pivot levels of each session.
+type pivotLevels struct {
+	state   pivotState
+	active  []stream.Level
+	pending pendingLevels
+	in      stream.CandleStream
+}
+
+// NewPivotLevelStream returns a level stream that outputs the pivot point,
+// support and resistance levels of each session, calculated as described by
+// NewPivotStream. At the start of each session after the first the levels of
+// the previous session are withdrawn and the new levels are output, each kind
+// being the name of the level's component and timestamped with the start of
+// the session.
+func NewPivotLevelStream(in stream.CandleStream, session time.Duration,
+	method PivotMethod) stream.LevelStream {
+
+	return &pivotLevels{
+		state: pivotState{session: session, method: method},
+		in:    in,
+	}
+
+}
+
+func (p *pivotLevels) Next() (stream.Level, error) {
+
+	if err := p.state.validate(); err != nil {
+		return stream.Level{}, err
+	}
+
+	// read candles until the levels change
+	for len(p.pending) == 0 {
+
+		candle, err := p.in.Next()
+		if err != nil {
+			return stream.Level{}, err
+		}
+
+		if !p.state.update(candle) {
+			continue
+		}
+
+		for _, level := range p.active {
+			p.pending.withdraw(level)
+		}
+
+		p.active = p.active[:0]
+		for i, kind := range p.state.method.components() {
+			level := stream.Level{
+				Timestamp: p.state.start,
+				Kind:      kind,
+				Price:     p.state.levels[i],
+				Active:    true,
+			}
+			p.active = append(p.active, level)
+			p.pending.push(level)
+		}
+
+	}
+
+	return p.pending.pop(), nil
+
+}
+
+func (p *pivotLevels) Close() {
+	p.in.Close()
+}
@@ -0,0 +1,188 @@
+package indicator_test
+
+import (
+	gomath "math"
+	"testing"
+	"time"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// pivotCandles returns candles spanning three daily sessions; the first
+// session has a high of 110, a low of 90 and a close of 105 and the second a
+// high of 120, a low of 100 and a close of 115.
+func pivotCandles() []stream.Candle {
+
+	candles := patternCandles(
+		[4]float64{100, 104, 90, 101}, [4]float64{101, 110, 99, 105},
+		[4]float64{105, 106, 104, 105}, [4]float64{105, 120, 100, 115},
+		[4]float64{115, 116, 114, 115})
+
+	// two candles per session
+	for i := range candles {
+		candles[i].Timestamp = candles[0].Timestamp.Add(
+			time.Duration(i) * 12 * time.Hour)
+	}
+
+	return candles
+
+}
+
+// TestPivotStream tests calculating pivot levels by each method.
+func TestPivotStream(t *testing.T) {
+
+	cases := []struct {
+		name       string
+		method     indicator.PivotMethod
+		components []string
+		second     []float64
+		third      []float64
+	}{
+		{"TestClassic", indicator.PivotClassic,
+			[]string{"pivot", "r1", "r2", "r3", "s1", "s2", "s3"},
+			[]float64{101.666666667, 113.333333333, 121.666666667,
+				133.333333333, 93.333333333, 81.666666667, 73.333333333},
+			[]float64{111.666666667, 123.333333333, 131.666666667,
+				143.333333333, 103.333333333, 91.666666667, 83.333333333}},
+		{"TestFibonacci", indicator.PivotFibonacci,
+			[]string{"pivot", "r1", "r2", "r3", "s1", "s2", "s3"},
+			[]float64{101.666666667, 109.306666667, 114.026666667,
+				121.666666667, 94.026666667, 89.306666667, 81.666666667},
+			nil},
+		{"TestCamarilla", indicator.PivotCamarilla,
+			[]string{"pivot", "r1", "r2", "r3", "r4", "s1", "s2", "s3",
+				"s4"},
+			[]float64{101.666666667, 106.833333333, 108.666666667, 110.5,
+				116, 103.166666667, 101.333333333, 99.5, 94},
+			nil},
+		{"TestWoodie", indicator.PivotWoodie,
+			[]string{"pivot", "r1", "r2", "r3", "s1", "s2", "s3"},
+			[]float64{102.5, 115, 122.5, 135, 95, 82.5, 75},
+			nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			// only the classic case reads the third session
+			candles := pivotCandles()
+			if c.third == nil {
+				candles = candles[:4]
+			}
+
+			ps := indicator.NewPivotStream(input.NewCandleListStream(
+				candles), 24*time.Hour, c.method)
+			defer ps.Close()
+
+			components := ps.Components()
+			if len(components) != len(c.components) {
+				t.Fatalf("expected components %v, got %v", c.components,
+					components)
+			}
+			for i := range components {
+				if components[i] != c.components[i] {
+					t.Fatalf("expected components %v, got %v", c.components,
+						components)
+				}
+			}
+
+			// levels are not known during the first session
+			for i := 0; i < 2; i++ {
+				values, err := ps.Next()
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, value := range values {
+					if !gomath.IsNaN(value) {
+						t.Fatalf("index %d; expected NaN, got %v", i, values)
+					}
+				}
+			}
+
+			if c.third == nil {
+				assertMultiStreamOutput(t, ps,
+					[][]float64{c.second, c.second})
+				return
+			}
+
+			assertMultiStreamOutput(t, ps,
+				[][]float64{c.second, c.second, c.third})
+
+		})
+	}
+
+}
+
+// TestPivotLevelStream tests that the levels of each session replace the
+// levels of the previous session.
+func TestPivotLevelStream(t *testing.T) {
+
+	candles := pivotCandles()
+
+	ps := indicator.NewPivotLevelStream(input.NewCandleListStream(candles),
+		24*time.Hour, indicator.PivotClassic)
+	defer ps.Close()
+
+	var levels []stream.Level
+	for {
+		level, err := ps.Next()
+		if err == stream.ErrEndOfStream {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		levels = append(levels, level)
+	}
+
+	// seven levels for the second session, then seven withdrawals and seven
+	// levels for the third
+	if len(levels) != 21 {
+		t.Fatalf("expected 21 levels, got %d", len(levels))
+	}
+
+	for i, level := range levels {
+
+		expectedActive := i < 7 || i >= 14
+		if level.Active != expectedActive {
+			t.Fatalf("index %d; expected active %v, got %v", i,
+				expectedActive, level.Active)
+		}
+
+		expectedStart := candles[2].Timestamp
+		if i >= 14 {
+			expectedStart = candles[4].Timestamp
+		}
+
+		if !level.Timestamp.Equal(expectedStart) {
+			t.Fatalf("index %d; expected timestamp %v, got %v", i,
+				expectedStart, level.Timestamp)
+		}
+
+	}
+
+	// withdrawals match the levels they withdraw
+	for i := 7; i < 14; i++ {
+		if levels[i].Kind != levels[i-7].Kind ||
+			levels[i].Price != levels[i-7].Price {
+			t.Fatalf("index %d; withdrew %v, expected %v", i, levels[i],
+				levels[i-7])
+		}
+	}
+
+}
+
+// TestPivotStreamSession tests that a pivot stream with an invalid session
+// returns an error.
+func TestPivotStreamSession(t *testing.T) {
+
+	ps := indicator.NewPivotStream(input.NewCandleListStream(pivotCandles()),
+		0, indicator.PivotClassic)
+	defer ps.Close()
+
+	if _, err := ps.Next(); err == nil {
+		t.Fatal("expected error for zero session")
+	}
+
+}
@@ -0,0 +1,174 @@
+package indicator
+
+import (
+	"errors"
+	gomath "math"
+
+	"github.com/bsladewski/lapis/stream"
+)
+
+// rsiState holds the state of a Relative Strength Index calculation so that it
+// can be shared by streams derived from the index.
+type rsiState struct {
+	gain     *emaState
+	loss     *emaState
+	previous float64
+	started  bool
+}
+
+// newRSIState returns the initial state of a Relative Strength Index; gains
+// and losses are averaged using Wilder's smoothing.
+func newRSIState(period int) *rsiState {
+
+	return &rsiState{
+		gain: newEMAState(period, 1.0/float64(period), SeedSMA),
+		loss: newEMAState(period, 1.0/float64(period), SeedSMA),
+	}
+
+}
+
+// update adds a value to the Relative Strength Index and returns the updated
+// index; the index is 50 until a change in value has been observed.
+func (r *rsiState) update(value float64) float64 {
+
+	if !r.started {
+		r.started = true
+		r.previous = value
+		return 50.0
+	}
+
+	change := value - r.previous
+	r.previous = value
+
+	gain := r.gain.update(gomath.Max(change, 0.0))
+	loss := r.loss.update(gomath.Max(-change, 0.0))
+
+	if gain+loss == 0.0 {
+		return 50.0
+	}
+
+	return 100.0 * gain / (gain + loss)
+
+}
+
+// rsi is the concrete implementation of a stream that applies a Relative
+// Strength Index function to input data.
+type rsi struct {
+	period int
+	state  *rsiState
+	in     stream.Stream
+}
+
+// NewRSIStream returns a stream that applies Wilder's Relative Strength Index
+// function to input data.
+func NewRSIStream(in stream.Stream, period int) Indicator {
+
+	return &rsi{
+		period: period,
+		state:  newRSIState(period),
+		in:     in,
+	}
+
+}
+
+func (r *rsi) Next() (float64, error) {
+
+	if r.period <= 0 {
+		return 0.0, errors.New(
+			"relative strength index period cannot be negative or zero")
+	}
+
+	// retrieve the next piece of input data
+	next, err := r.in.Next()
+	if err != nil && err != stream.ErrNotReady {
+		return 0.0, err
+	}
+
+	// values flagged as not ready, or marked as not ready with NaN, are
+	// passed through unchanged rather than poisoning the averages
+	if err == stream.ErrNotReady || gomath.IsNaN(next) {
+		return next, err
+	}
+
+	return r.state.update(next), nil
+
+}
+
+func (r *rsi) Close() {
+	r.in.Close()
+}
+
+func (r *rsi) WarmUp() int {
+
+	// the first value has no change so period changes require an additional
+	// value
+	return r.period + inputWarmUp(r.in)
+
+}
+
+// stochRSI is the concrete implementation of a stream that applies a
+// Stochastic Relative Strength Index function to input data.
+type stochRSI struct {
+	rsiPeriod   int
+	stochPeriod int
+	state       *rsiState
+	window      *extrema
+	in          stream.Stream
+}
+
+// NewStochRSIStream returns a stream that applies a Stochastic Relative
+// Strength Index function to input data; the output is the position of the
+// relative strength index within its range over the stochastic period scaled
+// from 0 to 1, or 0.5 if the index has not moved over the period.
+func NewStochRSIStream(in stream.Stream, rsiPeriod,
+	stochPeriod int) Indicator {
+
+	return &stochRSI{
+		rsiPeriod:   rsiPeriod,
+		stochPeriod: stochPeriod,
+		state:       newRSIState(rsiPeriod),
+		window:      newExtrema(stochPeriod),
+		in:          in,
+	}
+
+}
+
+func (s *stochRSI) Next() (float64, error) {
+
+	if s.rsiPeriod <= 0 || s.stochPeriod <= 0 {
+		return 0.0, errors.New(
+			"stochastic relative strength index periods cannot be negative " +
+				"or zero")
+	}
+
+	// retrieve the next piece of input data
+	next, err := s.in.Next()
+	if err != nil && err != stream.ErrNotReady {
+		return 0.0, err
+	}
+
+	// values flagged as not ready, or marked as not ready with NaN, are
+	// passed through unchanged rather than poisoning the averages
+	if err == stream.ErrNotReady || gomath.IsNaN(next) {
+		return next, err
+	}
+
+	value := s.state.update(next)
+	s.window.update(value)
+
+	lowest, highest := s.window.min(), s.window.max()
+	if highest == lowest {
+		return 0.5, nil
+	}
+
+	return (value - lowest) / (highest - lowest), nil
+
+}
+
+func (s *stochRSI) Close() {
+	s.in.Close()
+}
+
+func (s *stochRSI) WarmUp() int {
+	return s.rsiPeriod + s.stochPeriod - 1 + inputWarmUp(s.in)
+}
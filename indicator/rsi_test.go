@@ -0,0 +1,84 @@
+package indicator_test
+
+import (
+	"testing"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// TestRSIStream tests calculations performed by a Relative Strength Index
+// stream.
+func TestRSIStream(t *testing.T) {
+
+	// define input data
+	inputData := []float64{3.0, 4.0, 2.0, 6.0, 4.0, 5.0, 0.0, 1.0}
+
+	// define expected output
+	expectedOutput := []float64{50.0, 100.0, 33.333333333333336,
+		71.42857142857143, 50.0, 59.183673469387756, 24.892703862660944,
+		36.014625228519186}
+
+	rs := indicator.NewRSIStream(input.NewListStream(inputData), 3)
+	defer rs.Close()
+
+	if rs.WarmUp() != 3 {
+		t.Fatalf("expected warm-up 3, got %d", rs.WarmUp())
+	}
+
+	assertStreamOutput(t, rs, expectedOutput)
+
+	// assert that the index is bounded over mock data
+	rs = indicator.NewRSIStream(input.NewListStream(loadMockData(t)), 14)
+	defer rs.Close()
+
+	assertStreamBounds(t, rs, 0.0, 100.0)
+
+}
+
+// TestStochRSIStream tests calculations performed by a Stochastic Relative
+// Strength Index stream.
+func TestStochRSIStream(t *testing.T) {
+
+	// define input data
+	inputData := []float64{3.0, 4.0, 2.0, 6.0, 4.0, 5.0, 0.0, 1.0}
+
+	// define expected output
+	expectedOutput := []float64{0.5, 1.0, 0.0, 0.5714285714285715,
+		0.43749999999999994, 0.42857142857142855, 0.0, 0.3243396583244024}
+
+	ss := indicator.NewStochRSIStream(input.NewListStream(inputData), 3, 3)
+	defer ss.Close()
+
+	if ss.WarmUp() != 5 {
+		t.Fatalf("expected warm-up 5, got %d", ss.WarmUp())
+	}
+
+	assertStreamOutput(t, ss, expectedOutput)
+
+}
+
+// assertStreamBounds asserts that every value output by a stream lies within
+// the specified bounds.
+func assertStreamBounds(t *testing.T, s stream.Stream, lower, upper float64) {
+
+	t.Helper()
+
+	for i := 0; ; i++ {
+
+		streamValue, err := s.Next()
+		if err == stream.ErrEndOfStream {
+			break
+		} else if err != nil {
+			t.Fatalf("index %d; err: %v", i, err)
+		}
+
+		if streamValue < lower || streamValue > upper {
+			t.Fatalf("index %d; expected value within [%.2f, %.2f], got %.2f",
+				i, lower, upper, streamValue)
+		}
+
+	}
+
+}
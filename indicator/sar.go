@@ -0,0 +1,150 @@
+package indicator
+
+import (
+	"errors"
+	gomath "math"
+
+	"github.com/bsladewski/lapis/stream"
+)
+
+const (
+	// SARValue names the stop and reverse component of a Parabolic SAR stream.
+	SARValue = "sar"
+	// TrendDirection names the direction component of a trend following
+	// stream; the direction is 1 for a rising trend and -1 for a falling
+	// trend.
+	TrendDirection = "direction"
+)
+
+// sar is the concrete implementation of a multi stream that applies Wilder's
+// Parabolic Stop and Reverse function to input candles.
+//
+// The stream is a state machine with a rising and a falling state. While
+// rising, the stop trails below price and accelerates towards the extreme
+// point, the highest high of the trend, by the acceleration factor; the factor
+// grows by step, up to max, each time a new extreme point is set. The stop may
+// never rise above the lows of the current or previous candle. When a candle's
+// low reaches the stop the trend reverses: the stop is placed at the extreme
+// point of the previous trend, no lower than the highs of the current and
+// previous candles, the extreme point becomes the candle's low and the factor
+// is reset to step. The falling state mirrors the rising state. The first
+// candle starts a rising trend with the stop at its low.
+type sar struct {
+	step         float64
+	max          float64
+	rising       bool
+	stop         float64
+	extreme      float64
+	acceleration float64
+	previous     stream.Candle
+	started      bool
+	in           stream.CandleStream
+}
+
+// NewParabolicSARStream returns a multi stream that applies Wilder's Parabolic
+// Stop and Reverse function to input candles, outputting the stop for each
+// candle and the direction of the trend; Wilder's recommended step and maximum
+// acceleration factor are 0.02 and 0.2.
+func NewParabolicSARStream(in stream.CandleStream, step,
+	max float64) MultiIndicator {
+
+	return &sar{
+		step: step,
+		max:  max,
+		in:   in,
+	}
+
+}
+
+func (s *sar) Components() []string {
+	return []string{SARValue, TrendDirection}
+}
+
+func (s *sar) Next() ([]float64, error) {
+
+	if s.step <= 0.0 || s.max < s.step {
+		return nil, errors.New("parabolic sar step must be greater than " +
+			"zero and no greater than the maximum acceleration")
+	}
+
+	// retrieve the next candle
+	candle, err := s.in.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	// the first candle starts a rising trend
+	if !s.started {
+		s.started = true
+		s.rising = true
+		s.stop = candle.Low
+		s.extreme = candle.High
+		s.acceleration = s.step
+		s.previous = candle
+		return []float64{s.stop, 1.0}, nil
+	}
+
+	if s.rising {
+
+		if candle.Low <= s.stop {
+			// reverse into a falling trend
+			s.rising = false
+			s.stop = gomath.Max(s.extreme,
+				gomath.Max(candle.High, s.previous.High))
+			s.extreme = candle.Low
+			s.acceleration = s.step
+		} else if candle.High > s.extreme {
+			// a new extreme point accelerates the stop
+			s.extreme = candle.High
+			s.acceleration = gomath.Min(s.acceleration+s.step, s.max)
+		}
+
+	} else {
+
+		if candle.High >= s.stop {
+			// reverse into a rising trend
+			s.rising = true
+			s.stop = gomath.Min(s.extreme,
+				gomath.Min(candle.Low, s.previous.Low))
+			s.extreme = candle.High
+			s.acceleration = s.step
+		} else if candle.Low < s.extreme {
+			// a new extreme point accelerates the stop
+			s.extreme = candle.Low
+			s.acceleration = gomath.Min(s.acceleration+s.step, s.max)
+		}
+
+	}
+
+	output := s.stop
+
+	// move the stop towards the extreme point for the next candle without
+	// crossing the range of the current or previous candle
+	s.stop += s.acceleration * (s.extreme - s.stop)
+	if s.rising {
+		s.stop = gomath.Min(s.stop, gomath.Min(candle.Low, s.previous.Low))
+	} else {
+		s.stop = gomath.Max(s.stop, gomath.Max(candle.High, s.previous.High))
+	}
+
+	s.previous = candle
+
+	direction := -1.0
+	if s.rising {
+		direction = 1.0
+	}
+
+	return []float64{output, direction}, nil
+
+}
+
+func (s *sar) Close() {
+	s.in.Close()
+}
+
+func (s *sar) WarmUp() int {
+
+	// the direction of the first candle is assumed
+	return 1
+
+}
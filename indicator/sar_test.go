@@ -0,0 +1,45 @@
+package indicator_test
+
+import (
+	"testing"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+)
+
+// TestParabolicSARStream tests the transitions of a Parabolic SAR stream
+// between rising and falling trends.
+func TestParabolicSARStream(t *testing.T) {
+
+	// define expected output; each new high accelerates the stop until the
+	// fifth candle's low penetrates it, at which point the stop is placed at
+	// the prior extreme high of 13 and the trend falls until the final candle
+	// penetrates the stop and the stop is placed at the extreme low of 8
+	expectedOutput := [][]float64{
+		{9.0, 1.0},
+		{9.0, 1.0},
+		{9.0, 1.0},
+		{9.18, 1.0},
+		{9.4856, 1.0},
+		{13.0, -1.0},
+		{12.92, -1.0},
+		{12.7232, -1.0},
+		{8.0, 1.0},
+	}
+
+	ss := indicator.NewParabolicSARStream(
+		input.NewCandleListStream(trendCandles), 0.02, 0.2)
+	defer ss.Close()
+
+	assertMultiStreamOutput(t, ss, expectedOutput)
+
+	// assert that an invalid acceleration results in an error
+	ss = indicator.NewParabolicSARStream(
+		input.NewCandleListStream(trendCandles), 0.2, 0.02)
+	defer ss.Close()
+
+	if _, err := ss.Next(); err == nil {
+		t.Fatal("expected invalid acceleration error")
+	}
+
+}
@@ -0,0 +1,114 @@
+package indicator
+
+import (
+	"errors"
+
+	"github.com/bsladewski/lapis/stream"
+)
+
+// SupertrendValue names the trailing stop component of a Supertrend stream.
+const SupertrendValue = "supertrend"
+
+// supertrend is the concrete implementation of a multi stream that applies a
+// Supertrend function to input candles.
+//
+// The stream is a state machine with a rising and a falling state. Bands are
+// placed a multiple of the average true range above and below the midpoint of
+// each candle; the lower band may only rise and the upper band may only fall
+// unless the previous close crossed the band, in which case the band resets to
+// its basic value. While rising the lower band is output as the trailing stop
+// and the trend reverses when a close falls below it; while falling the upper
+// band is output and the trend reverses when a close rises above it. The first
+// candle starts a rising trend.
+type supertrend struct {
+	period     int
+	multiplier float64
+	trueRange  trueRangeState
+	atr        *emaState
+	upper      float64
+	lower      float64
+	rising     bool
+	previous   float64
+	started    bool
+	in         stream.CandleStream
+}
+
+// NewSupertrendStream returns a multi stream that applies a Supertrend function
+// to input candles, outputting the trailing stop for each candle and the
+// direction of the trend.
+func NewSupertrendStream(in stream.CandleStream, period int,
+	multiplier float64) MultiIndicator {
+
+	return &supertrend{
+		period:     period,
+		multiplier: multiplier,
+		atr:        newEMAState(period, 1.0/float64(period), SeedSMA),
+		in:         in,
+	}
+
+}
+
+func (s *supertrend) Components() []string {
+	return []string{SupertrendValue, TrendDirection}
+}
+
+func (s *supertrend) Next() ([]float64, error) {
+
+	if s.period <= 0 {
+		return nil, errors.New("supertrend period cannot be negative or zero")
+	}
+
+	// retrieve the next candle
+	candle, err := s.in.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	midpoint := (candle.High + candle.Low) / 2.0
+	offset := s.multiplier * s.atr.update(s.trueRange.update(candle))
+	upper, lower := midpoint+offset, midpoint-offset
+
+	if !s.started {
+
+		// the first candle starts a rising trend
+		s.started = true
+		s.rising = true
+
+	} else {
+
+		// the bands may only tighten unless the previous close crossed them
+		if upper > s.upper && s.previous <= s.upper {
+			upper = s.upper
+		}
+
+		if lower < s.lower && s.previous >= s.lower {
+			lower = s.lower
+		}
+
+		// reverse the trend if the close crosses the active band
+		if s.rising && candle.Close < lower {
+			s.rising = false
+		} else if !s.rising && candle.Close > upper {
+			s.rising = true
+		}
+
+	}
+
+	s.upper, s.lower = upper, lower
+	s.previous = candle.Close
+
+	if s.rising {
+		return []float64{lower, 1.0}, nil
+	}
+
+	return []float64{upper, -1.0}, nil
+
+}
+
+func (s *supertrend) Close() {
+	s.in.Close()
+}
+
+func (s *supertrend) WarmUp() int {
+	return s.period - 1
+}
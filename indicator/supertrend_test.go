@@ -0,0 +1,35 @@
+package indicator_test
+
+import (
+	"testing"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+)
+
+// TestSupertrendStream tests the transitions of a Supertrend stream between
+// rising and falling trends.
+func TestSupertrendStream(t *testing.T) {
+
+	// define expected output; the lower band trails the rising trend until the
+	// fifth candle closes below it, the upper band then trails the falling
+	// trend until the eighth candle closes above it
+	expectedOutput := [][]float64{
+		{8.5, 1.0},
+		{9.25, 1.0},
+		{10.275, 1.0},
+		{11.3375, 1.0},
+		{13.23125, -1.0},
+		{11.990625, -1.0},
+		{10.9953125, -1.0},
+		{8.009375, 1.0},
+		{9.726171875, 1.0},
+	}
+
+	ss := indicator.NewSupertrendStream(
+		input.NewCandleListStream(trendCandles), 2, 1.0)
+	defer ss.Close()
+
+	assertMultiStreamOutput(t, ss, expectedOutput)
+
+}
@@ -0,0 +1,287 @@
+package indicator
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bsladewski/lapis/stream"
+)
+
+const (
+	// LevelSwingHigh is the kind of level output for a swing high.
+	LevelSwingHigh = "swing_high"
+	// LevelSwingLow is the kind of level output for a swing low.
+	LevelSwingLow = "swing_low"
+	// LevelResistance is the kind of level output for a resistance level.
+	LevelResistance = "resistance"
+	// LevelSupport is the kind of level output for a support level.
+	LevelSupport = "support"
+)
+
+// zigzag is the concrete implementation of a level stream that outputs the
+// swing highs and lows of input candles.
+type zigzag struct {
+	percent    float64
+	multiplier float64
+	period     int
+	trueRange  trueRangeState
+	average    *emaState
+	direction  int
+	high       float64
+	highTime   time.Time
+	low        float64
+	lowTime    time.Time
+	started    bool
+	swingHigh  *stream.Level
+	swingLow   *stream.Level
+	pending    pendingLevels
+	in         stream.CandleStream
+}
+
+// NewZigZagStream returns a level stream that outputs the swing highs and lows
+// of input candles; a swing high is confirmed once the price falls the
+// specified percentage below the highest high since the last swing low, and a
+// swing low once the price rises the specified percentage above the lowest low
+// since the last swing high. Swings are timestamped with the candle that made
+// the high or low and the previous swing of the same kind is withdrawn when a
+// new swing is confirmed, so the active levels are the latest swing high and
+// swing low; the swing in progress at the end of input is not output.
+func NewZigZagStream(in stream.CandleStream,
+	percent float64) stream.LevelStream {
+
+	return &zigzag{
+		percent: percent,
+		in:      in,
+	}
+
+}
+
+// NewATRZigZagStream returns a level stream that outputs swing highs and lows
+// as described by NewZigZagStream, where a swing is confirmed once the price
+// reverses by a multiple of Wilder's Average True Range over the specified
+// period at the reversing candle.
+func NewATRZigZagStream(in stream.CandleStream, period int,
+	multiplier float64) stream.LevelStream {
+
+	return &zigzag{
+		multiplier: multiplier,
+		period:     period,
+		average:    newEMAState(period, 1.0/float64(period), SeedSMA),
+		in:         in,
+	}
+
+}
+
+func (z *zigzag) Next() (stream.Level, error) {
+
+	if z.average == nil && z.percent <= 0 {
+		return stream.Level{},
+			errors.New("zigzag percentage cannot be negative or zero")
+	}
+
+	if z.average != nil && (z.period <= 0 || z.multiplier <= 0) {
+		return stream.Level{}, errors.New(
+			"zigzag period and multiplier cannot be negative or zero")
+	}
+
+	// read candles until a swing has been confirmed
+	for len(z.pending) == 0 {
+
+		candle, err := z.in.Next()
+		if err != nil {
+			return stream.Level{}, err
+		}
+
+		z.update(candle)
+
+	}
+
+	return z.pending.pop(), nil
+
+}
+
+// update tracks the extremes of the swing in progress, confirming a swing when
+// the price has reversed far enough from the extreme.
+func (z *zigzag) update(candle stream.Candle) {
+
+	var atr float64
+	if z.average != nil {
+		atr = z.average.update(z.trueRange.update(candle))
+	}
+
+	// reversal returns the distance the price must reverse from an extreme
+	reversal := func(extreme float64) float64 {
+		if z.average != nil {
+			return z.multiplier * atr
+		}
+		return extreme * z.percent / 100.0
+	}
+
+	if !z.started {
+		z.high, z.highTime = candle.High, candle.Timestamp
+		z.low, z.lowTime = candle.Low, candle.Timestamp
+		z.started = true
+		return
+	}
+
+	// track the highest high while looking for a swing high and the lowest low
+	// while looking for a swing low; until the first swing both are tracked
+	if z.direction >= 0 && candle.High > z.high {
+		z.high, z.highTime = candle.High, candle.Timestamp
+	}
+
+	if z.direction <= 0 && candle.Low < z.low {
+		z.low, z.lowTime = candle.Low, candle.Timestamp
+	}
+
+	if z.direction >= 0 && candle.Low <= z.high-reversal(z.high) {
+		z.confirm(&z.swingHigh, LevelSwingHigh, z.high, z.highTime)
+		z.direction = -1
+		z.low, z.lowTime = candle.Low, candle.Timestamp
+	} else if z.direction <= 0 && candle.High >= z.low+reversal(z.low) {
+		z.confirm(&z.swingLow, LevelSwingLow, z.low, z.lowTime)
+		z.direction = 1
+		z.high, z.highTime = candle.High, candle.Timestamp
+	}
+
+}
+
+// confirm outputs a swing, withdrawing the previous swing of the same kind.
+func (z *zigzag) confirm(previous **stream.Level, kind string, price float64,
+	timestamp time.Time) {
+
+	if *previous != nil {
+		z.pending.withdraw(**previous)
+	}
+
+	swing := stream.Level{
+		Timestamp: timestamp,
+		Kind:      kind,
+		Price:     price,
+		Active:    true,
+	}
+
+	*previous = &swing
+	z.pending.push(swing)
+
+}
+
+func (z *zigzag) Close() {
+	z.in.Close()
+}
+
+// fractal is the concrete implementation of a level stream that outputs
+// support and resistance levels at the fractals of input candles.
+type fractal struct {
+	span    int
+	window  []stream.Candle
+	active  []stream.Level
+	pending pendingLevels
+	in      stream.CandleStream
+}
+
+// NewFractalLevelStream returns a level stream that outputs support and
+// resistance levels at fractals of input candles. A candle whose high is above
+// the highs of the span candles on either side of it is an up fractal and its
+// high becomes a resistance level, a candle whose low is below the lows of the
+// span candles on either side is a down fractal and its low becomes a support
+// level; fractals are confirmed span candles later. Levels are timestamped with
+// the fractal candle and are withdrawn once a candle closes beyond them. The
+// conventional span is 2.
+func NewFractalLevelStream(in stream.CandleStream,
+	span int) stream.LevelStream {
+
+	return &fractal{
+		span: span,
+		in:   in,
+	}
+
+}
+
+func (f *fractal) Next() (stream.Level, error) {
+
+	if f.span <= 0 {
+		return stream.Level{},
+			errors.New("fractal span cannot be negative or zero")
+	}
+
+	// read candles until the levels change
+	for len(f.pending) == 0 {
+
+		candle, err := f.in.Next()
+		if err != nil {
+			return stream.Level{}, err
+		}
+
+		f.update(candle)
+
+	}
+
+	return f.pending.pop(), nil
+
+}
+
+// update withdraws the levels broken by a candle and outputs levels for any
+// fractal confirmed by the candle.
+func (f *fractal) update(candle stream.Candle) {
+
+	active := f.active[:0]
+	for _, level := range f.active {
+		if level.Kind == LevelResistance && candle.Close > level.Price ||
+			level.Kind == LevelSupport && candle.Close < level.Price {
+			f.pending.withdraw(level)
+			continue
+		}
+		active = append(active, level)
+	}
+	f.active = active
+
+	f.window = append(f.window, candle)
+	if len(f.window) > 2*f.span+1 {
+		f.window = f.window[1:]
+	}
+
+	if len(f.window) < 2*f.span+1 {
+		return
+	}
+
+	// the candle in the middle of the window is a fractal if its high or low
+	// is beyond that of every other candle in the window
+	middle := f.window[f.span]
+	up, down := true, true
+	for i, other := range f.window {
+		if i == f.span {
+			continue
+		}
+		up = up && middle.High > other.High
+		down = down && middle.Low < other.Low
+	}
+
+	if up {
+		f.add(LevelResistance, middle.High, middle.Timestamp)
+	}
+
+	if down {
+		f.add(LevelSupport, middle.Low, middle.Timestamp)
+	}
+
+}
+
+// add outputs a new active level.
+func (f *fractal) add(kind string, price float64, timestamp time.Time) {
+
+	level := stream.Level{
+		Timestamp: timestamp,
+		Kind:      kind,
+		Price:     price,
+		Active:    true,
+	}
+
+	f.active = append(f.active, level)
+	f.pending.push(level)
+
+}
+
+func (f *fractal) Close() {
+	f.in.Close()
+}
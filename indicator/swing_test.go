@@ -0,0 +1,186 @@
+package indicator_test
+
+import (
+	"testing"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/output"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// readLevels reads every change to the active levels from a level stream until
+// the end of the stream.
+func readLevels(t *testing.T, s stream.LevelStream) []stream.Level {
+
+	t.Helper()
+
+	var levels []stream.Level
+	for {
+
+		level, err := s.Next()
+		if err == stream.ErrEndOfStream {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		levels = append(levels, level)
+
+	}
+
+	return levels
+
+}
+
+// assertLevels asserts that changes to the active levels match the expected
+// changes, referring to timestamps by the index of their candle.
+func assertLevels(t *testing.T, levels []stream.Level,
+	candles []stream.Candle, expected []struct {
+		kind   string
+		price  float64
+		index  int
+		active bool
+	}) {
+
+	t.Helper()
+
+	if len(levels) != len(expected) {
+		t.Fatalf("expected %d levels, got %d: %v", len(expected), len(levels),
+			levels)
+	}
+
+	for i, e := range expected {
+		level := levels[i]
+		if level.Kind != e.kind || level.Price != e.price ||
+			!level.Timestamp.Equal(candles[e.index].Timestamp) ||
+			level.Active != e.active {
+			t.Fatalf("index %d; expected %v, got %v", i, e, level)
+		}
+	}
+
+}
+
+// TestZigZagStream tests confirming swing highs and lows with a percentage
+// threshold.
+func TestZigZagStream(t *testing.T) {
+
+	candles := patternCandles(
+		[4]float64{100, 101, 99, 100}, [4]float64{100, 106, 100, 105},
+		[4]float64{105, 112, 104, 110}, [4]float64{110, 115, 109, 114},
+		[4]float64{114, 114, 102, 103}, [4]float64{103, 104, 95, 96},
+		[4]float64{96, 106, 96, 105}, [4]float64{105, 107, 104, 106})
+
+	zs := output.NewActiveLevelOutput(indicator.NewZigZagStream(
+		input.NewCandleListStream(candles), 10.0))
+	defer zs.Close()
+
+	assertLevels(t, readLevels(t, zs), candles, []struct {
+		kind   string
+		price  float64
+		index  int
+		active bool
+	}{
+		{indicator.LevelSwingLow, 99, 0, true},
+		{indicator.LevelSwingHigh, 115, 3, true},
+		{indicator.LevelSwingLow, 99, 0, false},
+		{indicator.LevelSwingLow, 95, 5, true},
+	})
+
+	// the latest swing high and low remain active
+	active := zs.GetData()
+	if len(active) != 2 || active[0].Price != 115 || active[1].Price != 95 {
+		t.Fatalf("expected active swings at 115 and 95, got %v", active)
+	}
+
+}
+
+// TestATRZigZagStream tests that swings confirmed with an average true range
+// threshold alternate between highs and lows.
+func TestATRZigZagStream(t *testing.T) {
+
+	zs := indicator.NewATRZigZagStream(input.NewCandleListStream(
+		loadMockCandles(t)), 14, 3.0)
+	defer zs.Close()
+
+	var swings []stream.Level
+	for _, level := range readLevels(t, zs) {
+		if level.Active {
+			swings = append(swings, level)
+		}
+	}
+
+	if len(swings) < 2 {
+		t.Fatalf("expected swings, got %v", swings)
+	}
+
+	for i := 1; i < len(swings); i++ {
+
+		previous, swing := swings[i-1], swings[i]
+
+		if swing.Kind == previous.Kind {
+			t.Fatalf("index %d; consecutive swings of kind %s", i, swing.Kind)
+		}
+
+		// a single wide candle may make both a swing high and a swing low
+		if swing.Timestamp.Before(previous.Timestamp) {
+			t.Fatalf("index %d; swing at %v precedes %v", i,
+				swing.Timestamp, previous.Timestamp)
+		}
+
+		if swing.Kind == indicator.LevelSwingHigh &&
+			swing.Price <= previous.Price ||
+			swing.Kind == indicator.LevelSwingLow &&
+				swing.Price >= previous.Price {
+			t.Fatalf("index %d; swing %v does not reverse %v", i, swing,
+				previous)
+		}
+
+	}
+
+}
+
+// TestFractalLevelStream tests outputting support and resistance levels at
+// fractals and withdrawing them once broken.
+func TestFractalLevelStream(t *testing.T) {
+
+	candles := patternCandles(
+		[4]float64{10, 11, 9, 10}, [4]float64{10, 12, 9.5, 11},
+		[4]float64{11, 15, 10, 14}, [4]float64{14, 14, 8, 9},
+		[4]float64{9, 13, 8.5, 12}, [4]float64{12, 13.5, 11, 13},
+		[4]float64{13, 16, 12.5, 15.5}, [4]float64{15.5, 16, 7, 7.5})
+
+	fs := output.NewActiveLevelOutput(indicator.NewFractalLevelStream(
+		input.NewCandleListStream(candles), 2))
+	defer fs.Close()
+
+	// both levels are active once the first two changes have been read
+	for i := 0; i < 2; i++ {
+		if _, err := fs.Next(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	active := fs.GetData()
+	if len(active) != 2 || active[0].Kind != indicator.LevelResistance ||
+		active[1].Kind != indicator.LevelSupport {
+		t.Fatalf("expected resistance and support, got %v", active)
+	}
+
+	assertLevels(t, append(active, readLevels(t, fs)...), candles, []struct {
+		kind   string
+		price  float64
+		index  int
+		active bool
+	}{
+		{indicator.LevelResistance, 15, 2, true},
+		{indicator.LevelSupport, 8, 3, true},
+		{indicator.LevelResistance, 15, 2, false},
+		{indicator.LevelSupport, 8, 3, false},
+	})
+
+	if data := fs.GetData(); len(data) != 0 {
+		t.Fatalf("expected no active levels, got %v", data)
+	}
+
+}
@@ -0,0 +1,202 @@
+package indicator
+
+import (
+	"errors"
+	gomath "math"
+
+	"github.com/bsladewski/lapis/stream"
+)
+
+const (
+	// ADXValue names the average directional index component of an ADX
+	// stream.
+	ADXValue = "adx"
+	// ADXPlusDI names the positive directional indicator component of an ADX
+	// stream.
+	ADXPlusDI = "plus_di"
+	// ADXMinusDI names the negative directional indicator component of an ADX
+	// stream.
+	ADXMinusDI = "minus_di"
+	// AroonUp names the Aroon up component of an Aroon stream.
+	AroonUp = "up"
+	// AroonDown names the Aroon down component of an Aroon stream.
+	AroonDown = "down"
+	// AroonOscillator names the Aroon oscillator component of an Aroon
+	// stream.
+	AroonOscillator = "oscillator"
+)
+
+// adx is the concrete implementation of a multi stream that applies Wilder's
+// Directional Movement System to input candles.
+type adx struct {
+	period    int
+	trueRange trueRangeState
+	plusDM    *emaState
+	minusDM   *emaState
+	atr       *emaState
+	adx       *emaState
+	previous  stream.Candle
+	started   bool
+	in        stream.CandleStream
+}
+
+// NewADXStream returns a multi stream that applies Wilder's Directional
+// Movement System to input candles; the positive and negative directional
+// indicators measure upward and downward movement relative to the true range
+// and the average directional index is a smoothed measure of the difference
+// between them, indicating the strength of a trend regardless of direction.
+// The first candle has no directional movement so every component is NaN.
+func NewADXStream(in stream.CandleStream, period int) MultiIndicator {
+
+	alpha := 1.0 / float64(period)
+
+	return &adx{
+		period:  period,
+		plusDM:  newEMAState(period, alpha, SeedSMA),
+		minusDM: newEMAState(period, alpha, SeedSMA),
+		atr:     newEMAState(period, alpha, SeedSMA),
+		adx:     newEMAState(period, alpha, SeedSMA),
+		in:      in,
+	}
+
+}
+
+func (a *adx) Components() []string {
+	return []string{ADXValue, ADXPlusDI, ADXMinusDI}
+}
+
+func (a *adx) Next() ([]float64, error) {
+
+	if a.period <= 0 {
+		return nil, errors.New(
+			"average directional index period cannot be negative or zero")
+	}
+
+	// retrieve the next candle
+	candle, err := a.in.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	trueRange := a.trueRange.update(candle)
+
+	// directional movement requires a previous candle, so every component of
+	// the first candle is marked as not ready with NaN
+	if !a.started {
+		a.started = true
+		a.previous = candle
+		nan := gomath.NaN()
+		return []float64{nan, nan, nan}, nil
+	}
+
+	// only the greater of the upward and downward moves counts as directional
+	// movement
+	up := candle.High - a.previous.High
+	down := a.previous.Low - candle.Low
+	a.previous = candle
+
+	plusDM, minusDM := 0.0, 0.0
+	if up > down && up > 0.0 {
+		plusDM = up
+	} else if down > up && down > 0.0 {
+		minusDM = down
+	}
+
+	averageRange := a.atr.update(trueRange)
+	plusDM = a.plusDM.update(plusDM)
+	minusDM = a.minusDM.update(minusDM)
+
+	plusDI, minusDI := 0.0, 0.0
+	if averageRange != 0.0 {
+		plusDI = 100.0 * plusDM / averageRange
+		minusDI = 100.0 * minusDM / averageRange
+	}
+
+	dx := 0.0
+	if plusDI+minusDI != 0.0 {
+		dx = 100.0 * gomath.Abs(plusDI-minusDI) / (plusDI + minusDI)
+	}
+
+	// the index averages the directional movement index only once the
+	// directional indicators are ready; until then it follows the directional
+	// movement index
+	index := dx
+	if a.atr.n >= a.period {
+		index = a.adx.update(dx)
+	}
+
+	return []float64{index, plusDI, minusDI}, nil
+
+}
+
+func (a *adx) Close() {
+	a.in.Close()
+}
+
+func (a *adx) WarmUp() int {
+
+	// the directional indicators are ready after period movements, which
+	// require an additional candle, and the index must then average the
+	// directional movement index of period candles from that candle on
+	return 2*a.period - 1
+
+}
+
+// aroon is the concrete implementation of a multi stream that applies an Aroon
+// function to input candles.
+type aroon struct {
+	period int
+	highs  *extrema
+	lows   *extrema
+	in     stream.CandleStream
+}
+
+// NewAroonStream returns a multi stream that applies an Aroon function to input
+// candles; Aroon up and down measure the number of candles since the highest
+// high and lowest low within the last period candles, scaled from 100 for the
+// current candle to 0 for a candle period candles ago. The oscillator is the
+// difference between up and down.
+func NewAroonStream(in stream.CandleStream, period int) MultiIndicator {
+
+	return &aroon{
+		period: period,
+		highs:  newExtrema(period + 1),
+		lows:   newExtrema(period + 1),
+		in:     in,
+	}
+
+}
+
+func (a *aroon) Components() []string {
+	return []string{AroonUp, AroonDown, AroonOscillator}
+}
+
+func (a *aroon) Next() ([]float64, error) {
+
+	if a.period <= 0 {
+		return nil, errors.New("aroon period cannot be negative or zero")
+	}
+
+	// retrieve the next candle
+	candle, err := a.in.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	a.highs.update(candle.High)
+	a.lows.update(candle.Low)
+
+	up := 100.0 * float64(a.period-a.highs.maxAge()) / float64(a.period)
+	down := 100.0 * float64(a.period-a.lows.minAge()) / float64(a.period)
+
+	return []float64{up, down, up - down}, nil
+
+}
+
+func (a *aroon) Close() {
+	a.in.Close()
+}
+
+func (a *aroon) WarmUp() int {
+	return a.period
+}
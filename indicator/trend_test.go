@@ -0,0 +1,138 @@
+package indicator_test
+
+import (
+	gomath "math"
+	"testing"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// trendCandles is the candle input used to test trend streams; prices rise,
+// fall and then rise again so that trend following streams reverse twice.
+var trendCandles = []stream.Candle{
+	{High: 10.0, Low: 9.0, Close: 9.5},
+	{High: 11.0, Low: 10.0, Close: 10.8},
+	{High: 12.0, Low: 11.0, Close: 11.9},
+	{High: 13.0, Low: 12.0, Close: 12.8},
+	{High: 12.5, Low: 10.0, Close: 10.2},
+	{High: 11.0, Low: 9.0, Close: 9.3},
+	{High: 10.0, Low: 8.0, Close: 8.4},
+	{High: 11.5, Low: 9.5, Close: 11.2},
+	{High: 13.0, Low: 11.0, Close: 12.9},
+}
+
+// TestADXStream tests calculations performed by an Average Directional Index
+// stream.
+func TestADXStream(t *testing.T) {
+
+	// define expected output from the second candle; the index follows the
+	// directional movement index until the directional indicators are ready
+	expectedOutput := [][]float64{
+		{100.0, 66.66666666666667, 0.0},
+		{100.0, 74.07407407407408, 0.0},
+		{100.0, 78.94736842105263, 0.0},
+		{50.0, 37.5, 37.5},
+		{42.42424242424243, 24.0, 42.00000000000001},
+		{44.4118605408928, 15.584415584415584, 44.80519480519481},
+		{33.02254117360333, 30.316580218207832, 24.682525487390453},
+		{36.56326634646552, 43.85363421019823, 17.204837302082037},
+	}
+
+	as := indicator.NewADXStream(input.NewCandleListStream(trendCandles), 3)
+	defer as.Close()
+
+	if as.WarmUp() != 5 {
+		t.Fatalf("expected warm-up 5, got %d", as.WarmUp())
+	}
+
+	// the first candle has no directional movement
+	values, err := as.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, value := range values {
+		if !gomath.IsNaN(value) {
+			t.Fatalf("component %d; expected NaN, got %f", i, value)
+		}
+	}
+
+	assertMultiStreamOutput(t, as, expectedOutput)
+
+}
+
+// TestADXStreamReference tests that an Average Directional Index stream
+// matches reference values once ready; the directional movement and true
+// range are smoothed by Wilder's method from the mean of their first period
+// values and the index from the mean of the first period directional movement
+// indices calculated from ready directional indicators.
+func TestADXStreamReference(t *testing.T) {
+
+	as := indicator.NewADXStream(input.NewCandleListStream(
+		referenceCandles()), 5)
+	defer as.Close()
+
+	for i := 0; i < as.WarmUp(); i++ {
+		if _, err := as.Next(); err != nil {
+			t.Fatalf("index %d; err: %v", i, err)
+		}
+	}
+
+	adx := []float64{
+		62.5544048422, 57.7487329342, 56.1473314579, 57.1650211644,
+		57.2808131558, 49.6986462129, 43.8483805440, 37.9093298128,
+		31.6426909413, 27.2766901285, 32.4360458168, 36.5635303675,
+		43.4549876912, 50.3856534387, 56.2224847966, 54.9101642362,
+		51.7357363966, 51.6465203009, 49.4132069290, 47.6265562315,
+		44.8439099281,
+	}
+	plusDI := []float64{
+		16.5673695683, 14.2691055515, 11.4514752636, 8.9415278550,
+		8.7834198662, 19.0013889541, 16.1846476843, 16.0978372845,
+		19.4151401943, 17.6478943158, 11.2153027474, 10.1407260106,
+		7.4860202629, 5.2604886302, 4.1951997978, 10.2803937568,
+		12.2891592945, 10.0938168881, 41.1199100686, 37.3265182498,
+		34.1710667731,
+	}
+	minusDI := []float64{
+		37.3330819309, 32.1541500259, 34.1190318405, 37.1913640374,
+		32.7889766787, 28.1309039234, 24.5044837205, 21.4057704788,
+		17.0191705664, 14.4939188347, 36.5841079727, 33.0788587386,
+		44.1787920911, 42.7987537126, 36.8734328935, 30.5641588066,
+		28.0282986033, 31.3504269512, 17.4221225293, 15.8148977820,
+		16.9398705192,
+	}
+
+	expectedOutput := make([][]float64, len(adx))
+	for i := range adx {
+		expectedOutput[i] = []float64{adx[i], plusDI[i], minusDI[i]}
+	}
+
+	assertMultiStreamOutput(t, as, expectedOutput)
+
+}
+
+// TestAroonStream tests calculations performed by an Aroon stream.
+func TestAroonStream(t *testing.T) {
+
+	// define expected output
+	expectedOutput := [][]float64{
+		{100.0, 100.0, 0.0},
+		{100.0, 66.66666666666667, 33.33333333333333},
+		{100.0, 33.333333333333336, 66.66666666666666},
+		{100.0, 0.0, 100.0},
+		{66.66666666666667, 100.0, -33.33333333333333},
+		{33.333333333333336, 100.0, -66.66666666666666},
+		{0.0, 100.0, -100.0},
+		{0.0, 66.66666666666667, -66.66666666666667},
+		{100.0, 33.333333333333336, 66.66666666666666},
+	}
+
+	as := indicator.NewAroonStream(input.NewCandleListStream(trendCandles), 3)
+	defer as.Close()
+
+	assertMultiStreamOutput(t, as, expectedOutput)
+
+}
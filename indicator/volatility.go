@@ -0,0 +1,157 @@
+package indicator
+
+import (
+	"errors"
+	gomath "math"
+
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// Common numbers of periods per year used to annualize historical volatility.
+const (
+	// TradingDaysPerYear is the number of daily periods in a year of trading
+	// on a traditional exchange.
+	TradingDaysPerYear = 252.0
+	// CalendarDaysPerYear is the number of daily periods in a year of trading
+	// on an exchange that never closes.
+	CalendarDaysPerYear = 365.0
+	// HoursPerYear is the number of hourly periods in a year of trading on an
+	// exchange that never closes.
+	HoursPerYear = 24.0 * 365.0
+)
+
+// stdDev is the concrete implementation of a stream that outputs the rolling
+// standard deviation of input data.
+type stdDev struct {
+	period int
+	state  *util.WelfordWindow
+	in     stream.Stream
+}
+
+// NewStdDevStream returns a stream that outputs the population standard
+// deviation of input data over the specified period.
+func NewStdDevStream(in stream.Stream, period int) Indicator {
+
+	return &stdDev{
+		period: period,
+		state:  util.NewWelfordWindow(period),
+		in:     in,
+	}
+
+}
+
+func (s *stdDev) Next() (float64, error) {
+
+	if s.period <= 0 {
+		return 0.0, errors.New(
+			"standard deviation period cannot be negative or zero")
+	}
+
+	// retrieve the next piece of input data
+	next, err := s.in.Next()
+	if err != nil && err != stream.ErrNotReady {
+		return 0.0, err
+	}
+
+	// values flagged as not ready, or marked as not ready with NaN, are
+	// passed through unchanged rather than poisoning the running statistics
+	if err == stream.ErrNotReady || gomath.IsNaN(next) {
+		return next, err
+	}
+
+	s.state.Add(next)
+
+	return gomath.Sqrt(s.state.Variance()), nil
+
+}
+
+func (s *stdDev) Close() {
+	s.in.Close()
+}
+
+func (s *stdDev) WarmUp() int {
+	return s.period - 1 + inputWarmUp(s.in)
+}
+
+// historicalVolatility is the concrete implementation of a stream that outputs
+// the annualized volatility of input prices.
+type historicalVolatility struct {
+	period         int
+	periodsPerYear float64
+	state          *util.WelfordWindow
+	previous       float64
+	started        bool
+	in             stream.Stream
+}
+
+// NewHistoricalVolatilityStream returns a stream that outputs the historical
+// volatility of input prices; volatility is the sample standard deviation of
+// the logarithmic returns over the specified period, annualized using the
+// number of periods in a year.
+func NewHistoricalVolatilityStream(in stream.Stream, period int,
+	periodsPerYear float64) Indicator {
+
+	return &historicalVolatility{
+		period:         period,
+		periodsPerYear: periodsPerYear,
+		state:          util.NewWelfordWindow(period),
+		in:             in,
+	}
+
+}
+
+func (h *historicalVolatility) Next() (float64, error) {
+
+	if h.period <= 0 {
+		return 0.0, errors.New(
+			"historical volatility period cannot be negative or zero")
+	}
+
+	if h.periodsPerYear <= 0.0 {
+		return 0.0, errors.New(
+			"historical volatility periods per year must be greater than zero")
+	}
+
+	// retrieve the next piece of input data
+	next, err := h.in.Next()
+	if err != nil && err != stream.ErrNotReady {
+		return 0.0, err
+	}
+
+	// values flagged as not ready, or marked as not ready with NaN, are
+	// passed through unchanged rather than poisoning the running statistics
+	if err == stream.ErrNotReady || gomath.IsNaN(next) {
+		return next, err
+	}
+
+	if next <= 0.0 {
+		return 0.0, errors.New(
+			"historical volatility requires prices greater than zero")
+	}
+
+	// the first price has no return
+	if !h.started {
+		h.started = true
+		h.previous = next
+		return 0.0, nil
+	}
+
+	h.state.Add(gomath.Log(next / h.previous))
+	h.previous = next
+
+	return gomath.Sqrt(h.state.SampleVariance() * h.periodsPerYear), nil
+
+}
+
+func (h *historicalVolatility) Close() {
+	h.in.Close()
+}
+
+func (h *historicalVolatility) WarmUp() int {
+
+	// the first price has no return so period returns require an additional
+	// price
+	return h.period + inputWarmUp(h.in)
+
+}
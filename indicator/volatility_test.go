@@ -0,0 +1,84 @@
+package indicator_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+)
+
+// TestStdDevStream tests calculations performed by a standard deviation
+// stream.
+func TestStdDevStream(t *testing.T) {
+
+	// define input data
+	inputData := []float64{3.0, 4.0, 2.0, 6.0, 4.0, 5.0, 0.0, 1.0}
+
+	// define expected output
+	expectedOutput := []float64{0.0, 0.5, 0.816496580927726, 1.632993161855452,
+		1.632993161855452, 0.816496580927726, 2.160246899469287,
+		2.160246899469287}
+
+	ss := indicator.NewStdDevStream(input.NewListStream(inputData), 3)
+	defer ss.Close()
+
+	assertStreamOutput(t, ss, expectedOutput)
+
+	// assert that the rolling calculation matches a direct calculation over
+	// mock data
+	prices := loadMockData(t)
+
+	expectedOutput = make([]float64, len(prices))
+	for i := range prices {
+
+		start := i - 19
+		if start < 0 {
+			start = 0
+		}
+		frame := prices[start : i+1]
+
+		mean := 0.0
+		for _, price := range frame {
+			mean += price
+		}
+		mean /= float64(len(frame))
+
+		variance := 0.0
+		for _, price := range frame {
+			variance += (price - mean) * (price - mean)
+		}
+
+		expectedOutput[i] = math.Sqrt(variance / float64(len(frame)))
+
+	}
+
+	ss = indicator.NewStdDevStream(input.NewListStream(prices), 20)
+	defer ss.Close()
+
+	assertStreamOutput(t, ss, expectedOutput)
+
+}
+
+// TestHistoricalVolatilityStream tests calculations performed by a historical
+// volatility stream.
+func TestHistoricalVolatilityStream(t *testing.T) {
+
+	// define input data
+	inputData := []float64{1.0, 2.0, 1.0, 2.0, 4.0}
+
+	// define expected output
+	expectedOutput := []float64{0.0, 0.0, 18.727805535093722,
+		18.727805535093722, 0.0}
+
+	hs := indicator.NewHistoricalVolatilityStream(
+		input.NewListStream(inputData), 2, indicator.CalendarDaysPerYear)
+	defer hs.Close()
+
+	if hs.WarmUp() != 2 {
+		t.Fatalf("expected warm-up 2, got %d", hs.WarmUp())
+	}
+
+	assertStreamOutput(t, hs, expectedOutput)
+
+}
@@ -0,0 +1,463 @@
+package indicator
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// windowSum maintains the sum of a sliding window of values.
+type windowSum struct {
+	frame *util.Ring[float64]
+	sum   util.KahanSum
+}
+
+// newWindowSum returns an empty sliding window sum of the specified period.
+func newWindowSum(period int) *windowSum {
+
+	return &windowSum{
+		frame: util.NewRing[float64](period),
+	}
+
+}
+
+// update adds a value to the window, evicting the oldest value if the window
+// is full, and returns the sum of the window.
+func (w *windowSum) update(value float64) float64 {
+
+	if evicted, ok := w.frame.Add(value); ok {
+		w.sum.Add(-evicted)
+	}
+	w.sum.Add(value)
+
+	return w.sum.Value()
+
+}
+
+// moneyFlowMultiplier returns the position of the close price within the range
+// of a candle scaled from -1 at the low to 1 at the high, or 0 if the candle
+// has no range.
+func moneyFlowMultiplier(candle stream.Candle) float64 {
+
+	if candle.High == candle.Low {
+		return 0.0
+	}
+
+	return ((candle.Close - candle.Low) - (candle.High - candle.Close)) /
+		(candle.High - candle.Low)
+
+}
+
+// obv is the concrete implementation of a stream that outputs the On-Balance
+// Volume of input candles.
+type obv struct {
+	total    float64
+	previous float64
+	started  bool
+	in       stream.CandleStream
+}
+
+// NewOBVStream returns a stream that outputs the On-Balance Volume of input
+// candles; the volume of each candle is added to a running total when the
+// close price rises and subtracted when it falls.
+func NewOBVStream(in stream.CandleStream) Indicator {
+
+	return &obv{
+		in: in,
+	}
+
+}
+
+func (o *obv) Next() (float64, error) {
+
+	// retrieve the next candle
+	candle, err := o.in.Next()
+	if err != nil {
+		return 0.0, err
+	}
+
+	if o.started && candle.Close > o.previous {
+		o.total += candle.Volume
+	} else if o.started && candle.Close < o.previous {
+		o.total -= candle.Volume
+	}
+
+	o.previous = candle.Close
+	o.started = true
+
+	return o.total, nil
+
+}
+
+func (o *obv) Close() {
+	o.in.Close()
+}
+
+func (o *obv) WarmUp() int {
+	return 0
+}
+
+// vwap is the concrete implementation of a stream that outputs the Volume
+// Weighted Average Price of input candles since the start of each session.
+type vwap struct {
+	session  time.Duration
+	start    time.Time
+	weighted util.KahanSum
+	volume   util.KahanSum
+	in       stream.CandleStream
+}
+
+// NewVWAPStream returns a stream that outputs the Volume Weighted Average
+// Price, using the typical price of each candle, since the start of the
+// session containing the candle; sessions are consecutive intervals of the
+// specified length measured from the zero time, so a session of 24 hours
+// begins at midnight UTC. If no volume has been traded during the session the
+// typical price of the candle is output.
+func NewVWAPStream(in stream.CandleStream, session time.Duration) Indicator {
+
+	return &vwap{
+		session: session,
+		in:      in,
+	}
+
+}
+
+func (v *vwap) Next() (float64, error) {
+
+	if v.session <= 0 {
+		return 0.0, errors.New("vwap session cannot be negative or zero")
+	}
+
+	// retrieve the next candle
+	candle, err := v.in.Next()
+	if err != nil {
+		return 0.0, err
+	}
+
+	// reset the average at the start of each session
+	if start := candle.Timestamp.Truncate(v.session); !start.Equal(v.start) {
+		v.start = start
+		v.weighted.Reset()
+		v.volume.Reset()
+	}
+
+	typical := typicalPrice(candle)
+	v.weighted.Add(typical * candle.Volume)
+	v.volume.Add(candle.Volume)
+
+	if volume := v.volume.Value(); util.CompareFloat(volume, 0.0) != 0 {
+		return v.weighted.Value() / volume, nil
+	}
+
+	return typical, nil
+
+}
+
+func (v *vwap) Close() {
+	v.in.Close()
+}
+
+func (v *vwap) WarmUp() int {
+	return 0
+}
+
+// rollingVWAP is the concrete implementation of a stream that outputs the
+// Volume Weighted Average Price of input candles over a sliding window.
+type rollingVWAP struct {
+	period   int
+	weighted *windowSum
+	volume   *windowSum
+	in       stream.CandleStream
+}
+
+// NewRollingVWAPStream returns a stream that outputs the Volume Weighted
+// Average Price, using the typical price of each candle, over the last period
+// candles. If no volume has been traded during the period the typical price of
+// the candle is output.
+func NewRollingVWAPStream(in stream.CandleStream, period int) Indicator {
+
+	return &rollingVWAP{
+		period:   period,
+		weighted: newWindowSum(period),
+		volume:   newWindowSum(period),
+		in:       in,
+	}
+
+}
+
+func (r *rollingVWAP) Next() (float64, error) {
+
+	if r.period <= 0 {
+		return 0.0, errors.New("vwap period cannot be negative or zero")
+	}
+
+	// retrieve the next candle
+	candle, err := r.in.Next()
+	if err != nil {
+		return 0.0, err
+	}
+
+	typical := typicalPrice(candle)
+	weighted := r.weighted.update(typical * candle.Volume)
+	volume := r.volume.update(candle.Volume)
+
+	if util.CompareFloat(volume, 0.0) != 0 {
+		return weighted / volume, nil
+	}
+
+	return typical, nil
+
+}
+
+func (r *rollingVWAP) Close() {
+	r.in.Close()
+}
+
+func (r *rollingVWAP) WarmUp() int {
+	return r.period - 1
+}
+
+// mfi is the concrete implementation of a stream that applies a Money Flow
+// Index function to input candles.
+type mfi struct {
+	period   int
+	positive *windowSum
+	negative *windowSum
+	previous float64
+	started  bool
+	in       stream.CandleStream
+}
+
+// NewMFIStream returns a stream that applies a Money Flow Index function to
+// input candles; the index is the proportion of money flow, the typical price
+// multiplied by volume, over the period that occurred while the typical price
+// was rising, scaled from 0 to 100. The index is 50 while there is no money
+// flow.
+func NewMFIStream(in stream.CandleStream, period int) Indicator {
+
+	return &mfi{
+		period:   period,
+		positive: newWindowSum(period),
+		negative: newWindowSum(period),
+		in:       in,
+	}
+
+}
+
+func (m *mfi) Next() (float64, error) {
+
+	if m.period <= 0 {
+		return 0.0, errors.New(
+			"money flow index period cannot be negative or zero")
+	}
+
+	// retrieve the next candle
+	candle, err := m.in.Next()
+	if err != nil {
+		return 0.0, err
+	}
+
+	typical := typicalPrice(candle)
+
+	// the direction of money flow requires a previous typical price
+	if !m.started {
+		m.started = true
+		m.previous = typical
+		return 50.0, nil
+	}
+
+	flow := typical * candle.Volume
+	positive, negative := 0.0, 0.0
+	if typical > m.previous {
+		positive = flow
+	} else if typical < m.previous {
+		negative = flow
+	}
+	m.previous = typical
+
+	positive = m.positive.update(positive)
+	negative = m.negative.update(negative)
+
+	if util.CompareFloat(positive+negative, 0.0) == 0 {
+		return 50.0, nil
+	}
+
+	return 100.0 * positive / (positive + negative), nil
+
+}
+
+func (m *mfi) Close() {
+	m.in.Close()
+}
+
+func (m *mfi) WarmUp() int {
+
+	// the first candle has no direction so period flows require an additional
+	// candle
+	return m.period
+
+}
+
+// accumulationDistribution is the concrete implementation of a stream that
+// outputs the Accumulation/Distribution line of input candles.
+type accumulationDistribution struct {
+	total util.KahanSum
+	in    stream.CandleStream
+}
+
+// NewADLineStream returns a stream that outputs the Accumulation/Distribution
+// line of input candles; the line is a running total of the volume of each
+// candle weighted by the position of the close price within the candle's
+// range, from -1 at the low to 1 at the high.
+func NewADLineStream(in stream.CandleStream) Indicator {
+
+	return &accumulationDistribution{
+		in: in,
+	}
+
+}
+
+func (a *accumulationDistribution) Next() (float64, error) {
+
+	// retrieve the next candle
+	candle, err := a.in.Next()
+	if err != nil {
+		return 0.0, err
+	}
+
+	a.total.Add(moneyFlowMultiplier(candle) * candle.Volume)
+
+	return a.total.Value(), nil
+
+}
+
+func (a *accumulationDistribution) Close() {
+	a.in.Close()
+}
+
+func (a *accumulationDistribution) WarmUp() int {
+	return 0
+}
+
+// cmf is the concrete implementation of a stream that applies a Chaikin Money
+// Flow function to input candles.
+type cmf struct {
+	period int
+	flow   *windowSum
+	volume *windowSum
+	in     stream.CandleStream
+}
+
+// NewCMFStream returns a stream that applies a Chaikin Money Flow function to
+// input candles; the output is the sum of the volume of each candle weighted by
+// the position of the close price within its range, divided by the total
+// volume over the period. The output is 0 while there is no volume.
+func NewCMFStream(in stream.CandleStream, period int) Indicator {
+
+	return &cmf{
+		period: period,
+		flow:   newWindowSum(period),
+		volume: newWindowSum(period),
+		in:     in,
+	}
+
+}
+
+func (c *cmf) Next() (float64, error) {
+
+	if c.period <= 0 {
+		return 0.0, errors.New(
+			"chaikin money flow period cannot be negative or zero")
+	}
+
+	// retrieve the next candle
+	candle, err := c.in.Next()
+	if err != nil {
+		return 0.0, err
+	}
+
+	flow := c.flow.update(moneyFlowMultiplier(candle) * candle.Volume)
+	volume := c.volume.update(candle.Volume)
+
+	if util.CompareFloat(volume, 0.0) == 0 {
+		return 0.0, nil
+	}
+
+	return flow / volume, nil
+
+}
+
+func (c *cmf) Close() {
+	c.in.Close()
+}
+
+func (c *cmf) WarmUp() int {
+	return c.period - 1
+}
+
+// chaikinOscillator is the concrete implementation of a stream that applies a
+// Chaikin Oscillator function to input candles.
+type chaikinOscillator struct {
+	fastPeriod int
+	slowPeriod int
+	total      util.KahanSum
+	fast       *emaState
+	slow       *emaState
+	in         stream.CandleStream
+}
+
+// NewChaikinOscillatorStream returns a stream that applies a Chaikin
+// Oscillator function to input candles; the oscillator is the difference
+// between a fast and a slow exponential moving average, conventionally of 3
+// and 10 periods, of the Accumulation/Distribution line. The averages are
+// seeded with the first value of the line.
+func NewChaikinOscillatorStream(in stream.CandleStream, fastPeriod,
+	slowPeriod int) Indicator {
+
+	return &chaikinOscillator{
+		fastPeriod: fastPeriod,
+		slowPeriod: slowPeriod,
+		fast: newEMAState(fastPeriod, SmoothingAlpha(fastPeriod, 2.0),
+			SeedFirst),
+		slow: newEMAState(slowPeriod, SmoothingAlpha(slowPeriod, 2.0),
+			SeedFirst),
+		in: in,
+	}
+
+}
+
+func (c *chaikinOscillator) Next() (float64, error) {
+
+	if c.fastPeriod <= 0 || c.slowPeriod <= 0 {
+		return 0.0, errors.New(
+			"chaikin oscillator periods cannot be negative or zero")
+	}
+
+	// retrieve the next candle
+	candle, err := c.in.Next()
+	if err != nil {
+		return 0.0, err
+	}
+
+	c.total.Add(moneyFlowMultiplier(candle) * candle.Volume)
+	line := c.total.Value()
+
+	return c.fast.update(line) - c.slow.update(line), nil
+
+}
+
+func (c *chaikinOscillator) Close() {
+	c.in.Close()
+}
+
+func (c *chaikinOscillator) WarmUp() int {
+
+	if c.slowPeriod > c.fastPeriod {
+		return c.slowPeriod - 1
+	}
+
+	return c.fastPeriod - 1
+
+}
@@ -0,0 +1,92 @@
+package indicator_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// volumeCandles returns the candle input used to test volume streams; the
+// candles are hourly and span two daily sessions.
+func volumeCandles() []stream.Candle {
+
+	start := time.Date(2020, 5, 18, 22, 0, 0, 0, time.UTC)
+
+	candles := []stream.Candle{
+		{High: 4.0, Low: 2.0, Close: 3.0, Volume: 10.0},
+		{High: 5.0, Low: 3.0, Close: 4.0, Volume: 20.0},
+		{High: 6.0, Low: 4.0, Close: 5.0, Volume: 10.0},
+		{High: 5.0, Low: 2.0, Close: 2.0, Volume: 30.0},
+		{High: 3.0, Low: 1.0, Close: 3.0, Volume: 0.0},
+	}
+
+	for i := range candles {
+		candles[i].Timestamp = start.Add(time.Duration(i) * time.Hour)
+	}
+
+	return candles
+
+}
+
+// TestVolumeStreams tests calculations performed by volume based streams.
+func TestVolumeStreams(t *testing.T) {
+
+	// define test cases
+	cases := []struct {
+		name           string
+		newStream      func(in stream.CandleStream) indicator.Indicator
+		expectedOutput []float64
+	}{
+		{"TestOBV", func(in stream.CandleStream) indicator.Indicator {
+			return indicator.NewOBVStream(in)
+		}, []float64{0.0, 20.0, 30.0, 0.0, 0.0}},
+		{"TestVWAP", func(in stream.CandleStream) indicator.Indicator {
+			return indicator.NewVWAPStream(in, 24*time.Hour)
+		}, []float64{3.0, 3.6666666666666665, 5.0, 3.5, 3.5}},
+		{"TestRollingVWAP", func(in stream.CandleStream) indicator.Indicator {
+			return indicator.NewRollingVWAPStream(in, 2)
+		}, []float64{3.0, 3.6666666666666665, 4.333333333333333, 3.5, 3.0}},
+		{"TestMFI", func(in stream.CandleStream) indicator.Indicator {
+			return indicator.NewMFIStream(in, 2)
+		}, []float64{50.0, 100.0, 100.0, 35.714285714285715, 0.0}},
+		{"TestADLine", func(in stream.CandleStream) indicator.Indicator {
+			return indicator.NewADLineStream(in)
+		}, []float64{0.0, 0.0, 0.0, -30.0, -30.0}},
+		{"TestCMF", func(in stream.CandleStream) indicator.Indicator {
+			return indicator.NewCMFStream(in, 2)
+		}, []float64{0.0, 0.0, 0.0, -0.75, -1.0}},
+		{"TestChaikinOscillator", func(
+			in stream.CandleStream) indicator.Indicator {
+			return indicator.NewChaikinOscillatorStream(in, 2, 3)
+		}, []float64{0.0, 0.0, 0.0, -5.0, -4.166666666666664}},
+	}
+
+	for _, tc := range cases {
+
+		t.Run(tc.name, func(t *testing.T) {
+
+			vs := tc.newStream(input.NewCandleListStream(volumeCandles()))
+			defer vs.Close()
+
+			assertStreamOutput(t, vs, tc.expectedOutput)
+
+		})
+
+	}
+
+}
+
+// TestMFIStreamBounds tests that a Money Flow Index stream is bounded over mock
+// data.
+func TestMFIStreamBounds(t *testing.T) {
+
+	ms := indicator.NewMFIStream(input.NewCandleListStream(loadMockCandles(t)),
+		14)
+	defer ms.Close()
+
+	assertStreamBounds(t, ms, 0.0, 100.0)
+
+}
@@ -0,0 +1,78 @@
+package indicator
+
+import (
+	"errors"
+
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// vwma is the concrete implementation of a stream that applies a Volume
+// Weighted Moving Average function to the close price of input candles.
+type vwma struct {
+	period      int
+	prices      *util.Ring[float64]
+	volumes     *util.Ring[float64]
+	priceSum    util.KahanSum
+	volumeSum   util.KahanSum
+	weightedSum util.KahanSum
+	in          stream.CandleStream
+}
+
+// NewVWMAStream returns a stream that applies a Volume Weighted Moving Average
+// function to the close price of input candles; if no volume was traded over
+// the period the simple moving average of the close price is output.
+func NewVWMAStream(in stream.CandleStream, period int) Indicator {
+
+	return &vwma{
+		period:  period,
+		prices:  util.NewRing[float64](period),
+		volumes: util.NewRing[float64](period),
+		in:      in,
+	}
+
+}
+
+func (v *vwma) Next() (float64, error) {
+
+	if v.period <= 0 {
+		return 0.0, errors.New(
+			"volume weighted moving average period cannot be negative or zero")
+	}
+
+	// retrieve the next candle
+	candle, err := v.in.Next()
+	if err != nil {
+		return 0.0, err
+	}
+
+	// add the close price and volume to the frame, removing evicted values
+	// from the running sums
+	if evicted, ok := v.prices.Add(candle.Close); ok {
+		evictedVolume, _ := v.volumes.Add(candle.Volume)
+		v.priceSum.Add(-evicted)
+		v.volumeSum.Add(-evictedVolume)
+		v.weightedSum.Add(-evicted * evictedVolume)
+	} else {
+		v.volumes.Add(candle.Volume)
+	}
+
+	v.priceSum.Add(candle.Close)
+	v.volumeSum.Add(candle.Volume)
+	v.weightedSum.Add(candle.Close * candle.Volume)
+
+	if volume := v.volumeSum.Value(); util.CompareFloat(volume, 0.0) != 0 {
+		return v.weightedSum.Value() / volume, nil
+	}
+
+	return v.priceSum.Value() / float64(v.prices.Size()), nil
+
+}
+
+func (v *vwma) Close() {
+	v.in.Close()
+}
+
+func (v *vwma) WarmUp() int {
+	return v.period - 1
+}
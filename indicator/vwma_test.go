@@ -0,0 +1,81 @@
+package indicator_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// loadMockCandles reads the candles from the mock coinbase data file.
+func loadMockCandles(t testing.TB) []stream.Candle {
+
+	mockData, err := os.Open("../input/mock_data.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mockData.Close()
+
+	ms, err := input.NewCoinbaseMockCandleStream(mockData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ms.Close()
+
+	var candles []stream.Candle
+	for {
+
+		candle, err := ms.Next()
+		if err == stream.ErrEndOfStream {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		candles = append(candles, candle)
+
+	}
+
+	return candles
+
+}
+
+// TestVWMAStream tests calculations performed by a Volume Weighted Moving
+// Average stream.
+func TestVWMAStream(t *testing.T) {
+
+	// define input data
+	inputData := []stream.Candle{
+		{Close: 2.0, Volume: 1.0},
+		{Close: 4.0, Volume: 3.0},
+		{Close: 6.0, Volume: 0.0},
+		{Close: 8.0, Volume: 0.0},
+	}
+
+	// define expected output averages; the final frame has no volume so the
+	// simple average of close prices is output
+	expectedOutput := []float64{2.0, 3.5, 4.0, 7.0}
+
+	vs := indicator.NewVWMAStream(input.NewCandleListStream(inputData), 2)
+	defer vs.Close()
+
+	assertStreamOutput(t, vs, expectedOutput)
+
+	// assert that the stream matches the reference values once ready
+	vs = indicator.NewVWMAStream(input.NewCandleListStream(
+		referenceCandles()), 5)
+	defer vs.Close()
+
+	assertReadyOutput(t, vs, []float64{
+		9668.1193707333, 9636.6001711304, 9610.7155988543, 9606.0479145850,
+		9624.9108457042, 9662.0632888115, 9670.8961793104, 9665.5459386024,
+		9655.6516929686, 9655.5470648130, 9646.8836117139, 9647.6809233355,
+		9660.2633874984, 9688.7544014266, 9703.9253925567, 9677.7069394695,
+		9673.7062958351, 9651.4110785736, 9616.3166029441, 9585.9330433543,
+		9570.5019564317, 9562.4332201519, 9545.3523546576, 9674.9053856879,
+		9727.8319305694, 9756.3244051839,
+	})
+
+}
@@ -0,0 +1,90 @@
+package indicator
+
+import (
+	"math"
+
+	"github.com/bsladewski/lapis/stream"
+)
+
+// WarmUpMode determines how an indicator presents values output before the
+// indicator has filled its window.
+type WarmUpMode int
+
+const (
+	// WarmUpPartial outputs values calculated from partial data as-is.
+	WarmUpPartial WarmUpMode = iota
+	// WarmUpSuppress consumes values calculated from partial data without
+	// outputting them; the first value output is the first ready value.
+	WarmUpSuppress
+	// WarmUpNaN outputs NaN in place of values calculated from partial data.
+	WarmUpNaN
+	// WarmUpFlag outputs values calculated from partial data along with a
+	// stream.ErrNotReady error.
+	WarmUpFlag
+)
+
+// warmUp is the concrete implementation of a stream that applies a warm-up
+// mode to the values output by an indicator.
+type warmUp struct {
+	mode WarmUpMode
+	n    int
+	in   Indicator
+}
+
+// NewWarmUpStream returns an indicator that applies the specified warm-up mode
+// to the values output by the supplied indicator.
+func NewWarmUpStream(in Indicator, mode WarmUpMode) Indicator {
+
+	return &warmUp{
+		mode: mode,
+		in:   in,
+	}
+
+}
+
+func (w *warmUp) Next() (float64, error) {
+
+	for {
+
+		// retrieve the next value from the indicator; values flagged as not
+		// ready by the indicator's input count towards the warm-up
+		value, err := w.in.Next()
+		if err != nil && err != stream.ErrNotReady {
+			return 0.0, err
+		}
+
+		// if the indicator has filled its window pass values through
+		if w.n >= w.in.WarmUp() {
+			return value, err
+		}
+		w.n++
+
+		switch w.mode {
+		case WarmUpSuppress:
+			continue
+		case WarmUpNaN:
+			return math.NaN(), nil
+		case WarmUpFlag:
+			return value, stream.ErrNotReady
+		default:
+			return value, err
+		}
+
+	}
+
+}
+
+func (w *warmUp) Close() {
+	w.in.Close()
+}
+
+func (w *warmUp) WarmUp() int {
+
+	// suppressed values are never output so the stream is ready immediately
+	if w.mode == WarmUpSuppress {
+		return 0
+	}
+
+	return w.in.WarmUp()
+
+}
@@ -0,0 +1,187 @@
+package indicator_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// TestWarmUpStream tests each warm-up mode applied to a Moving Average stream.
+func TestWarmUpStream(t *testing.T) {
+
+	// define input data
+	inputData := []float64{3.0, 4.0, 2.0, 6.0, 4.0, 5.0}
+
+	nan := math.NaN()
+
+	// define test cases; expected errors are returned alongside the value at
+	// the same index
+	cases := []struct {
+		name           string
+		mode           indicator.WarmUpMode
+		warmUp         int
+		expectedOutput []float64
+		expectedErrs   []error
+	}{
+		{"TestPartial", indicator.WarmUpPartial, 2,
+			[]float64{3.0, 3.5, 3.0, 4.0, 4.0, 5.0},
+			[]error{nil, nil, nil, nil, nil, nil}},
+		{"TestSuppress", indicator.WarmUpSuppress, 0,
+			[]float64{3.0, 4.0, 4.0, 5.0},
+			[]error{nil, nil, nil, nil}},
+		{"TestNaN", indicator.WarmUpNaN, 2,
+			[]float64{nan, nan, 3.0, 4.0, 4.0, 5.0},
+			[]error{nil, nil, nil, nil, nil, nil}},
+		{"TestFlag", indicator.WarmUpFlag, 2,
+			[]float64{3.0, 3.5, 3.0, 4.0, 4.0, 5.0},
+			[]error{stream.ErrNotReady, stream.ErrNotReady, nil, nil, nil,
+				nil}},
+	}
+
+	for _, tc := range cases {
+
+		t.Run(tc.name, func(t *testing.T) {
+
+			ws := indicator.NewWarmUpStream(
+				indicator.NewMAStream(input.NewListStream(inputData), 3),
+				tc.mode,
+			)
+			defer ws.Close()
+
+			if ws.WarmUp() != tc.warmUp {
+				t.Fatalf("expected warm-up %d, got %d", tc.warmUp, ws.WarmUp())
+			}
+
+			for i, value := range tc.expectedOutput {
+
+				streamValue, err := ws.Next()
+				if err != tc.expectedErrs[i] {
+					t.Fatalf("index %d; expected err %v, got %v", i,
+						tc.expectedErrs[i], err)
+				}
+
+				if math.IsNaN(value) {
+					if !math.IsNaN(streamValue) {
+						t.Fatalf("index %d; expected NaN, got %.2f", i,
+							streamValue)
+					}
+					continue
+				}
+
+				if util.CompareFloat(streamValue, value) != 0 {
+					t.Fatalf("index %d; expected %.2f, got %.2f", i, value,
+						streamValue)
+				}
+
+			}
+
+			// assert that next results in end of stream error
+			if _, err := ws.Next(); err != stream.ErrEndOfStream {
+				t.Fatalf("expected end of stream error, got %v", err)
+			}
+
+		})
+
+	}
+
+}
+
+// TestWarmUpChained tests that the warm-up length of an indicator includes the
+// warm-up length of its input.
+func TestWarmUpChained(t *testing.T) {
+
+	// define input data
+	inputData := []float64{3.0, 4.0, 2.0, 6.0, 4.0, 5.0, 0.0, 1.0}
+
+	// an average of a NaN flagged average should not output a value until
+	// both windows are full
+	mas := indicator.NewMAStream(
+		indicator.NewWarmUpStream(
+			indicator.NewMAStream(input.NewListStream(inputData), 3),
+			indicator.WarmUpNaN,
+		),
+		2,
+	)
+	defer mas.Close()
+
+	if mas.WarmUp() != 3 {
+		t.Fatalf("expected warm-up 3, got %d", mas.WarmUp())
+	}
+
+	expectedOutput := []float64{3.0, 3.5, 4.0, 4.5, 4.0}
+
+	for i := 0; i < 3; i++ {
+
+		streamValue, err := mas.Next()
+		if err != nil {
+			t.Fatalf("index %d; err: %v", i, err)
+		}
+
+		if i < 2 && !math.IsNaN(streamValue) {
+			t.Fatalf("index %d; expected NaN, got %.2f", i, streamValue)
+		}
+
+		if i == 2 && util.CompareFloat(streamValue, expectedOutput[0]) != 0 {
+			t.Fatalf("index %d; expected %.2f, got %.2f", i,
+				expectedOutput[0], streamValue)
+		}
+
+	}
+
+	for i, value := range expectedOutput[1:] {
+
+		streamValue, err := mas.Next()
+		if err != nil {
+			t.Fatalf("index %d; err: %v", i, err)
+		}
+
+		if util.CompareFloat(streamValue, value) != 0 {
+			t.Fatalf("index %d; expected %.2f, got %.2f", i, value,
+				streamValue)
+		}
+
+	}
+
+}
+
+// TestWarmUpChainedFlag tests that an indicator passes values flagged as not
+// ready by its input through along with the error.
+func TestWarmUpChainedFlag(t *testing.T) {
+
+	// define input data
+	inputData := []float64{3.0, 4.0, 2.0, 6.0, 4.0, 5.0, 0.0, 1.0}
+
+	mas := indicator.NewMAStream(
+		indicator.NewWarmUpStream(
+			indicator.NewMAStream(input.NewListStream(inputData), 3),
+			indicator.WarmUpFlag,
+		),
+		2,
+	)
+	defer mas.Close()
+
+	// the provisional values of the inner average are passed through and do
+	// not count towards the outer average
+	expectedOutput := []float64{3.0, 3.5, 3.0, 3.5, 4.0, 4.5, 4.0}
+
+	for i, value := range expectedOutput {
+
+		streamValue, err := mas.Next()
+		if i < 2 && err != stream.ErrNotReady {
+			t.Fatalf("index %d; expected not ready error, got %v", i, err)
+		} else if i >= 2 && err != nil {
+			t.Fatalf("index %d; err: %v", i, err)
+		}
+
+		if util.CompareFloat(streamValue, value) != 0 {
+			t.Fatalf("index %d; expected %.2f, got %.2f", i, value,
+				streamValue)
+		}
+
+	}
+
+}
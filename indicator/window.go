@@ -0,0 +1,79 @@
+package indicator
+
+// indexedValue is a value along with the index at which it was received.
+type indexedValue struct {
+	index int
+	value float64
+}
+
+// extrema tracks the minimum and maximum of a sliding window of values in
+// amortized constant time; each extremum is kept at the front of a monotonic
+// queue of the values that may still become the extremum of the window.
+type extrema struct {
+	period int
+	n      int
+	maxima []indexedValue
+	minima []indexedValue
+}
+
+// newExtrema returns an empty sliding window of the specified period.
+func newExtrema(period int) *extrema {
+
+	return &extrema{
+		period: period,
+	}
+
+}
+
+// update adds a value to the sliding window, evicting any values that have
+// fallen outside of the window.
+func (e *extrema) update(value float64) {
+
+	current := indexedValue{e.n, value}
+	e.n++
+
+	// values that are no greater than the new value can never be the maximum
+	// again, likewise for values no less than the new value and the minimum;
+	// ties favor the most recent value
+	for len(e.maxima) > 0 && e.maxima[len(e.maxima)-1].value <= value {
+		e.maxima = e.maxima[:len(e.maxima)-1]
+	}
+	e.maxima = append(e.maxima, current)
+
+	for len(e.minima) > 0 && e.minima[len(e.minima)-1].value >= value {
+		e.minima = e.minima[:len(e.minima)-1]
+	}
+	e.minima = append(e.minima, current)
+
+	// remove extrema that have fallen outside of the window
+	for e.maxima[0].index <= current.index-e.period {
+		e.maxima = e.maxima[1:]
+	}
+
+	for e.minima[0].index <= current.index-e.period {
+		e.minima = e.minima[1:]
+	}
+
+}
+
+// max returns the maximum value within the window.
+func (e *extrema) max() float64 {
+	return e.maxima[0].value
+}
+
+// min returns the minimum value within the window.
+func (e *extrema) min() float64 {
+	return e.minima[0].value
+}
+
+// maxAge returns the number of values received since the maximum value within
+// the window.
+func (e *extrema) maxAge() int {
+	return e.n - 1 - e.maxima[0].index
+}
+
+// minAge returns the number of values received since the minimum value within
+// the window.
+func (e *extrema) minAge() int {
+	return e.n - 1 - e.minima[0].index
+}
@@ -0,0 +1,183 @@
+package indicator
+
+import (
+	"errors"
+	gomath "math"
+
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// wmaState holds the state of a single linearly weighted moving average
+// calculation; the weighted sum is maintained incrementally so each value is
+// calculated in constant time regardless of the period.
+type wmaState struct {
+	frame    *util.Ring[float64]
+	sum      util.KahanSum
+	weighted util.KahanSum
+}
+
+// newWMAState returns the initial state of a linearly weighted moving average.
+func newWMAState(period int) *wmaState {
+
+	return &wmaState{
+		frame: util.NewRing[float64](period),
+	}
+
+}
+
+// update adds a value to the weighted moving average and returns the updated
+// average; the newest value has a weight equal to the number of values in the
+// frame and the oldest value has a weight of one.
+func (w *wmaState) update(value float64) float64 {
+
+	if evicted, ok := w.frame.Add(value); ok {
+		// when the frame is full every value loses one unit of weight which
+		// also removes the evicted value from the weighted sum
+		w.weighted.Add(-w.sum.Value())
+		w.sum.Add(-evicted)
+	}
+
+	w.weighted.Add(float64(w.frame.Size()) * value)
+	w.sum.Add(value)
+
+	size := float64(w.frame.Size())
+
+	return w.weighted.Value() / (size * (size + 1) / 2)
+
+}
+
+// wma is the concrete implementation of a stream that applies a Weighted
+// Moving Average function to input data.
+type wma struct {
+	period int
+	state  *wmaState
+	in     stream.Stream
+}
+
+// NewWMAStream returns a stream that applies a linearly Weighted Moving Average
+// function to input data.
+func NewWMAStream(in stream.Stream, period int) Indicator {
+
+	return &wma{
+		period: period,
+		state:  newWMAState(period),
+		in:     in,
+	}
+
+}
+
+func (w *wma) Next() (float64, error) {
+
+	if w.period <= 0 {
+		return 0.0, errors.New(
+			"weighted moving average period cannot be negative or zero")
+	}
+
+	// retrieve the next piece of input data
+	next, err := w.in.Next()
+	if err != nil && err != stream.ErrNotReady {
+		return 0.0, err
+	}
+
+	// values flagged as not ready, or marked as not ready with NaN, are
+	// passed through unchanged rather than poisoning the weighted sum
+	if err == stream.ErrNotReady || gomath.IsNaN(next) {
+		return next, err
+	}
+
+	return w.state.update(next), nil
+
+}
+
+func (w *wma) Close() {
+	w.in.Close()
+}
+
+func (w *wma) WarmUp() int {
+	return w.period - 1 + inputWarmUp(w.in)
+}
+
+// hull is the concrete implementation of a stream that applies a Hull Moving
+// Average function to input data.
+type hull struct {
+	period int
+	half   *wmaState
+	full   *wmaState
+	smooth *wmaState
+	in     stream.Stream
+}
+
+// NewHullStream returns a stream that applies a Hull Moving Average function to
+// input data; the Hull Moving Average is a weighted moving average, with a
+// period of the square root of the specified period, of the difference between
+// twice the weighted moving average of half the period and the weighted moving
+// average of the full period.
+func NewHullStream(in stream.Stream, period int) Indicator {
+
+	return &hull{
+		period: period,
+		half:   newWMAState(hullHalfPeriod(period)),
+		full:   newWMAState(period),
+		smooth: newWMAState(hullSmoothPeriod(period)),
+		in:     in,
+	}
+
+}
+
+// hullHalfPeriod returns the period of the shorter weighted moving average
+// used to calculate a Hull Moving Average.
+func hullHalfPeriod(period int) int {
+
+	if period < 2 {
+		return 1
+	}
+
+	return period / 2
+
+}
+
+// hullSmoothPeriod returns the period of the weighted moving average used to
+// smooth a Hull Moving Average.
+func hullSmoothPeriod(period int) int {
+
+	if period < 1 {
+		return 1
+	}
+
+	return int(gomath.Sqrt(float64(period)))
+
+}
+
+func (h *hull) Next() (float64, error) {
+
+	if h.period <= 0 {
+		return 0.0, errors.New(
+			"hull moving average period cannot be negative or zero")
+	}
+
+	// retrieve the next piece of input data
+	next, err := h.in.Next()
+	if err != nil && err != stream.ErrNotReady {
+		return 0.0, err
+	}
+
+	// values flagged as not ready, or marked as not ready with NaN, are
+	// passed through unchanged rather than poisoning the weighted sums
+	if err == stream.ErrNotReady || gomath.IsNaN(next) {
+		return next, err
+	}
+
+	raw := 2.0*h.half.update(next) - h.full.update(next)
+
+	return h.smooth.update(raw), nil
+
+}
+
+func (h *hull) Close() {
+	h.in.Close()
+}
+
+func (h *hull) WarmUp() int {
+	return h.period - 1 + hullSmoothPeriod(h.period) - 1 + inputWarmUp(h.in)
+}
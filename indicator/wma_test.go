@@ -0,0 +1,75 @@
+package indicator_test
+
+import (
+	"testing"
+
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+)
+
+// TestWMAStream tests calculations performed by a Weighted Moving Average
+// stream.
+func TestWMAStream(t *testing.T) {
+
+	// define input data
+	inputData := []float64{3.0, 4.0, 2.0, 6.0, 4.0, 5.0, 0.0, 1.0}
+
+	// define expected output averages
+	expectedOutput := []float64{3.0, 3.6666666666666665, 2.8333333333333335,
+		4.333333333333333, 4.333333333333333, 4.833333333333333,
+		2.3333333333333335, 1.3333333333333333}
+
+	wmas := indicator.NewWMAStream(input.NewListStream(inputData), 3)
+	defer wmas.Close()
+
+	assertStreamOutput(t, wmas, expectedOutput)
+
+	// assert that the stream matches the reference values once ready
+	wmas = indicator.NewWMAStream(input.NewListStream(referencePrices), 5)
+	defer wmas.Close()
+
+	assertReadyOutput(t, wmas, []float64{
+		9660.6100000000, 9632.2093333333, 9619.4000000000, 9628.6373333333,
+		9642.6926666667, 9668.8806666667, 9671.0220000000, 9658.9346666667,
+		9644.1313333333, 9644.2893333333, 9659.0846666667, 9668.5486666667,
+		9678.1886666667, 9694.4206666667, 9708.2193333333, 9686.1693333333,
+		9672.5440000000, 9645.7006666667, 9597.3333333333, 9561.6026666667,
+		9564.7020000000, 9564.7980000000, 9564.2033333333, 9645.3140000000,
+		9702.9460000000, 9739.7040000000,
+	})
+
+}
+
+// TestHullStream tests calculations performed by a Hull Moving Average stream.
+func TestHullStream(t *testing.T) {
+
+	// define input data
+	inputData := []float64{3.0, 4.0, 2.0, 6.0, 4.0, 5.0, 0.0, 1.0}
+
+	// define expected output averages
+	expectedOutput := []float64{3.0, 3.444444444444444, 2.888888888888889,
+		4.322222222222223, 5.166666666666667, 4.866666666666667,
+		1.8666666666666671, -0.16666666666666666}
+
+	hs := indicator.NewHullStream(input.NewListStream(inputData), 4)
+	defer hs.Close()
+
+	if hs.WarmUp() != 4 {
+		t.Fatalf("expected warm-up 4, got %d", hs.WarmUp())
+	}
+
+	assertStreamOutput(t, hs, expectedOutput)
+
+	// assert that the stream matches the reference values once ready
+	hs = indicator.NewHullStream(input.NewListStream(referencePrices), 9)
+	defer hs.Close()
+
+	assertReadyOutput(t, hs, []float64{
+		9686.8541111111, 9678.7993333333, 9652.3281481481, 9635.2909629630,
+		9647.3242962963, 9669.0701111111, 9688.3228888889, 9705.5558518519,
+		9721.8106296296, 9707.2573333333, 9679.7183333333, 9637.3819259259,
+		9577.9754444444, 9525.4235185185, 9513.1306666667, 9527.7371111111,
+		9548.9184074074, 9632.7868148148, 9727.4582962963, 9802.6718518519,
+	})
+
+}
@@ -0,0 +1,118 @@
+package input
+
+import (
+	"fmt"
+
+	"github.com/bsladewski/lapis/stream"
+)
+
+// A candleList represents a candle stream open on a pre-defined list of
+// candles.
+type candleList struct {
+	index   int
+	candles []stream.Candle
+}
+
+// NewCandleListStream returns a candle stream that reads candles from a
+// pre-defined list.
+func NewCandleListStream(candles []stream.Candle) stream.CandleStream {
+
+	return &candleList{
+		candles: candles,
+	}
+
+}
+
+func (l *candleList) Next() (stream.Candle, error) {
+
+	// return end of stream error if the list is exhausted
+	if l.index >= len(l.candles) {
+		return stream.Candle{}, stream.ErrEndOfStream
+	}
+
+	// retrieve and return the next candle in the list
+	candle := l.candles[l.index]
+	l.index++
+
+	return candle, nil
+
+}
+
+func (l *candleList) Close() {
+	l.candles = nil
+}
+
+// CandleField identifies a value of a candle.
+type CandleField int
+
+const (
+	// FieldOpen identifies the open price of a candle.
+	FieldOpen CandleField = iota
+	// FieldHigh identifies the high price of a candle.
+	FieldHigh
+	// FieldLow identifies the low price of a candle.
+	FieldLow
+	// FieldClose identifies the close price of a candle.
+	FieldClose
+	// FieldVolume identifies the base currency volume of a candle.
+	FieldVolume
+	// FieldQuoteVolume identifies the quote currency volume of a candle.
+	FieldQuoteVolume
+)
+
+// Value returns the value of this field from the supplied candle.
+func (f CandleField) Value(candle stream.Candle) (float64, error) {
+
+	switch f {
+	case FieldOpen:
+		return candle.Open, nil
+	case FieldHigh:
+		return candle.High, nil
+	case FieldLow:
+		return candle.Low, nil
+	case FieldClose:
+		return candle.Close, nil
+	case FieldVolume:
+		return candle.Volume, nil
+	case FieldQuoteVolume:
+		return candle.QuoteVolume, nil
+	}
+
+	return 0.0, fmt.Errorf("invalid candle field: %d", f)
+
+}
+
+// A candleField is the concrete implementation of a stream that reads a single
+// value from each candle in a candle stream.
+type candleField struct {
+	field CandleField
+	in    stream.CandleStream
+}
+
+// NewCandleFieldStream returns a stream that reads the specified field from
+// each candle in the input candle stream.
+func NewCandleFieldStream(in stream.CandleStream,
+	field CandleField) stream.Stream {
+
+	return &candleField{
+		field: field,
+		in:    in,
+	}
+
+}
+
+func (c *candleField) Next() (float64, error) {
+
+	// retrieve the next candle from the input stream
+	candle, err := c.in.Next()
+	if err != nil {
+		return 0.0, err
+	}
+
+	return c.field.Value(candle)
+
+}
+
+func (c *candleField) Close() {
+	c.in.Close()
+}
@@ -0,0 +1,67 @@
+package input_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// TestCandleFieldStream tests reading each field from a candle list stream.
+func TestCandleFieldStream(t *testing.T) {
+
+	// define input data
+	candle := stream.Candle{
+		Timestamp:   time.Date(2020, 5, 19, 10, 0, 0, 0, time.UTC),
+		Open:        1.0,
+		High:        4.0,
+		Low:         0.5,
+		Close:       2.0,
+		Volume:      10.0,
+		QuoteVolume: 20.0,
+	}
+
+	// define test cases
+	cases := []struct {
+		name     string
+		field    input.CandleField
+		expected float64
+	}{
+		{"TestOpen", input.FieldOpen, 1.0},
+		{"TestHigh", input.FieldHigh, 4.0},
+		{"TestLow", input.FieldLow, 0.5},
+		{"TestClose", input.FieldClose, 2.0},
+		{"TestVolume", input.FieldVolume, 10.0},
+		{"TestQuoteVolume", input.FieldQuoteVolume, 20.0},
+	}
+
+	for _, tc := range cases {
+
+		t.Run(tc.name, func(t *testing.T) {
+
+			// create the stream
+			fs := input.NewCandleFieldStream(
+				input.NewCandleListStream([]stream.Candle{candle}), tc.field)
+			defer fs.Close()
+
+			// assert that the field is read from the candle
+			streamValue, err := fs.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if streamValue != tc.expected {
+				t.Fatalf("expected %.2f, got %.2f", tc.expected, streamValue)
+			}
+
+			// assert that next results in end of stream error
+			if _, err := fs.Next(); err != stream.ErrEndOfStream {
+				t.Fatalf("expected end of stream error, got %v", err)
+			}
+
+		})
+
+	}
+
+}
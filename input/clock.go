@@ -0,0 +1,60 @@
+package input
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/bsladewski/lapis/stream"
+)
+
+// A CandleClock is a candle stream that passes candles through while keeping
+// the timestamp of the most recent candle read; its Now method is supplied as
+// the clock of outputs, so that values calculated from the candles are
+// timestamped by the time of the data rather than the time they were
+// processed, e.g. when replaying historical candles.
+type CandleClock struct {
+	now atomic.Pointer[time.Time]
+	in  stream.CandleStream
+}
+
+// NewCandleClock returns a candle clock that reads candles from the supplied
+// candle stream; until the first candle is read the clock reads the zero time.
+func NewCandleClock(in stream.CandleStream) *CandleClock {
+
+	return &CandleClock{
+		in: in,
+	}
+
+}
+
+// Next gets the next candle in the stream and advances the clock to its
+// timestamp.
+func (c *CandleClock) Next() (stream.Candle, error) {
+
+	candle, err := c.in.Next()
+	if err == nil {
+		now := candle.Timestamp.UTC()
+		c.now.Store(&now)
+	}
+
+	return candle, err
+
+}
+
+// Close closes the underlying candle stream.
+func (c *CandleClock) Close() {
+	c.in.Close()
+}
+
+// Now returns the timestamp of the most recent candle read, in UTC; it may be
+// called concurrently with Next.
+func (c *CandleClock) Now() time.Time {
+
+	now := c.now.Load()
+	if now == nil {
+		return time.Time{}
+	}
+
+	return *now
+
+}
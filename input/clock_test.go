@@ -0,0 +1,53 @@
+package input_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// TestCandleClock tests that a candle clock reads the timestamp of the most
+// recent candle read.
+func TestCandleClock(t *testing.T) {
+
+	start := time.Date(2020, 5, 19, 10, 0, 0, 0, time.UTC)
+
+	clock := input.NewCandleClock(input.NewCandleListStream([]stream.Candle{
+		{Timestamp: start, Close: 1.0},
+		{Timestamp: start.Add(time.Hour), Close: 2.0},
+	}))
+	defer clock.Close()
+
+	if !clock.Now().IsZero() {
+		t.Fatalf("expected the zero time, got %v", clock.Now())
+	}
+
+	// values calculated from the candles are timestamped by the candles
+	closes := input.NewCandleFieldStream(clock, input.FieldClose)
+
+	for i := 0; i < 2; i++ {
+
+		if _, err := closes.Next(); err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := start.Add(time.Duration(i) * time.Hour); !clock.Now().
+			Equal(expected) {
+			t.Fatalf("index %d; expected %v, got %v", i, expected,
+				clock.Now())
+		}
+
+	}
+
+	// the clock holds the last timestamp at the end of the stream
+	if _, err := closes.Next(); err != stream.ErrEndOfStream {
+		t.Fatalf("expected end of stream error, got %v", err)
+	}
+
+	if !clock.Now().Equal(start.Add(time.Hour)) {
+		t.Fatalf("expected %v, got %v", start.Add(time.Hour), clock.Now())
+	}
+
+}
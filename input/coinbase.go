@@ -52,6 +52,21 @@ func NewCoinbaseMockStream(mockDataReader io.Reader) (stream.Stream, error) {
 
 }
 
+// NewCoinbaseMockCandleStream retrieves a candle stream that can be used to
+// mock candle data retrieved from coinbase.
+func NewCoinbaseMockCandleStream(
+	mockDataReader io.Reader) (stream.CandleStream, error) {
+
+	candles, err := parseHistoricalCandles(mockDataReader)
+	if err != nil {
+		return nil, fmt.Errorf("parse mock data file, err: %v", err)
+	}
+
+	// the mock stream reads the historical candle data as a list
+	return NewCandleListStream(candles), nil
+
+}
+
 // spotPriceResponse is used to read the exchange rate returned by the get spot
 // price request to the coinbase API.
 type spotPriceResponse struct {
@@ -132,6 +147,25 @@ func (m *coinbaseMockStream) Close() {
 // prices.
 func GetHistoricalData() ([]float64, error) {
 
+	candles, err := GetHistoricalCandles()
+	if err != nil {
+		return nil, err
+	}
+
+	// the close price will represent the spot price for each index
+	spotPrices := []float64{}
+	for _, candle := range candles {
+		spotPrices = append(spotPrices, candle.Close)
+	}
+
+	return spotPrices, nil
+
+}
+
+// GetHistoricalCandles retrieves historical hourly coinbase candles for bitcoin
+// prices ordered by timestamp ascending.
+func GetHistoricalCandles() ([]stream.Candle, error) {
+
 	// create a new GET request for historical coinbase spot prices
 	req, err := http.NewRequest(
 		"GET",
@@ -151,28 +185,44 @@ func GetHistoricalData() ([]float64, error) {
 	}
 	defer resp.Body.Close()
 
-	spotPrices, err := parseHistoricalData(resp.Body)
+	candles, err := parseHistoricalCandles(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("parse historical data, err: %v", err)
 	}
 
-	return spotPrices, nil
+	return candles, nil
 
 }
 
+// parseHistoricalData parses historical coinbase data returning the close
+// price of each candle ordered by timestamp ascending.
 func parseHistoricalData(r io.Reader) ([]float64, error) {
 
-	// define type for storing close prices associated with a specific timestamp
-	type priceDatum struct {
-		Timestamp time.Time
-		Price     float64
+	candles, err := parseHistoricalCandles(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// build ordered list of price data; the close price will represent the
+	// spot price for each index
+	spotPrices := []float64{}
+	for _, candle := range candles {
+		spotPrices = append(spotPrices, candle.Close)
 	}
 
+	return spotPrices, nil
+
+}
+
+// parseHistoricalCandles parses historical coinbase data returning candles
+// ordered by timestamp ascending.
+func parseHistoricalCandles(r io.Reader) ([]stream.Candle, error) {
+
 	// open csv reader
 	reader := csv.NewReader(r)
 	reader.FieldsPerRecord = -1
 
-	priceData := []priceDatum{}
+	candles := []stream.Candle{}
 
 	// loop until we are finished reading the csv data
 	for {
@@ -191,7 +241,7 @@ func parseHistoricalData(r io.Reader) ([]float64, error) {
 		}
 
 		// if we are not looking at a row of data, skip the row
-		if record[1] != "BTCUSD" {
+		if len(record) < 8 || record[1] != "BTCUSD" {
 			continue
 		}
 
@@ -203,29 +253,39 @@ func parseHistoricalData(r io.Reader) ([]float64, error) {
 			return nil, fmt.Errorf("parsing timestamp, err: %v", err)
 		}
 
-		// parse the close price from csv data; the close price will represent
-		// the spot price for this index
-		price, err := strconv.ParseFloat(record[5], 64)
-		if err != nil {
-			return nil, fmt.Errorf("parsing price, err: %v", err)
+		candle := stream.Candle{Timestamp: timestamp}
+
+		// parse the open, high, low and close prices followed by the base and
+		// quote volume from csv data
+		fields := []struct {
+			name  string
+			value *float64
+		}{
+			{"open", &candle.Open},
+			{"high", &candle.High},
+			{"low", &candle.Low},
+			{"price", &candle.Close},
+			{"volume", &candle.Volume},
+			{"quote volume", &candle.QuoteVolume},
+		}
+
+		for i, field := range fields {
+			*field.value, err = strconv.ParseFloat(record[2+i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s, err: %v", field.name, err)
+			}
 		}
 
-		// add the timestamp and close price to price data
-		priceData = append(priceData, priceDatum{timestamp, price})
+		// add the candle to candle data
+		candles = append(candles, candle)
 
 	}
 
-	// sort price data by timestamp ascending
-	sort.Slice(priceData, func(i, j int) bool {
-		return priceData[i].Timestamp.Before(priceData[j].Timestamp)
+	// sort candle data by timestamp ascending
+	sort.Slice(candles, func(i, j int) bool {
+		return candles[i].Timestamp.Before(candles[j].Timestamp)
 	})
 
-	// build ordered list of price data
-	spotPrices := []float64{}
-	for _, priceDatum := range priceData {
-		spotPrices = append(spotPrices, priceDatum.Price)
-	}
-
-	return spotPrices, nil
+	return candles, nil
 
 }
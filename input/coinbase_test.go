@@ -72,6 +72,56 @@ func TestGetMockSpotPrice(t *testing.T) {
 
 }
 
+// TestCoinbaseMockCandleStream tests loading and retrieving historical candles
+// to mock coinbase candle data.
+func TestCoinbaseMockCandleStream(t *testing.T) {
+
+	mockData, err := os.Open("mock_data.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// construct the coinbase mock candle stream
+	ms, err := input.NewCoinbaseMockCandleStream(mockData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ms.Close()
+
+	var previous stream.Candle
+
+	for {
+
+		// retrieve the next mock candle
+		candle, err := ms.Next()
+		if err == stream.ErrEndOfStream {
+			break
+		}
+
+		// assert next item of mock data was read without error
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// assert that candles are ordered by timestamp ascending
+		if !previous.Timestamp.IsZero() &&
+			!candle.Timestamp.After(previous.Timestamp) {
+			t.Fatalf("expected timestamp after %v, got %v",
+				previous.Timestamp, candle.Timestamp)
+		}
+
+		// assert that the high and low prices bound the open and close prices
+		if candle.High < candle.Open || candle.High < candle.Close ||
+			candle.Low > candle.Open || candle.Low > candle.Close {
+			t.Fatalf("invalid candle: %+v", candle)
+		}
+
+		previous = candle
+
+	}
+
+}
+
 // TestGetHistoricalData tests retrieving historical bitcoin data up to the
 // current time.
 func TestGetHistoricalData(t *testing.T) {
@@ -0,0 +1,295 @@
+package input
+
+import (
+	"context"
+	"fmt"
+	"io"
+	gomath "math"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/ipc"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/apache/arrow/go/v14/parquet/file"
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// columnarBatchSize is the number of rows read from a Parquet file at a time.
+const columnarBatchSize = 65536
+
+// ReaderAtSeeker is implemented by sources of columnar files that support
+// random access, such as *os.File and *bytes.Reader.
+type ReaderAtSeeker interface {
+	io.Reader
+	io.ReaderAt
+	io.Seeker
+}
+
+// recordSource reads the record batches of a columnar file in order; a
+// record is valid until the next record is read and nil is returned at the
+// end of the file.
+type recordSource interface {
+	next() (arrow.Record, error)
+	close()
+}
+
+// parquetSource reads the record batches of a Parquet file.
+type parquetSource struct {
+	reader *file.Reader
+	rr     pqarrow.RecordReader
+}
+
+func (p *parquetSource) next() (arrow.Record, error) {
+
+	record, err := p.rr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+
+	return record, err
+
+}
+
+func (p *parquetSource) close() {
+	p.rr.Release()
+	p.reader.Close()
+}
+
+// arrowSource reads the record batches of an Arrow IPC file.
+type arrowSource struct {
+	reader *ipc.FileReader
+	index  int
+}
+
+func (a *arrowSource) next() (arrow.Record, error) {
+
+	if a.index >= a.reader.NumRecords() {
+		return nil, nil
+	}
+
+	record, err := a.reader.Record(a.index)
+	a.index++
+
+	return record, err
+
+}
+
+func (a *arrowSource) close() {
+	a.reader.Close()
+}
+
+// A columnarCandles is the concrete implementation of a candle stream that
+// reads candles from a columnar file.
+type columnarCandles struct {
+	source  recordSource
+	schema  *arrow.Schema
+	columns []int
+	candles []stream.Candle
+	index   int
+	done    bool
+}
+
+// NewParquetCandleStream returns a candle stream that reads candles in order
+// from a Parquet file, such as a file written by a candle columnar output or
+// by pandas or DuckDB. Columns are matched by name regardless of case: the
+// timestamp column must hold timestamps of any unit, which are read as UTC if
+// they have no time zone, the open, high, low and close columns are required
+// and the volume and quote_volume columns are read as zero if they are
+// missing. Value columns may hold floating point or integer values, and null
+// values are read as NaN.
+func NewParquetCandleStream(r ReaderAtSeeker) (stream.CandleStream, error) {
+
+	reader, err := file.NewParquetReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("open parquet file, err: %v", err)
+	}
+
+	fr, err := pqarrow.NewFileReader(reader, pqarrow.ArrowReadProperties{
+		BatchSize: columnarBatchSize,
+	}, memory.DefaultAllocator)
+	if err != nil {
+		reader.Close()
+		return nil, fmt.Errorf("open parquet file, err: %v", err)
+	}
+
+	rr, err := fr.GetRecordReader(context.Background(), nil, nil)
+	if err != nil {
+		reader.Close()
+		return nil, fmt.Errorf("read parquet file, err: %v", err)
+	}
+
+	return newColumnarCandles(&parquetSource{reader: reader, rr: rr},
+		rr.Schema())
+
+}
+
+// NewArrowCandleStream returns a candle stream that reads candles in order
+// from an Arrow IPC file, also known as a Feather version 2 file. Columns are
+// read as described by NewParquetCandleStream.
+func NewArrowCandleStream(r ReaderAtSeeker) (stream.CandleStream, error) {
+
+	reader, err := ipc.NewFileReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("open arrow file, err: %v", err)
+	}
+
+	return newColumnarCandles(&arrowSource{reader: reader}, reader.Schema())
+
+}
+
+// newColumnarCandles returns a candle stream reading records from a source
+// with the supplied schema, closing the source if the schema does not hold
+// candles.
+func newColumnarCandles(source recordSource,
+	schema *arrow.Schema) (stream.CandleStream, error) {
+
+	names := []string{"timestamp", "open", "high", "low", "close", "volume",
+		"quote_volume"}
+	required := 5
+
+	c := &columnarCandles{
+		source:  source,
+		schema:  schema,
+		columns: make([]int, len(names)),
+	}
+
+	// resolve the index of each column by name
+	for i, name := range names {
+
+		c.columns[i] = -1
+		for j, field := range schema.Fields() {
+			if strings.EqualFold(field.Name, name) {
+				c.columns[i] = j
+				break
+			}
+		}
+
+		if c.columns[i] < 0 && i < required {
+			source.close()
+			return nil, fmt.Errorf("missing candle column: %s", name)
+		}
+
+	}
+
+	if _, ok := schema.Field(c.columns[0]).Type.(*arrow.TimestampType); !ok {
+		source.close()
+		return nil, fmt.Errorf("invalid timestamp column type: %s",
+			schema.Field(c.columns[0]).Type)
+	}
+
+	for i, column := range c.columns[1:] {
+		if column < 0 {
+			continue
+		}
+		switch schema.Field(column).Type.ID() {
+		case arrow.FLOAT64, arrow.FLOAT32, arrow.INT64, arrow.INT32:
+		default:
+			source.close()
+			return nil, fmt.Errorf("invalid %s column type: %s", names[i+1],
+				schema.Field(column).Type)
+		}
+	}
+
+	return c, nil
+
+}
+
+// floatValue returns the value of a numeric column at a row, or NaN if the
+// value is null.
+func floatValue(column arrow.Array, row int) float64 {
+
+	if column.IsNull(row) {
+		return gomath.NaN()
+	}
+
+	switch values := column.(type) {
+	case *array.Float64:
+		return values.Value(row)
+	case *array.Float32:
+		return float64(values.Value(row))
+	case *array.Int64:
+		return float64(values.Value(row))
+	case *array.Int32:
+		return float64(values.Value(row))
+	}
+
+	return gomath.NaN()
+
+}
+
+// read reads the candles of the next record batch, returning false at the end
+// of the file.
+func (c *columnarCandles) read() (bool, error) {
+
+	record, err := c.source.next()
+	if err != nil {
+		return false, fmt.Errorf("read candles, err: %v", err)
+	} else if record == nil {
+		return false, nil
+	}
+
+	timestamps := record.Column(c.columns[0]).(*array.Timestamp)
+	unit := c.schema.Field(c.columns[0]).Type.(*arrow.TimestampType).Unit
+
+	c.candles = make([]stream.Candle, record.NumRows())
+	c.index = 0
+
+	for row := range c.candles {
+
+		candle := &c.candles[row]
+		candle.Timestamp = timestamps.Value(row).ToTime(unit).In(time.UTC)
+
+		values := []*float64{&candle.Open, &candle.High, &candle.Low,
+			&candle.Close, &candle.Volume, &candle.QuoteVolume}
+		for i, value := range values {
+			if column := c.columns[i+1]; column >= 0 {
+				*value = floatValue(record.Column(column), row)
+			}
+		}
+
+	}
+
+	return true, nil
+
+}
+
+func (c *columnarCandles) Next() (stream.Candle, error) {
+
+	// read record batches until a candle is available
+	for c.index >= len(c.candles) {
+
+		if c.done {
+			return stream.Candle{}, stream.ErrEndOfStream
+		}
+
+		ok, err := c.read()
+		if err != nil {
+			return stream.Candle{}, err
+		} else if !ok {
+			c.done = true
+			c.source.close()
+			return stream.Candle{}, stream.ErrEndOfStream
+		}
+
+	}
+
+	candle := c.candles[c.index]
+	c.index++
+
+	return candle, nil
+
+}
+
+func (c *columnarCandles) Close() {
+
+	if !c.done {
+		c.done = true
+		c.source.close()
+	}
+
+	c.candles = nil
+
+}
@@ -0,0 +1,216 @@
+package input_test
+
+import (
+	"bytes"
+	gomath "math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/ipc"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// pandasRecord returns a record shaped like a data frame written by pandas,
+// with capitalized column names, millisecond timestamps without a time zone,
+// mixed value types, a null value and no volume columns.
+func pandasRecord() arrow.Record {
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "Timestamp", Type: &arrow.TimestampType{Unit: arrow.Millisecond}},
+		{Name: "Open", Type: arrow.PrimitiveTypes.Float32},
+		{Name: "High", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "Low", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "Close", Type: arrow.PrimitiveTypes.Int32},
+	}, nil)
+
+	b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer b.Release()
+
+	start := time.Date(2020, 5, 19, 10, 0, 0, 0, time.UTC)
+	b.Field(0).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{
+		arrow.Timestamp(start.UnixMilli()),
+		arrow.Timestamp(start.Add(time.Minute).UnixMilli()),
+	}, nil)
+	b.Field(1).(*array.Float32Builder).AppendValues([]float32{1.5, 2.5}, nil)
+	b.Field(2).(*array.Int64Builder).AppendValues([]int64{4, 5}, nil)
+	b.Field(3).(*array.Float64Builder).AppendValues([]float64{0.5, 0},
+		[]bool{true, false})
+	b.Field(4).(*array.Int32Builder).AppendValues([]int32{2, 3}, nil)
+
+	return b.NewRecord()
+
+}
+
+// writeParquet writes a record to a Parquet file.
+func writeParquet(t *testing.T, record arrow.Record) []byte {
+
+	t.Helper()
+
+	table := array.NewTableFromRecords(record.Schema(),
+		[]arrow.Record{record})
+	defer table.Release()
+
+	var buf bytes.Buffer
+	if err := pqarrow.WriteTable(table, &buf, 1024, nil,
+		pqarrow.DefaultWriterProps()); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+
+}
+
+// writeArrow writes a record to an Arrow IPC file.
+func writeArrow(t *testing.T, record arrow.Record) []byte {
+
+	t.Helper()
+
+	// the arrow file writer requires a seekable destination
+	path := filepath.Join(t.TempDir(), "candles.arrow")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	w, err := ipc.NewFileWriter(f, ipc.WithSchema(record.Schema()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Write(record); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+
+}
+
+// TestColumnarCandleStream tests reading candles from columnar files written
+// by other tools.
+func TestColumnarCandleStream(t *testing.T) {
+
+	record := pandasRecord()
+	defer record.Release()
+
+	cases := []struct {
+		name   string
+		data   []byte
+		reader func(input.ReaderAtSeeker) (stream.CandleStream, error)
+	}{
+		{"TestParquet", writeParquet(t, record), input.NewParquetCandleStream},
+		{"TestArrow", writeArrow(t, record), input.NewArrowCandleStream},
+	}
+
+	start := time.Date(2020, 5, 19, 10, 0, 0, 0, time.UTC)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			cs, err := tc.reader(bytes.NewReader(tc.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cs.Close()
+
+			first, err := cs.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !first.Timestamp.Equal(start) ||
+				first.Timestamp.Location() != time.UTC ||
+				first.Open != 1.5 || first.High != 4 || first.Low != 0.5 ||
+				first.Close != 2 || first.Volume != 0 ||
+				first.QuoteVolume != 0 {
+				t.Fatalf("unexpected candle: %+v", first)
+			}
+
+			second, err := cs.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// null values are read as NaN
+			if !second.Timestamp.Equal(start.Add(time.Minute)) ||
+				!gomath.IsNaN(second.Low) || second.Close != 3 {
+				t.Fatalf("unexpected candle: %+v", second)
+			}
+
+			if _, err := cs.Next(); err != stream.ErrEndOfStream {
+				t.Fatalf("expected end of stream, got %v", err)
+			}
+
+		})
+	}
+
+}
+
+// TestColumnarCandleStreamInvalid tests rejecting files that do not hold
+// candles.
+func TestColumnarCandleStreamInvalid(t *testing.T) {
+
+	missing := arrow.NewSchema([]arrow.Field{
+		{Name: "timestamp", Type: arrow.FixedWidthTypes.Timestamp_us},
+		{Name: "open", Type: arrow.PrimitiveTypes.Float64},
+	}, nil)
+
+	untimed := arrow.NewSchema([]arrow.Field{
+		{Name: "timestamp", Type: arrow.BinaryTypes.String},
+		{Name: "open", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "high", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "low", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "close", Type: arrow.PrimitiveTypes.Float64},
+	}, nil)
+
+	textual := arrow.NewSchema([]arrow.Field{
+		{Name: "timestamp", Type: arrow.FixedWidthTypes.Timestamp_us},
+		{Name: "open", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "high", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "low", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "close", Type: arrow.BinaryTypes.String},
+	}, nil)
+
+	for _, schema := range []*arrow.Schema{missing, untimed, textual} {
+
+		b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+		record := b.NewRecord()
+		b.Release()
+
+		if _, err := input.NewParquetCandleStream(
+			bytes.NewReader(writeParquet(t, record))); err == nil {
+			t.Fatalf("expected an error for schema: %s", schema)
+		}
+
+		if _, err := input.NewArrowCandleStream(
+			bytes.NewReader(writeArrow(t, record))); err == nil {
+			t.Fatalf("expected an error for schema: %s", schema)
+		}
+
+		record.Release()
+
+	}
+
+	// data that is not a columnar file is rejected
+	if _, err := input.NewParquetCandleStream(
+		bytes.NewReader([]byte("timestamp,open"))); err == nil {
+		t.Fatal("expected an error for a file that is not parquet")
+	}
+
+}
@@ -51,3 +51,51 @@ func (l *list) Next() (float64, error) {
 func (l *list) Close() {
 	l.values.Clear()
 }
+
+// A multiList represents a multi stream open on a pre-defined list of sets of
+// values.
+type multiList struct {
+	components []string
+	index      int
+	values     [][]float64
+}
+
+// NewMultiListStream returns a multi stream that reads sets of values from a
+// pre-defined list; each set of values must be ordered by component.
+func NewMultiListStream(components []string,
+	values [][]float64) stream.MultiStream {
+
+	return &multiList{
+		components: components,
+		values:     values,
+	}
+
+}
+
+func (l *multiList) Components() []string {
+	return l.components
+}
+
+func (l *multiList) Next() ([]float64, error) {
+
+	// return end of stream error if the list is exhausted
+	if l.index >= len(l.values) {
+		return nil, stream.ErrEndOfStream
+	}
+
+	// retrieve and return the next set of values in the list
+	values := l.values[l.index]
+	l.index++
+
+	if len(values) != len(l.components) {
+		return nil, fmt.Errorf("expected %d values, got %d",
+			len(l.components), len(values))
+	}
+
+	return values, nil
+
+}
+
+func (l *multiList) Close() {
+	l.values = nil
+}
@@ -36,16 +36,67 @@ func (s *splitter) Next() (float64, error) {
 	// input
 	s.m--
 
-	// if the current error is not nil, return it
-	if s.err != nil {
+	// if the current error is not nil, return it; values flagged as not ready
+	// are returned along with the error
+	if s.err != nil && s.err != stream.ErrNotReady {
 		return 0.0, s.err
 	}
 
 	// return the current value
-	return s.value, nil
+	return s.value, s.err
 
 }
 
 func (s *splitter) Close() {
 	s.in.Close()
 }
+
+// candleSplitter is the concrete implementation of a candle stream that splits
+// an input by allowing it to be read a specified number of times before
+// advancing to the next candle in the stream.
+type candleSplitter struct {
+	n      int
+	m      int
+	candle stream.Candle
+	in     stream.CandleStream
+	err    error
+}
+
+// NewCandleSplitterStream returns a candle stream that can be used to split an
+// input candle stream amongst multiple output streams by repeating each candle
+// in the stream n times.
+func NewCandleSplitterStream(in stream.CandleStream,
+	n int) stream.CandleStream {
+
+	return &candleSplitter{
+		in: in,
+		n:  n,
+	}
+
+}
+
+func (s *candleSplitter) Next() (stream.Candle, error) {
+
+	// if we have consumed the current input n times, read the next candle
+	if s.m <= 0 {
+		s.candle, s.err = s.in.Next()
+		s.m = s.n
+	}
+
+	// decrement the counter that tracks how many times to return the current
+	// input
+	s.m--
+
+	// if the current error is not nil, return it
+	if s.err != nil {
+		return stream.Candle{}, s.err
+	}
+
+	// return the current candle
+	return s.candle, nil
+
+}
+
+func (s *candleSplitter) Close() {
+	s.in.Close()
+}
@@ -48,3 +48,39 @@ func TestSplitterStream(t *testing.T) {
 	}
 
 }
+
+// TestCandleSplitterStream test the result of splitting an input candle stream
+// multiple ways.
+func TestCandleSplitterStream(t *testing.T) {
+
+	// define input data
+	inputData := []stream.Candle{{Close: 3.0}, {Close: 4.0}}
+
+	// define expected output close prices
+	expectedOutput := []float64{3.0, 3.0, 4.0, 4.0}
+
+	// create the splitter stream
+	ss := input.NewCandleSplitterStream(input.NewCandleListStream(inputData), 2)
+	defer ss.Close()
+
+	// assert that expected ouput matches data from splitter stream
+	for i, value := range expectedOutput {
+
+		candle, err := ss.Next()
+		if err != nil {
+			t.Fatalf("index %d; err: %v", i, err)
+		}
+
+		if util.CompareFloat(candle.Close, value) != 0 {
+			t.Fatalf("index %d; expected %.2f, got %.2f", i, value,
+				candle.Close)
+		}
+
+	}
+
+	// assert that next results in end of stream error
+	if _, err := ss.Next(); err != stream.ErrEndOfStream {
+		t.Fatalf("expected end of stream error, got %v", err)
+	}
+
+}
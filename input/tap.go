@@ -0,0 +1,145 @@
+package input
+
+import (
+	"fmt"
+	gomath "math"
+
+	"github.com/bsladewski/lapis/stream"
+)
+
+// tapResult is a single value read from a component of a multi stream.
+type tapResult struct {
+	value float64
+	err   error
+}
+
+// tapSource holds a multi stream that is shared by a number of tap streams;
+// each set of values read from the multi stream is queued for every tap that
+// is still open so that taps may be read independently of one another.
+type tapSource struct {
+	components []int
+	queues     [][]tapResult
+	closed     []bool
+	in         stream.MultiStream
+}
+
+// A tap is the concrete implementation of a stream that reads a single
+// component of a shared multi stream.
+type tap struct {
+	index  int
+	source *tapSource
+}
+
+// NewTapStreams returns a stream for each of the named components of a multi
+// stream, or for every component if no names are specified. The streams share
+// the multi stream so each set of values is computed once; values are queued
+// until every open tap has read them and the multi stream is closed once all
+// taps have been closed. Queues are not bounded: a tap that falls behind the
+// others holds every value it has not read, so taps should be read in step and
+// a tap that will not be read should be closed.
+func NewTapStreams(in stream.MultiStream,
+	components ...string) ([]stream.Stream, error) {
+
+	names := in.Components()
+	if len(components) == 0 {
+		components = names
+	}
+
+	source := &tapSource{
+		components: make([]int, len(components)),
+		queues:     make([][]tapResult, len(components)),
+		closed:     make([]bool, len(components)),
+		in:         in,
+	}
+
+	taps := make([]stream.Stream, len(components))
+
+	// resolve the index of each named component
+	for i, component := range components {
+
+		source.components[i] = -1
+		for j, name := range names {
+			if name == component {
+				source.components[i] = j
+				break
+			}
+		}
+
+		if source.components[i] < 0 {
+			return nil, fmt.Errorf("invalid component: %s", component)
+		}
+
+		taps[i] = &tap{index: i, source: source}
+
+	}
+
+	return taps, nil
+
+}
+
+// advance reads the next set of values from the multi stream and queues the
+// value of each tapped component for every tap that is still open.
+func (s *tapSource) advance() {
+
+	values, err := s.in.Next()
+
+	for i, component := range s.components {
+
+		if s.closed[i] {
+			continue
+		}
+
+		// a set of values flagged as not ready may be incomplete, in which
+		// case the missing components are marked as not ready with NaN
+		result := tapResult{err: err}
+		if err == nil || err == stream.ErrNotReady {
+			result.value = gomath.NaN()
+			if component < len(values) {
+				result.value = values[component]
+			}
+		}
+
+		s.queues[i] = append(s.queues[i], result)
+
+	}
+
+}
+
+func (t *tap) Next() (float64, error) {
+
+	if t.source.closed[t.index] {
+		return 0.0, stream.ErrEndOfStream
+	}
+
+	// if every value read from the multi stream has been consumed by this tap
+	// read the next set of values
+	if len(t.source.queues[t.index]) == 0 {
+		t.source.advance()
+	}
+
+	result := t.source.queues[t.index][0]
+	t.source.queues[t.index] = t.source.queues[t.index][1:]
+
+	return result.value, result.err
+
+}
+
+func (t *tap) Close() {
+
+	if t.source.closed[t.index] {
+		return
+	}
+
+	t.source.closed[t.index] = true
+	t.source.queues[t.index] = nil
+
+	// close the multi stream once every tap has been closed
+	for _, closed := range t.source.closed {
+		if !closed {
+			return
+		}
+	}
+
+	t.source.in.Close()
+
+}
@@ -0,0 +1,108 @@
+package input_test
+
+import (
+	gomath "math"
+	"testing"
+
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// TestTapStreams tests reading the components of a multi stream from tap
+// streams.
+func TestTapStreams(t *testing.T) {
+
+	// define input data
+	components := []string{"a", "b", "c"}
+	inputData := [][]float64{{1.0, 2.0, 3.0}, {4.0, 5.0, 6.0}}
+
+	// create taps for two of the components
+	taps, err := input.NewTapStreams(
+		input.NewMultiListStream(components, inputData), "c", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// read the first tap to the end of the stream before reading the second
+	for i, expected := range [][]float64{{3.0, 6.0}, {1.0, 4.0}} {
+
+		for j, value := range expected {
+
+			streamValue, err := taps[i].Next()
+			if err != nil {
+				t.Fatalf("tap %d, index %d; err: %v", i, j, err)
+			}
+
+			if util.CompareFloat(streamValue, value) != 0 {
+				t.Fatalf("tap %d, index %d; expected %.2f, got %.2f", i, j,
+					value, streamValue)
+			}
+
+		}
+
+		// assert that next results in end of stream error
+		if _, err := taps[i].Next(); err != stream.ErrEndOfStream {
+			t.Fatalf("expected end of stream error, got %v", err)
+		}
+
+		taps[i].Close()
+
+	}
+
+	// assert that tapping an unknown component fails
+	if _, err := input.NewTapStreams(
+		input.NewMultiListStream(components, inputData), "d"); err == nil {
+		t.Fatal("expected invalid component error")
+	}
+
+}
+
+// notReadyMulti is a multi stream that flags a set of values as not ready
+// without supplying any values before reading its input.
+type notReadyMulti struct {
+	stream.MultiStream
+	flagged bool
+}
+
+func (n *notReadyMulti) Next() ([]float64, error) {
+
+	if !n.flagged {
+		n.flagged = true
+		return nil, stream.ErrNotReady
+	}
+
+	return n.MultiStream.Next()
+
+}
+
+// TestTapStreamsNotReady tests that taps mark components missing from a set of
+// values flagged as not ready with NaN.
+func TestTapStreamsNotReady(t *testing.T) {
+
+	taps, err := input.NewTapStreams(&notReadyMulti{
+		MultiStream: input.NewMultiListStream([]string{"a", "b"},
+			[][]float64{{1.0, 2.0}}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tap := range taps {
+
+		value, err := tap.Next()
+		if err != stream.ErrNotReady || !gomath.IsNaN(value) {
+			t.Fatalf("tap %d; expected NaN not ready, got %v, %v", i, value,
+				err)
+		}
+
+		value, err = tap.Next()
+		if err != nil || value != float64(i+1) {
+			t.Fatalf("tap %d; expected %d, got %v, %v", i, i+1, value, err)
+		}
+
+		tap.Close()
+
+	}
+
+}
@@ -1,7 +1,145 @@
 // Package main begins execution of the lapis server.
 package main
 
-// main configures and runs the lapis server.
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/bsladewski/lapis/dashboard"
+	"github.com/bsladewski/lapis/event"
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/metrics"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// main configures and runs the lapis server, or runs the command named by
+// the first argument.
 func main() {
 
+	if len(os.Args) > 1 && os.Args[1] == "watch" {
+		if err := runWatch(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	serve(os.Args[1:])
+
+}
+
+// serve runs the lapis server.
+func serve(args []string) {
+
+	flags := flag.NewFlagSet("lapis", flag.ExitOnError)
+	addr := flags.String("addr", ":8080",
+		"address to serve the dashboard and metrics on")
+	replay := flags.String("replay", "",
+		"coinbase candle file to replay through the dashboard")
+	interval := flags.Duration("interval", time.Second,
+		"delay between replayed candles")
+	flags.Parse(args)
+
+	d := dashboard.NewDashboard("lapis", 0, nil)
+
+	registry := metrics.NewRegistry()
+	registry.CollectWorkers(countWorkers)
+
+	if *replay != "" {
+		go func() {
+			err := replayCandles(d, registry, *replay, *interval)
+			if err != nil {
+				log.Printf("replay %s, err: %v", *replay, err)
+			}
+		}()
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", d)
+	mux.Handle("/metrics", registry)
+
+	server := &http.Server{Addr: *addr, Handler: mux}
+
+	// close event streams and shut the server down on interrupt
+	go func() {
+
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		<-interrupt
+
+		d.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(),
+			5*time.Second)
+		defer cancel()
+
+		if err := server.Shutdown(ctx); err != nil {
+			log.Printf("shutdown, err: %v", err)
+		}
+
+	}()
+
+	log.Printf("serving dashboard on %s", *addr)
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+
+}
+
+// replayCandles reads the close price of each candle in a coinbase candle
+// file at the specified interval, plotting the price and its moving average
+// on the dashboard and exporting their metrics.
+func replayCandles(d *dashboard.Dashboard, registry *metrics.Registry,
+	path string, interval time.Duration) error {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	candles, err := input.NewCoinbaseMockCandleStream(file)
+	if err != nil {
+		return err
+	}
+
+	closes := d.Attach("price", "close", registry.Instrument("close",
+		input.NewTimerStream(input.NewCandleFieldStream(candles,
+			input.FieldClose), interval)))
+
+	ma := registry.Gauge("ma", registry.Instrument("ma", d.Attach("price",
+		"ma", indicator.NewMAStream(closes, 20))))
+	defer ma.Close()
+
+	for {
+		if _, err := ma.Next(); err == stream.ErrEndOfStream {
+			return nil
+		} else if err != nil && err != stream.ErrNotReady {
+			return err
+		}
+	}
+
+}
+
+// countWorkers returns the number of event workers keyed by the name of their
+// state.
+func countWorkers() (map[string]int, error) {
+
+	counts, err := event.CountWorkers()
+	if err != nil {
+		return nil, err
+	}
+
+	named := make(map[string]int, len(counts))
+	for state, count := range counts {
+		named[state.String()] = count
+	}
+
+	return named, nil
+
 }
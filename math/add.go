@@ -30,16 +30,22 @@ func (a *add) Next() (float64, error) {
 	// the result of adding the input stream ouputs
 	var result float64
 
+	// notReady notes whether any input stream flagged its value as not ready
+	var notReady bool
+
 	// retrieve next value from all input streams, this should consume from
 	// each input stream on every call to Next regardless of errors returned
 	// by any given input stream
 	for _, is := range a.inputs {
 		value, err := is.Next()
-		if err != nil {
+		if err == stream.ErrNotReady {
+			notReady = true
+		} else if err != nil {
 			errs = append(errs, err)
-		} else {
-			result += value
+			continue
 		}
+
+		result += value
 	}
 
 	// if we are at the end of any stream, return an end of stream error
@@ -54,6 +60,11 @@ func (a *add) Next() (float64, error) {
 		return 0.0, errors.WithStack(err)
 	}
 
+	// if any input was not ready the result is not ready either
+	if notReady {
+		return result, stream.ErrNotReady
+	}
+
 	// return the result of the addition
 	return result, nil
 
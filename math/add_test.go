@@ -54,3 +54,55 @@ func TestAddStream(t *testing.T) {
 	}
 
 }
+
+// TestAddStreamNotReady tests that an add stream flags its output as not ready
+// when any input value is not ready.
+func TestAddStreamNotReady(t *testing.T) {
+
+	// create an input that flags every value as not ready
+	notReady := &notReadyStream{input.NewListStream([]float64{1.0, 2.0})}
+
+	// create the add stream
+	as := math.NewAddStream(input.NewListStream([]float64{3.0, 4.0}), notReady)
+	defer as.Close()
+
+	// assert that values are still added but flagged as not ready
+	for i, value := range []float64{4.0, 6.0} {
+
+		streamValue, err := as.Next()
+		if err != stream.ErrNotReady {
+			t.Fatalf("index %d; expected not ready error, got %v", i, err)
+		}
+
+		if util.CompareFloat(streamValue, value) != 0 {
+			t.Fatalf("index %d; expected %.2f, got %.2f", i, value, streamValue)
+		}
+
+	}
+
+	// assert that next results in end of stream error
+	if _, err := as.Next(); err != stream.ErrEndOfStream {
+		t.Fatalf("expected end of stream error, got %v", err)
+	}
+
+}
+
+// notReadyStream flags every value read from its input as not ready.
+type notReadyStream struct {
+	in stream.Stream
+}
+
+func (n *notReadyStream) Next() (float64, error) {
+
+	value, err := n.in.Next()
+	if err != nil {
+		return 0.0, err
+	}
+
+	return value, stream.ErrNotReady
+
+}
+
+func (n *notReadyStream) Close() {
+	n.in.Close()
+}
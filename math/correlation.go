@@ -0,0 +1,244 @@
+package math
+
+import (
+	gomath "math"
+	"sort"
+
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+	"github.com/pkg/errors"
+)
+
+// nextPair retrieves the next value from a pair of input streams; both streams
+// are consumed on every call regardless of errors returned by either stream.
+func nextPair(a, b stream.Stream) (float64, float64, error) {
+
+	x, errA := a.Next()
+	y, errB := b.Next()
+
+	// if we are at the end of either stream, return an end of stream error
+	for _, err := range []error{errA, errB} {
+		if errors.Cause(err) == stream.ErrEndOfStream {
+			return 0.0, 0.0, stream.ErrEndOfStream
+		}
+	}
+
+	// not ready values are returned alongside the not ready error
+	var notReady bool
+	if errA == stream.ErrNotReady {
+		errA, notReady = nil, true
+	}
+	if errB == stream.ErrNotReady {
+		errB, notReady = nil, true
+	}
+
+	// handle any other errors returned by input streams
+	for _, err := range []error{errA, errB} {
+		if err != nil {
+			return 0.0, 0.0, errors.WithStack(err)
+		}
+	}
+
+	if notReady {
+		return x, y, stream.ErrNotReady
+	}
+
+	return x, y, nil
+
+}
+
+// pairWarmUp returns the number of values a pair of input streams output before
+// both are ready.
+func pairWarmUp(a, b stream.Stream) int {
+
+	warmUp := inputWarmUp(a)
+	if other := inputWarmUp(b); other > warmUp {
+		warmUp = other
+	}
+
+	return warmUp
+
+}
+
+// correlation returns the Pearson correlation coefficient of the pairs in the
+// window, or 0 if either series has not varied.
+func correlation(w *util.ComomentWindow) float64 {
+
+	deviation := gomath.Sqrt(w.X().Variance() * w.Y().Variance())
+	if deviation == 0.0 {
+		return 0.0
+	}
+
+	// guard against rounding error pushing the coefficient out of range
+	return gomath.Max(-1.0, gomath.Min(1.0,
+		w.Covariance()/deviation))
+
+}
+
+// The statistics that may be output by a pair stream.
+const (
+	pairCovariance = iota
+	pairCorrelation
+	pairSpearman
+	pairBeta
+)
+
+// pair is the concrete implementation of a stream that outputs a statistic of
+// a sliding window of pairs of values read from two input streams.
+type pair struct {
+	period    int
+	statistic int
+	position  int
+	window    *util.ComomentWindow
+	a         stream.Stream
+	b         stream.Stream
+}
+
+// newPair returns a stream that outputs the specified statistic of pairs of
+// values read from two input streams.
+func newPair(a, b stream.Stream, period, statistic int) stream.Stream {
+
+	return &pair{
+		period:    period,
+		statistic: statistic,
+		window:    util.NewComomentWindow(period),
+		a:         a,
+		b:         b,
+	}
+
+}
+
+// NewCovarianceStream returns a stream that outputs the sample covariance of
+// two input streams over the specified period.
+func NewCovarianceStream(a, b stream.Stream, period int) stream.Stream {
+	return newPair(a, b, period, pairCovariance)
+}
+
+// NewCorrelationStream returns a stream that outputs the Pearson correlation
+// coefficient of two input streams over the specified period; the output is 0
+// while either input has not varied over the period.
+func NewCorrelationStream(a, b stream.Stream, period int) stream.Stream {
+	return newPair(a, b, period, pairCorrelation)
+}
+
+// NewSpearmanStream returns a stream that outputs the Spearman rank
+// correlation coefficient of two input streams over the specified period; tied
+// values receive the average of their ranks. The output is 0 while either input
+// has not varied over the period.
+func NewSpearmanStream(a, b stream.Stream, period int) stream.Stream {
+	return newPair(a, b, period, pairSpearman)
+}
+
+// NewBetaStream returns a stream that outputs the beta of an asset relative to
+// a benchmark over the specified period, the covariance of the two divided by
+// the variance of the benchmark; inputs are usually the returns of the asset
+// and benchmark. The output is 0 while the benchmark has not varied over the
+// period.
+func NewBetaStream(asset, benchmark stream.Stream, period int) stream.Stream {
+	return newPair(asset, benchmark, period, pairBeta)
+}
+
+func (p *pair) Next() (float64, error) {
+
+	if p.period <= 0 {
+		return 0.0, errors.New("statistics period cannot be negative or zero")
+	}
+
+	// retrieve the next pair of values
+	x, y, err := nextPair(p.a, p.b)
+	if err != nil && err != stream.ErrNotReady {
+		return 0.0, err
+	}
+
+	p.position++
+
+	if value, ok := passThrough(err, x, y); ok {
+		return value, err
+	}
+
+	p.window.Add(x, y)
+
+	return p.value(), readiness(p.position, p.WarmUp(),
+		p.window.Count() == p.period)
+
+}
+
+// value returns the statistic of the pairs in the window.
+func (p *pair) value() float64 {
+
+	switch p.statistic {
+	case pairCorrelation:
+		return correlation(p.window)
+	case pairSpearman:
+		return spearman(p.window.X().Values(), p.window.Y().Values())
+	case pairBeta:
+		variance := p.window.Y().Variance()
+		if variance == 0.0 {
+			return 0.0
+		}
+		return p.window.Covariance() / variance
+	}
+
+	return p.window.SampleCovariance()
+
+}
+
+func (p *pair) Close() {
+	p.a.Close()
+	p.b.Close()
+}
+
+func (p *pair) WarmUp() int {
+	return p.period - 1 + pairWarmUp(p.a, p.b)
+}
+
+// spearman returns the Spearman rank correlation coefficient of two series of
+// equal length.
+func spearman(xs, ys []float64) float64 {
+
+	w := util.NewComomentWindow(len(xs))
+
+	rankedX, rankedY := rank(xs), rank(ys)
+	for i := range rankedX {
+		w.Add(rankedX[i], rankedY[i])
+	}
+
+	return correlation(w)
+
+}
+
+// rank returns the rank of each value in a series starting from one; tied
+// values receive the average of their ranks.
+func rank(values []float64) []float64 {
+
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.Slice(order, func(i, j int) bool {
+		return values[order[i]] < values[order[j]]
+	})
+
+	ranks := make([]float64, len(values))
+	for i := 0; i < len(order); {
+
+		// find the run of values tied with the current value
+		j := i + 1
+		for j < len(order) && values[order[j]] == values[order[i]] {
+			j++
+		}
+
+		// ranks i+1 through j are shared by the tied values
+		average := float64(i+j+1) / 2.0
+		for k := i; k < j; k++ {
+			ranks[order[k]] = average
+		}
+
+		i = j
+
+	}
+
+	return ranks
+
+}
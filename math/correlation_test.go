@@ -0,0 +1,184 @@
+package math_test
+
+import (
+	gomath "math"
+	"sort"
+	"testing"
+
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/math"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// correlationOf returns the Pearson correlation coefficient of two series, or
+// 0 if either series has not varied.
+func correlationOf(xs, ys []float64) float64 {
+
+	deviation := gomath.Sqrt(covarianceOf(xs, xs) * covarianceOf(ys, ys))
+	if deviation == 0.0 {
+		return 0.0
+	}
+
+	return covarianceOf(xs, ys) / deviation
+
+}
+
+// ranksOf returns the rank of each value in a series by counting the values
+// less than and equal to it.
+func ranksOf(values []float64) []float64 {
+
+	ranks := make([]float64, len(values))
+	for i, value := range values {
+		var less, equal int
+		for _, other := range values {
+			if other < value {
+				less++
+			} else if other == value {
+				equal++
+			}
+		}
+		ranks[i] = float64(less) + float64(equal+1)/2.0
+	}
+
+	return ranks
+
+}
+
+// TestCorrelationStreams tests the covariance, correlation, Spearman and beta
+// streams against two-pass calculations over each window of input data.
+func TestCorrelationStreams(t *testing.T) {
+
+	period := 30
+	as := series(400, 0.0)
+	bs := series(400, 4.0)
+
+	// round one series so that the rank correlation has ties to resolve
+	for i := range bs {
+		bs[i] = gomath.Round(bs[i] / 5.0)
+	}
+
+	windowsA, windowsB := windowsOf(as, period), windowsOf(bs, period)
+
+	var covariances, correlations, spearmans, betas []float64
+	for i := range windowsA {
+
+		a, b := windowsA[i], windowsB[i]
+
+		beta := 0.0
+		if variance := covarianceOf(b, b); variance > 0.0 {
+			beta = covarianceOf(a, b) / variance
+		}
+
+		covariances = append(covariances, covarianceOf(a, b))
+		correlations = append(correlations, correlationOf(a, b))
+		spearmans = append(spearmans, correlationOf(ranksOf(a), ranksOf(b)))
+		betas = append(betas, beta)
+
+	}
+
+	cases := []struct {
+		name     string
+		create   func(a, b stream.Stream, period int) stream.Stream
+		expected []float64
+	}{
+		{"TestCovariance", math.NewCovarianceStream, covariances},
+		{"TestCorrelation", math.NewCorrelationStream, correlations},
+		{"TestSpearman", math.NewSpearmanStream, spearmans},
+		{"TestBeta", math.NewBetaStream, betas},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := c.create(input.NewListStream(as), input.NewListStream(bs),
+				period)
+			defer s.Close()
+			assertStream(t, s, c.expected, period-1)
+		})
+	}
+
+}
+
+// TestSpearmanMonotonic tests that the Spearman correlation of monotonically
+// related series is exactly 1 or -1.
+func TestSpearmanMonotonic(t *testing.T) {
+
+	as := series(50, 0.0)
+	sorted := append([]float64(nil), as...)
+	sort.Float64s(sorted)
+
+	// cubing preserves order so the rank correlation is perfect
+	cubes := make([]float64, len(sorted))
+	negated := make([]float64, len(sorted))
+	for i, value := range sorted {
+		cubes[i] = value * value * value
+		negated[i] = -value
+	}
+
+	for _, c := range []struct {
+		name     string
+		values   []float64
+		expected float64
+	}{
+		{"TestIncreasing", cubes, 1.0},
+		{"TestDecreasing", negated, -1.0},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+
+			s := math.NewSpearmanStream(input.NewListStream(sorted),
+				input.NewListStream(c.values), 10)
+			defer s.Close()
+
+			// skip the values before the window is full
+			for i := 0; i < 9; i++ {
+				if _, err := s.Next(); err != stream.ErrNotReady {
+					t.Fatalf("index %d; expected not ready error, got %v",
+						i, err)
+				}
+			}
+
+			for i := 9; i < len(sorted); i++ {
+				value, err := s.Next()
+				if err != nil {
+					t.Fatalf("index %d; err: %v", i, err)
+				}
+				if value != c.expected {
+					t.Fatalf("index %d; expected %v, got %v", i, c.expected,
+						value)
+				}
+			}
+
+		})
+	}
+
+}
+
+// TestCorrelationStreamNotReady tests that the provisional value of the first
+// input is passed through and that the warm-up of a correlation stream is that
+// of the slower input.
+func TestCorrelationStreamNotReady(t *testing.T) {
+
+	s := math.NewCovarianceStream(newWarmUpStream([]float64{4.0, 1.0, 2.0, 3.0},
+		1), newWarmUpStream([]float64{9.0, 8.0, 2.0, 6.0}, 2), 2)
+	defer s.Close()
+
+	assertStream(t, s, []float64{4.0, 1.0, 0.0, 2.0}, 3)
+
+}
+
+// TestCorrelationStreamEndOfStream tests that a correlation stream ends when
+// the shorter of its inputs ends.
+func TestCorrelationStreamEndOfStream(t *testing.T) {
+
+	s := math.NewCorrelationStream(input.NewListStream([]float64{1.0, 2.0}),
+		input.NewListStream([]float64{1.0}), 2)
+	defer s.Close()
+
+	if _, err := s.Next(); err != stream.ErrNotReady {
+		t.Fatalf("expected not ready error, got %v", err)
+	}
+
+	if _, err := s.Next(); err != stream.ErrEndOfStream {
+		t.Fatalf("expected end of stream error, got %v", err)
+	}
+
+}
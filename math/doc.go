@@ -1,3 +1,5 @@
-// Package math provides streams that apply simple mathematical operations and
-// mathematical functions to other streams.
+// Package math provides streams that apply simple mathematical operations,
+// mathematical functions and rolling statistics to other streams. Rolling
+// statistics flag their output as not ready until their window is full of
+// ready input and report the length of that warm-up, as indicators do.
 package math
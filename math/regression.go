@@ -0,0 +1,188 @@
+package math
+
+import (
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// RegressionSlope names the slope component of a regression stream.
+	RegressionSlope = "slope"
+	// RegressionIntercept names the intercept component of a regression
+	// stream.
+	RegressionIntercept = "intercept"
+	// RegressionR2 names the coefficient of determination component of a
+	// regression stream.
+	RegressionR2 = "r2"
+	// RegressionForecast names the forecast component of a regression stream.
+	RegressionForecast = "forecast"
+)
+
+// fit holds the result of an ordinary least squares fit of a window of pairs.
+type fit struct {
+	slope     float64
+	intercept float64
+	r2        float64
+}
+
+// fitWindow fits a line to the pairs in a window by ordinary least squares,
+// regressing y on x; the intercept is the fitted value of y where x is 0. If
+// x has not varied the fitted line is flat at the mean of y.
+func fitWindow(w *util.ComomentWindow) fit {
+
+	variance := w.X().Variance()
+	if variance == 0.0 {
+		return fit{intercept: w.Y().Mean()}
+	}
+
+	slope := w.Covariance() / variance
+	r := correlation(w)
+
+	return fit{
+		slope:     slope,
+		intercept: w.Y().Mean() - slope*w.X().Mean(),
+		r2:        r * r,
+	}
+
+}
+
+// regression is the concrete implementation of a multi stream that fits a line
+// to a sliding window of input data against time.
+type regression struct {
+	period   int
+	position int
+	count    float64
+	window   *util.ComomentWindow
+	in       stream.Stream
+}
+
+// NewRegressionStream returns a multi stream that fits a line to input data
+// over the specified period by ordinary least squares, where x is the position
+// of each value within the period. The intercept is the fitted value at the
+// oldest value in the period and the forecast is the fitted value one step
+// after the newest.
+func NewRegressionStream(in stream.Stream, period int) stream.MultiStream {
+
+	return &regression{
+		period: period,
+		window: util.NewComomentWindow(period),
+		in:     in,
+	}
+
+}
+
+func (r *regression) Components() []string {
+	return []string{RegressionSlope, RegressionIntercept, RegressionR2,
+		RegressionForecast}
+}
+
+func (r *regression) Next() ([]float64, error) {
+
+	if r.period <= 0 {
+		return nil, errors.New("regression period cannot be negative or zero")
+	}
+
+	// retrieve the next piece of input data
+	next, err := r.in.Next()
+	if err != nil && err != stream.ErrNotReady {
+		return nil, err
+	}
+
+	r.position++
+
+	if value, ok := passThrough(err, next); ok {
+		return []float64{value, value, value, value}, err
+	}
+
+	// x counts input values; the slope does not depend on where the count
+	// starts so only the count at the oldest value needs to be accounted for
+	r.window.Add(r.count, next)
+	r.count++
+
+	f := fitWindow(r.window)
+	oldest := r.window.X().Get(0)
+
+	return []float64{
+		f.slope,
+		f.intercept + f.slope*oldest,
+		f.r2,
+		f.intercept + f.slope*r.count,
+	}, readiness(r.position, r.WarmUp(), r.window.Count() == r.period)
+
+}
+
+func (r *regression) Close() {
+	r.in.Close()
+}
+
+func (r *regression) WarmUp() int {
+	return r.period - 1 + inputWarmUp(r.in)
+}
+
+// pairRegression is the concrete implementation of a multi stream that fits a
+// line to a sliding window of pairs of values read from two input streams.
+type pairRegression struct {
+	period   int
+	position int
+	window   *util.ComomentWindow
+	y        stream.Stream
+	x        stream.Stream
+}
+
+// NewPairRegressionStream returns a multi stream that regresses y on x over
+// the specified period by ordinary least squares. The intercept is the fitted
+// value of y where x is 0 and the forecast is the fitted value of y at the
+// newest value of x.
+func NewPairRegressionStream(y, x stream.Stream,
+	period int) stream.MultiStream {
+
+	return &pairRegression{
+		period: period,
+		window: util.NewComomentWindow(period),
+		y:      y,
+		x:      x,
+	}
+
+}
+
+func (p *pairRegression) Components() []string {
+	return []string{RegressionSlope, RegressionIntercept, RegressionR2,
+		RegressionForecast}
+}
+
+func (p *pairRegression) Next() ([]float64, error) {
+
+	if p.period <= 0 {
+		return nil, errors.New("regression period cannot be negative or zero")
+	}
+
+	// retrieve the next pair of values
+	y, x, err := nextPair(p.y, p.x)
+	if err != nil && err != stream.ErrNotReady {
+		return nil, err
+	}
+
+	p.position++
+
+	if value, ok := passThrough(err, y, x); ok {
+		return []float64{value, value, value, value}, err
+	}
+
+	p.window.Add(x, y)
+
+	f := fitWindow(p.window)
+
+	return []float64{f.slope, f.intercept, f.r2, f.intercept + f.slope*x},
+		readiness(p.position, p.WarmUp(), p.window.Count() == p.period)
+
+}
+
+func (p *pairRegression) Close() {
+	p.y.Close()
+	p.x.Close()
+}
+
+func (p *pairRegression) WarmUp() int {
+	return p.period - 1 + pairWarmUp(p.y, p.x)
+}
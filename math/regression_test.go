@@ -0,0 +1,169 @@
+package math_test
+
+import (
+	"testing"
+
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/math"
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// fitOf fits a line to two series by ordinary least squares, returning the
+// slope, the intercept where x is 0 and the coefficient of determination.
+func fitOf(xs, ys []float64) (float64, float64, float64) {
+
+	variance := covarianceOf(xs, xs)
+	if variance == 0.0 {
+		return 0.0, meanOf(ys), 0.0
+	}
+
+	slope := covarianceOf(xs, ys) / variance
+	intercept := meanOf(ys) - slope*meanOf(xs)
+
+	// the coefficient of determination from the residual sum of squares
+	var residual, total float64
+	for i := range xs {
+		fitted := intercept + slope*xs[i]
+		residual += (ys[i] - fitted) * (ys[i] - fitted)
+		total += (ys[i] - meanOf(ys)) * (ys[i] - meanOf(ys))
+	}
+
+	r2 := 0.0
+	if total > 0.0 {
+		r2 = 1.0 - residual/total
+	}
+
+	return slope, intercept, r2
+
+}
+
+// assertMultiStream asserts that a multi stream outputs the expected sets of
+// values followed by an end of stream error; values before the warm-up must be
+// flagged as not ready and values from the warm-up on must not.
+func assertMultiStream(t *testing.T, s stream.MultiStream,
+	expected [][]float64, warmUp int) {
+
+	t.Helper()
+
+	assertWarmUp(t, s, warmUp)
+
+	components := s.Components()
+
+	for i, values := range expected {
+
+		streamValues, err := s.Next()
+		if i < warmUp && err != stream.ErrNotReady {
+			t.Fatalf("index %d; expected not ready error, got %v", i, err)
+		} else if i >= warmUp && err != nil {
+			t.Fatalf("index %d; err: %v", i, err)
+		}
+
+		for j, value := range values {
+			if util.CompareFloat(streamValues[j], value) != 0 {
+				t.Fatalf("index %d, %s; expected %v, got %v", i,
+					components[j], value, streamValues[j])
+			}
+		}
+
+	}
+
+	if _, err := s.Next(); err != stream.ErrEndOfStream {
+		t.Fatalf("expected end of stream error, got %v", err)
+	}
+
+}
+
+// TestRegressionStream tests fitting a line to each window of input data
+// against a direct least squares calculation.
+func TestRegressionStream(t *testing.T) {
+
+	period := 14
+	values := series(300, 0.0)
+
+	var expected [][]float64
+	for _, window := range windowsOf(values, period) {
+
+		xs := make([]float64, len(window))
+		for i := range xs {
+			xs[i] = float64(i)
+		}
+
+		slope, intercept, r2 := fitOf(xs, window)
+		forecast := intercept + slope*float64(len(window))
+
+		expected = append(expected,
+			[]float64{slope, intercept, r2, forecast})
+
+	}
+
+	s := math.NewRegressionStream(input.NewListStream(values), period)
+	defer s.Close()
+
+	assertMultiStream(t, s, expected, period-1)
+
+}
+
+// TestRegressionStreamLine tests that a regression stream recovers a straight
+// line exactly.
+func TestRegressionStreamLine(t *testing.T) {
+
+	values := []float64{3.0, 5.0, 7.0, 9.0, 11.0}
+
+	expected := [][]float64{
+		{0.0, 3.0, 0.0, 3.0},
+		{2.0, 3.0, 1.0, 7.0},
+		{2.0, 3.0, 1.0, 9.0},
+		{2.0, 5.0, 1.0, 11.0},
+		{2.0, 7.0, 1.0, 13.0},
+	}
+
+	s := math.NewRegressionStream(input.NewListStream(values), 3)
+	defer s.Close()
+
+	assertMultiStream(t, s, expected, 2)
+
+}
+
+// TestPairRegressionStream tests regressing one input on another against a
+// direct least squares calculation.
+func TestPairRegressionStream(t *testing.T) {
+
+	period := 25
+	ys := series(300, 0.0)
+	xs := series(300, 2.0)
+
+	windowsY, windowsX := windowsOf(ys, period), windowsOf(xs, period)
+
+	var expected [][]float64
+	for i := range windowsY {
+		slope, intercept, r2 := fitOf(windowsX[i], windowsY[i])
+		expected = append(expected,
+			[]float64{slope, intercept, r2, intercept + slope*xs[i]})
+	}
+
+	s := math.NewPairRegressionStream(input.NewListStream(ys),
+		input.NewListStream(xs), period)
+	defer s.Close()
+
+	assertMultiStream(t, s, expected, period-1)
+
+}
+
+// TestRegressionStreamNotReady tests that provisional input is passed through
+// every component and that the warm-up of a regression stream includes the
+// warm-up of its input.
+func TestRegressionStreamNotReady(t *testing.T) {
+
+	s := math.NewRegressionStream(newWarmUpStream(
+		[]float64{8.0, 3.0, 5.0, 7.0}, 1), 3)
+	defer s.Close()
+
+	assertMultiStream(t, s, [][]float64{
+		{8.0, 8.0, 8.0, 8.0},
+		{0.0, 3.0, 0.0, 3.0},
+		{2.0, 3.0, 1.0, 7.0},
+		{2.0, 3.0, 1.0, 9.0},
+	}, 3)
+
+}
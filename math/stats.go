@@ -0,0 +1,164 @@
+package math
+
+import (
+	gomath "math"
+
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+	"github.com/pkg/errors"
+)
+
+// The statistics that may be output by a stats stream.
+const (
+	statMean = iota
+	statVariance
+	statZScore
+)
+
+// inputWarmUp returns the number of values an input stream outputs before it
+// is ready, or 0 if the input does not report a warm-up.
+func inputWarmUp(in stream.Stream) int {
+
+	if w, ok := in.(interface{ WarmUp() int }); ok {
+		return w.WarmUp()
+	}
+
+	return 0
+
+}
+
+// passThrough reports whether the values read from the inputs of a rolling
+// statistic are not ready, either flagged by a not ready error or marked with
+// NaN, and returns the value to output in their place: the provisional value of
+// the first input when flagged, otherwise NaN. Values that are not ready are
+// never added to a window, where they would poison the running statistics.
+func passThrough(err error, values ...float64) (float64, bool) {
+
+	if err == stream.ErrNotReady {
+		return values[0], true
+	}
+
+	for _, value := range values {
+		if gomath.IsNaN(value) {
+			return gomath.NaN(), true
+		}
+	}
+
+	return 0.0, false
+
+}
+
+// readiness returns a not ready error until a rolling statistic has read past
+// its warm-up and its window is full; position counts every value read,
+// including values that are not ready.
+func readiness(position, warmUp int, full bool) error {
+
+	if position <= warmUp || !full {
+		return stream.ErrNotReady
+	}
+
+	return nil
+
+}
+
+// stats is the concrete implementation of a stream that outputs a statistic
+// of a sliding window of input data.
+type stats struct {
+	period    int
+	statistic int
+	position  int
+	window    *util.WelfordWindow
+	in        stream.Stream
+}
+
+// NewMeanStream returns a stream that outputs the mean of input data over the
+// specified period.
+func NewMeanStream(in stream.Stream, period int) stream.Stream {
+
+	return &stats{
+		period:    period,
+		statistic: statMean,
+		window:    util.NewWelfordWindow(period),
+		in:        in,
+	}
+
+}
+
+// NewVarianceStream returns a stream that outputs the sample variance of input
+// data over the specified period.
+func NewVarianceStream(in stream.Stream, period int) stream.Stream {
+
+	return &stats{
+		period:    period,
+		statistic: statVariance,
+		window:    util.NewWelfordWindow(period),
+		in:        in,
+	}
+
+}
+
+// NewZScoreStream returns a stream that outputs the number of sample standard
+// deviations between each input value and the mean of input data over the
+// specified period, including the value itself; the output is 0 while the
+// input has not varied over the period.
+func NewZScoreStream(in stream.Stream, period int) stream.Stream {
+
+	return &stats{
+		period:    period,
+		statistic: statZScore,
+		window:    util.NewWelfordWindow(period),
+		in:        in,
+	}
+
+}
+
+func (s *stats) Next() (float64, error) {
+
+	if s.period <= 0 {
+		return 0.0, errors.New("statistics period cannot be negative or zero")
+	}
+
+	// retrieve the next piece of input data
+	next, err := s.in.Next()
+	if err != nil && err != stream.ErrNotReady {
+		return 0.0, err
+	}
+
+	s.position++
+
+	if value, ok := passThrough(err, next); ok {
+		return value, err
+	}
+
+	s.window.Add(next)
+
+	return s.value(next), readiness(s.position, s.WarmUp(),
+		s.window.Count() == s.period)
+
+}
+
+// value returns the statistic of the window for the newest input value.
+func (s *stats) value(next float64) float64 {
+
+	switch s.statistic {
+	case statVariance:
+		return s.window.SampleVariance()
+	case statZScore:
+		deviation := gomath.Sqrt(s.window.SampleVariance())
+		if deviation == 0.0 {
+			return 0.0
+		}
+		return (next - s.window.Mean()) / deviation
+	}
+
+	return s.window.Mean()
+
+}
+
+func (s *stats) Close() {
+	s.in.Close()
+}
+
+func (s *stats) WarmUp() int {
+	return s.period - 1 + inputWarmUp(s.in)
+}
@@ -0,0 +1,256 @@
+package math_test
+
+import (
+	gomath "math"
+	"testing"
+
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/math"
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// series returns a deterministic series of input data with some noise and a
+// drift, shifted by the specified phase.
+func series(size int, phase float64) []float64 {
+
+	values := make([]float64, size)
+	for i := range values {
+		x := float64(i) + phase
+		values[i] = 100.0 + 0.3*x + 10.0*gomath.Sin(x/3.0) +
+			3.0*gomath.Cos(x*1.7)
+	}
+
+	return values
+
+}
+
+// meanOf returns the mean of a series.
+func meanOf(values []float64) float64 {
+
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+
+	return sum / float64(len(values))
+
+}
+
+// covarianceOf returns the sample covariance of two series using the two-pass
+// algorithm, or 0 for a single pair.
+func covarianceOf(xs, ys []float64) float64 {
+
+	if len(xs) < 2 {
+		return 0.0
+	}
+
+	mx, my := meanOf(xs), meanOf(ys)
+
+	var sum float64
+	for i := range xs {
+		sum += (xs[i] - mx) * (ys[i] - my)
+	}
+
+	return sum / float64(len(xs)-1)
+
+}
+
+// windowsOf returns the sliding windows of a series for the specified period,
+// including the partial windows at the start of the series.
+func windowsOf(values []float64, period int) [][]float64 {
+
+	windows := make([][]float64, len(values))
+	for i := range values {
+		start := i - period + 1
+		if start < 0 {
+			start = 0
+		}
+		windows[i] = values[start : i+1]
+	}
+
+	return windows
+
+}
+
+// warmUpStream is a list stream that flags the values before its warm-up as not
+// ready, as an indicator does.
+type warmUpStream struct {
+	warmUp   int
+	position int
+	in       stream.Stream
+}
+
+// newWarmUpStream returns a stream that outputs the specified values, flagging
+// the first warmUp values as not ready.
+func newWarmUpStream(values []float64, warmUp int) stream.Stream {
+	return &warmUpStream{warmUp: warmUp, in: input.NewListStream(values)}
+}
+
+func (w *warmUpStream) Next() (float64, error) {
+
+	next, err := w.in.Next()
+	if err != nil {
+		return next, err
+	}
+
+	w.position++
+	if w.position <= w.warmUp {
+		return next, stream.ErrNotReady
+	}
+
+	return next, nil
+
+}
+
+func (w *warmUpStream) Close() {
+	w.in.Close()
+}
+
+func (w *warmUpStream) WarmUp() int {
+	return w.warmUp
+}
+
+// assertWarmUp asserts that a stream reports the expected warm-up.
+func assertWarmUp(t *testing.T, s interface{}, expected int) {
+
+	t.Helper()
+
+	w, ok := s.(interface{ WarmUp() int })
+	if !ok {
+		t.Fatal("expected stream to report a warm-up")
+	}
+
+	if w.WarmUp() != expected {
+		t.Fatalf("expected warm-up %d, got %d", expected, w.WarmUp())
+	}
+
+}
+
+// assertStream asserts that a stream outputs the expected values followed by
+// an end of stream error; values before the warm-up must be flagged as not
+// ready and values from the warm-up on must not.
+func assertStream(t *testing.T, s stream.Stream, expected []float64,
+	warmUp int) {
+
+	t.Helper()
+
+	assertWarmUp(t, s, warmUp)
+
+	for i, value := range expected {
+
+		streamValue, err := s.Next()
+		if i < warmUp && err != stream.ErrNotReady {
+			t.Fatalf("index %d; expected not ready error, got %v", i, err)
+		} else if i >= warmUp && err != nil {
+			t.Fatalf("index %d; err: %v", i, err)
+		}
+
+		if util.CompareFloat(streamValue, value) != 0 {
+			t.Fatalf("index %d; expected %v, got %v", i, value, streamValue)
+		}
+
+	}
+
+	if _, err := s.Next(); err != stream.ErrEndOfStream {
+		t.Fatalf("expected end of stream error, got %v", err)
+	}
+
+}
+
+// TestStatsStreams tests the mean, variance and z-score streams against two-pass
+// calculations over each window of input data.
+func TestStatsStreams(t *testing.T) {
+
+	period := 20
+	values := series(500, 0.0)
+
+	var means, variances, zScores []float64
+	for i, window := range windowsOf(values, period) {
+
+		mean := meanOf(window)
+		variance := covarianceOf(window, window)
+
+		zScore := 0.0
+		if variance > 0.0 {
+			zScore = (values[i] - mean) / gomath.Sqrt(variance)
+		}
+
+		means = append(means, mean)
+		variances = append(variances, variance)
+		zScores = append(zScores, zScore)
+
+	}
+
+	t.Run("TestMean", func(t *testing.T) {
+		s := math.NewMeanStream(input.NewListStream(values), period)
+		defer s.Close()
+		assertStream(t, s, means, period-1)
+	})
+
+	t.Run("TestVariance", func(t *testing.T) {
+		s := math.NewVarianceStream(input.NewListStream(values), period)
+		defer s.Close()
+		assertStream(t, s, variances, period-1)
+	})
+
+	t.Run("TestZScore", func(t *testing.T) {
+		s := math.NewZScoreStream(input.NewListStream(values), period)
+		defer s.Close()
+		assertStream(t, s, zScores, period-1)
+	})
+
+}
+
+// TestStatsStreamNaN tests that NaN input is passed through without affecting
+// the running statistics; the mean is not ready until its window is full.
+func TestStatsStreamNaN(t *testing.T) {
+
+	s := math.NewMeanStream(input.NewListStream(
+		[]float64{gomath.NaN(), 2.0, gomath.NaN(), 4.0}), 2)
+	defer s.Close()
+
+	expected := []float64{gomath.NaN(), 2.0, gomath.NaN(), 3.0}
+	expectedErrors := []error{nil, stream.ErrNotReady, nil, nil}
+
+	for i, value := range expected {
+
+		streamValue, err := s.Next()
+		if err != expectedErrors[i] {
+			t.Fatalf("index %d; expected error %v, got %v", i,
+				expectedErrors[i], err)
+		}
+
+		if gomath.IsNaN(value) != gomath.IsNaN(streamValue) ||
+			!gomath.IsNaN(value) && util.CompareFloat(streamValue, value) != 0 {
+			t.Fatalf("index %d; expected %v, got %v", i, value, streamValue)
+		}
+
+	}
+
+}
+
+// TestStatsStreamNotReady tests that provisional input is passed through and
+// that the warm-up of a stats stream includes the warm-up of its input.
+func TestStatsStreamNotReady(t *testing.T) {
+
+	s := math.NewMeanStream(newWarmUpStream(
+		[]float64{5.0, 7.0, 2.0, 4.0, 6.0}, 2), 2)
+	defer s.Close()
+
+	assertStream(t, s, []float64{5.0, 7.0, 2.0, 3.0, 5.0}, 3)
+
+}
+
+// TestStatsStreamPeriod tests that a stats stream with an invalid period
+// returns an error.
+func TestStatsStreamPeriod(t *testing.T) {
+
+	s := math.NewVarianceStream(input.NewListStream([]float64{1.0}), 0)
+	defer s.Close()
+
+	if _, err := s.Next(); err == nil {
+		t.Fatal("expected error for zero period")
+	}
+
+}
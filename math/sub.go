@@ -37,14 +37,22 @@ func (s *sub) Next() (float64, error) {
 	// rather than subtracting from it
 	var firstStream = true
 
+	// notReady notes whether any input stream flagged its value as not ready
+	var notReady bool
+
 	// retrieve next value from all input streams, this should consume from
 	// each input stream on every call to Next regardless of errors returned
 	// by any given input stream
 	for _, is := range s.inputs {
 		value, err := is.Next()
-		if err != nil {
+		if err == stream.ErrNotReady {
+			notReady = true
+		} else if err != nil {
 			errs = append(errs, err)
-		} else if firstStream {
+			continue
+		}
+
+		if firstStream {
 			result = value
 			firstStream = false
 		} else {
@@ -64,6 +72,11 @@ func (s *sub) Next() (float64, error) {
 		return 0.0, errors.WithStack(err)
 	}
 
+	// if any input was not ready the result is not ready either
+	if notReady {
+		return result, stream.ErrNotReady
+	}
+
 	// return the result of the addition
 	return result, nil
 
@@ -0,0 +1,6 @@
+// Package metrics exports measurements of running pipelines and event workers
+// in the Prometheus text exposition format. Streams are instrumented by
+// wrapping them, recording the values read, the latency of each call to Next
+// and the errors returned, and selected streams may be exported as gauges of
+// their latest value.
+package metrics
@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	gomath "math"
+	"sync/atomic"
+
+	"github.com/bsladewski/lapis/output"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// A gauge holds the latest value of a stream.
+type gauge struct {
+	bits atomic.Uint64
+}
+
+// newGauge returns a gauge without a value.
+func newGauge() *gauge {
+
+	g := &gauge{}
+	g.set(gomath.NaN())
+
+	return g
+
+}
+
+func (g *gauge) set(value float64) {
+	g.bits.Store(gomath.Float64bits(value))
+}
+
+func (g *gauge) value() float64 {
+	return gomath.Float64frombits(g.bits.Load())
+}
+
+// gaugeOutput is an output that exports the latest value of a stream.
+type gaugeOutput struct {
+	gauge *gauge
+	in    stream.Stream
+}
+
+// Gauge returns an output that passes values through while exporting the
+// latest value as a gauge with the specified name; GetData returns the latest
+// value, or NaN if no value is ready. Values that are not ready are not
+// exported.
+func (r *Registry) Gauge(name string, in stream.Stream) output.Output[float64] {
+
+	return &gaugeOutput{
+		gauge: r.gauge(name),
+		in:    in,
+	}
+
+}
+
+func (g *gaugeOutput) Next() (float64, error) {
+
+	value, err := g.in.Next()
+	if err == nil {
+		g.gauge.set(value)
+	}
+
+	return value, err
+
+}
+
+func (g *gaugeOutput) GetData() float64 {
+	return g.gauge.value()
+}
+
+func (g *gaugeOutput) Close() {
+	g.in.Close()
+}
+
+// multiGaugeOutput is an output that exports the latest value of each
+// component of a multi stream.
+type multiGaugeOutput struct {
+	components []string
+	gauges     []*gauge
+	in         stream.MultiStream
+}
+
+// MultiGauge returns an output that passes sets of values through while
+// exporting the latest value of each component as a gauge named by joining
+// the specified name and the component with a dot; GetData returns the
+// latest value of each component.
+func (r *Registry) MultiGauge(name string,
+	in stream.MultiStream) output.MultiOutput[map[string]float64] {
+
+	g := &multiGaugeOutput{
+		components: in.Components(),
+		in:         in,
+	}
+
+	for _, component := range g.components {
+		g.gauges = append(g.gauges, r.gauge(name+"."+component))
+	}
+
+	return g
+
+}
+
+func (g *multiGaugeOutput) Components() []string {
+	return g.components
+}
+
+func (g *multiGaugeOutput) Next() ([]float64, error) {
+
+	values, err := g.in.Next()
+	if err == nil {
+		for i, value := range values {
+			if i < len(g.gauges) {
+				g.gauges[i].set(value)
+			}
+		}
+	}
+
+	return values, err
+
+}
+
+func (g *multiGaugeOutput) GetData() map[string]float64 {
+
+	data := map[string]float64{}
+	for i, component := range g.components {
+		data[component] = g.gauges[i].value()
+	}
+
+	return data
+
+}
+
+func (g *multiGaugeOutput) Close() {
+	g.in.Close()
+}
@@ -0,0 +1,71 @@
+package metrics_test
+
+import (
+	gomath "math"
+	"testing"
+
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/metrics"
+	"github.com/bsladewski/lapis/stream"
+)
+
+func TestGauge(t *testing.T) {
+
+	r := metrics.NewRegistry()
+
+	out := r.Gauge("close", input.NewListStream([]float64{1, 2.5}))
+	defer out.Close()
+
+	if value := out.GetData(); !gomath.IsNaN(value) {
+		t.Fatalf("expected no value, got %f", value)
+	}
+
+	assertLines(t, scrape(t, r), `lapis_output_value{output="close"} NaN`)
+
+	for i := 0; i < 2; i++ {
+		if _, err := out.Next(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the last value is kept at the end of the stream
+	if _, err := out.Next(); err != stream.ErrEndOfStream {
+		t.Fatalf("expected end of stream, got %v", err)
+	}
+
+	if value := out.GetData(); value != 2.5 {
+		t.Fatalf("expected 2.5, got %f", value)
+	}
+
+	assertLines(t, scrape(t, r),
+		"# TYPE lapis_output_value gauge",
+		`lapis_output_value{output="close"} 2.5`,
+	)
+
+}
+
+func TestMultiGauge(t *testing.T) {
+
+	r := metrics.NewRegistry()
+
+	out := r.MultiGauge("bands", input.NewMultiListStream(
+		[]string{"upper", "lower"}, [][]float64{{2, 1}, {3, 2}}))
+	defer out.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := out.Next(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data := out.GetData()
+	if data["upper"] != 3 || data["lower"] != 2 {
+		t.Fatalf("unexpected data: %v", data)
+	}
+
+	assertLines(t, scrape(t, r),
+		`lapis_output_value{output="bands.lower"} 2`,
+		`lapis_output_value{output="bands.upper"} 3`,
+	)
+
+}
@@ -0,0 +1,240 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	gomath "math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// contentType is the content type of the Prometheus text exposition format.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// A Registry holds the metrics of instrumented streams and gauges; it is an
+// HTTP handler that serves the metrics to Prometheus.
+type Registry struct {
+	mu      sync.Mutex
+	streams map[string]*streamMetrics
+	gauges  map[string]*gauge
+	workers func() (map[string]int, error)
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+
+	return &Registry{
+		streams: map[string]*streamMetrics{},
+		gauges:  map[string]*gauge{},
+	}
+
+}
+
+// CollectWorkers exports the number of event workers in each state, counted
+// by the supplied function each time the metrics are served; the function
+// returns the number of workers keyed by the name of their state, e.g. by
+// adapting event.CountWorkers.
+func (r *Registry) CollectWorkers(count func() (map[string]int, error)) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.workers = count
+
+}
+
+// stream returns the metrics of the named stream, creating them if the name
+// has not been registered; streams instrumented with the same name share
+// metrics.
+func (r *Registry) stream(name string) *streamMetrics {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.streams[name]
+	if !ok {
+		m = newStreamMetrics()
+		r.streams[name] = m
+	}
+
+	return m
+
+}
+
+// gauge returns the named gauge, creating it if the name has not been
+// registered.
+func (r *Registry) gauge(name string) *gauge {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	g, ok := r.gauges[name]
+	if !ok {
+		g = newGauge()
+		r.gauges[name] = g
+	}
+
+	return g
+
+}
+
+// ServeHTTP writes every metric in the Prometheus text exposition format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+
+	r.mu.Lock()
+	countWorkers := r.workers
+	r.mu.Unlock()
+
+	// count workers before writing so that a failure can be reported
+	var workers map[string]int
+	if countWorkers != nil {
+		var err error
+		if workers, err = countWorkers(); err != nil {
+			http.Error(w, fmt.Sprintf("count workers, err: %v", err),
+				http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+
+	buf := bufio.NewWriter(w)
+	r.write(buf, workers)
+	buf.Flush()
+
+}
+
+// write writes every metric along with the supplied worker counts.
+func (r *Registry) write(w io.Writer, workers map[string]int) {
+
+	// copy the registered metrics so that streams may be instrumented while
+	// metrics are written
+	r.mu.Lock()
+	streams := sortedNames(r.streams)
+	streamStats := make([]*streamMetrics, len(streams))
+	for i, name := range streams {
+		streamStats[i] = r.streams[name]
+	}
+	gauges := sortedNames(r.gauges)
+	gaugeValues := make([]float64, len(gauges))
+	for i, name := range gauges {
+		gaugeValues[i] = r.gauges[name].value()
+	}
+	r.mu.Unlock()
+
+	if len(streams) > 0 {
+
+		header(w, "lapis_stream_values_total", "counter",
+			"Values read from instrumented streams.")
+		for i, name := range streams {
+			sample(w, "lapis_stream_values_total",
+				float64(streamStats[i].values.Load()), "stream", name)
+		}
+
+		header(w, "lapis_stream_errors_total", "counter",
+			"Errors returned by instrumented streams by type.")
+		for i, name := range streams {
+			for _, count := range streamStats[i].errorCounts() {
+				sample(w, "lapis_stream_errors_total", float64(count.count),
+					"stream", name, "type", count.kind)
+			}
+		}
+
+		header(w, "lapis_stream_next_seconds", "histogram",
+			"Latency of calls to Next on instrumented streams.")
+		for i, name := range streams {
+			streamStats[i].latency.write(w, "lapis_stream_next_seconds",
+				"stream", name)
+		}
+
+	}
+
+	if len(gauges) > 0 {
+		header(w, "lapis_output_value", "gauge",
+			"Latest value of exported outputs.")
+		for i, name := range gauges {
+			sample(w, "lapis_output_value", gaugeValues[i], "output", name)
+		}
+	}
+
+	if workers != nil {
+
+		header(w, "lapis_event_workers", "gauge",
+			"Event workers by state.")
+		for _, state := range sortedNames(workers) {
+			sample(w, "lapis_event_workers", float64(workers[state]),
+				"state", state)
+		}
+
+	}
+
+}
+
+// sortedNames returns the keys of a map in ascending order.
+func sortedNames[T any](m map[string]T) []string {
+
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+
+}
+
+// header writes the help and type lines of a metric.
+func header(w io.Writer, name, kind, help string) {
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+
+}
+
+// sample writes a sample of a metric with labels supplied as pairs of names
+// and values.
+func sample(w io.Writer, name string, value float64, labels ...string) {
+
+	fmt.Fprintf(w, "%s%s %s\n", name, formatLabels(labels),
+		formatValue(value))
+
+}
+
+// formatLabels formats pairs of label names and values.
+func formatLabels(labels []string) string {
+
+	if len(labels) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, labels[i],
+			labelEscaper.Replace(labels[i+1])))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+
+}
+
+// labelEscaper escapes label values.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatValue formats a sample value.
+func formatValue(value float64) string {
+
+	switch {
+	case gomath.IsNaN(value):
+		return "NaN"
+	case gomath.IsInf(value, 1):
+		return "+Inf"
+	case gomath.IsInf(value, -1):
+		return "-Inf"
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, 64)
+
+}
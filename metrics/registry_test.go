@@ -0,0 +1,242 @@
+package metrics_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/metrics"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// failing returns an error after the values of a list stream.
+type failing struct {
+	stream.Stream
+}
+
+func (s *failing) Next() (float64, error) {
+
+	value, err := s.Stream.Next()
+	if err == stream.ErrEndOfStream {
+		return 0.0, errors.New("failed")
+	}
+
+	return value, err
+
+}
+
+// scrape returns the metrics served by a registry.
+func scrape(t *testing.T, r *metrics.Registry) string {
+
+	t.Helper()
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status: %d", resp.StatusCode)
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(
+		contentType, "text/plain; version=0.0.4") {
+		t.Fatalf("unexpected content type: %s", contentType)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(body)
+
+}
+
+// assertLines asserts that metrics contain each of the expected lines.
+func assertLines(t *testing.T, body string, expected ...string) {
+
+	t.Helper()
+
+	lines := map[string]bool{}
+	for _, line := range strings.Split(body, "\n") {
+		lines[line] = true
+	}
+
+	for _, line := range expected {
+		if !lines[line] {
+			t.Errorf("expected line %q in:\n%s", line, body)
+		}
+	}
+
+}
+
+func TestInstrument(t *testing.T) {
+
+	r := metrics.NewRegistry()
+
+	s := r.Instrument(`close "btc"`, &failing{
+		Stream: input.NewListStream([]float64{1, 2, 3}),
+	})
+	defer s.Close()
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.Next(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := s.Next(); err == nil {
+		t.Fatal("expected error")
+	}
+
+	candles := r.InstrumentCandles("candles", input.NewCandleListStream(nil))
+	if _, err := candles.Next(); err != stream.ErrEndOfStream {
+		t.Fatalf("expected end of stream, got %v", err)
+	}
+
+	body := scrape(t, r)
+
+	assertLines(t, body,
+		"# TYPE lapis_stream_values_total counter",
+		`lapis_stream_values_total{stream="close \"btc\""} 3`,
+		`lapis_stream_values_total{stream="candles"} 0`,
+		"# TYPE lapis_stream_errors_total counter",
+		`lapis_stream_errors_total{stream="close \"btc\"",`+
+			`type="error"} 1`,
+		`lapis_stream_errors_total{stream="candles",`+
+			`type="end_of_stream"} 1`,
+		"# TYPE lapis_stream_next_seconds histogram",
+		`lapis_stream_next_seconds_bucket{stream="close \"btc\"",`+
+			`le="+Inf"} 4`,
+		`lapis_stream_next_seconds_count{stream="close \"btc\""} 4`,
+		`lapis_stream_next_seconds_count{stream="candles"} 1`,
+	)
+
+	// buckets are cumulative
+	if !strings.Contains(body, `lapis_stream_next_seconds_bucket{stream=`+
+		`"candles",le="10"} 1`) {
+		t.Errorf("expected every call in the largest bucket:\n%s", body)
+	}
+
+}
+
+func TestInstrumentMulti(t *testing.T) {
+
+	r := metrics.NewRegistry()
+
+	s := r.InstrumentMulti("bands", input.NewMultiListStream(
+		[]string{"upper", "lower"}, [][]float64{{2, 1}, {3, 2}}))
+	defer s.Close()
+
+	if components := s.Components(); len(components) != 2 {
+		t.Fatalf("unexpected components: %v", components)
+	}
+
+	for {
+		if _, err := s.Next(); err == stream.ErrEndOfStream {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assertLines(t, scrape(t, r),
+		`lapis_stream_values_total{stream="bands"} 2`,
+		`lapis_stream_errors_total{stream="bands",type="end_of_stream"} 1`,
+	)
+
+}
+
+// erring returns the same error from every call to Next.
+type erring struct {
+	stream.Stream
+	err error
+}
+
+func (s *erring) Next() (float64, error) {
+	return 0.0, s.err
+}
+
+func TestInstrumentErrorKinds(t *testing.T) {
+
+	_, parseErr := strconv.ParseFloat("price", 64)
+
+	r := metrics.NewRegistry()
+
+	for name, err := range map[string]error{
+		"plain":   errors.New("failed"),
+		"parse":   fmt.Errorf("read close, err: %w", parseErr),
+		"stopped": fmt.Errorf("replay, err: %w", stream.ErrEndOfStream),
+	} {
+		s := r.Instrument(name, &erring{
+			Stream: input.NewListStream(nil),
+			err:    err,
+		})
+		s.Next()
+	}
+
+	// errors are labelled by the first exported type they wrap
+	assertLines(t, scrape(t, r),
+		`lapis_stream_errors_total{stream="parse",`+
+			`type="*strconv.NumError"} 1`,
+		`lapis_stream_errors_total{stream="plain",type="error"} 1`,
+		`lapis_stream_errors_total{stream="stopped",`+
+			`type="end_of_stream"} 1`,
+	)
+
+}
+
+func TestCollectWorkers(t *testing.T) {
+
+	r := metrics.NewRegistry()
+	r.CollectWorkers(func() (map[string]int, error) {
+		return map[string]int{"pending": 0, "running": 1, "finished": 3,
+			"failed": 0}, nil
+	})
+
+	assertLines(t, scrape(t, r),
+		"# TYPE lapis_event_workers gauge",
+		`lapis_event_workers{state="failed"} 0`,
+		`lapis_event_workers{state="finished"} 3`,
+		`lapis_event_workers{state="pending"} 0`,
+		`lapis_event_workers{state="running"} 1`,
+	)
+
+}
+
+func TestCollectWorkersError(t *testing.T) {
+
+	r := metrics.NewRegistry()
+	r.CollectWorkers(func() (map[string]int, error) {
+		return nil, errors.New("database is locked")
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusInternalServerError ||
+		!strings.Contains(rec.Body.String(), "database is locked") {
+		t.Fatalf("expected the error to be served, got %d: %s", rec.Code,
+			rec.Body.String())
+	}
+
+}
+
+func TestRegistryEmpty(t *testing.T) {
+
+	if body := scrape(t, metrics.NewRegistry()); body != "" {
+		t.Fatalf("expected no metrics, got:\n%s", body)
+	}
+
+}
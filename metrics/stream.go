@@ -0,0 +1,270 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bsladewski/lapis/stream"
+)
+
+// latencyBuckets are the upper bounds in seconds of the buckets of the Next
+// latency histogram.
+var latencyBuckets = []float64{
+	0.000001, 0.00001, 0.0001, 0.001, 0.01, 0.1, 1, 10,
+}
+
+// A histogram counts observations in cumulative buckets without locking.
+type histogram struct {
+	bounds []float64
+	// buckets holds a count for each bound followed by a count of the
+	// observations above the largest bound
+	buckets []atomic.Uint64
+	// sum is the sum of observations in nanoseconds
+	sum atomic.Int64
+}
+
+// newHistogram returns a histogram with the specified bucket bounds.
+func newHistogram(bounds []float64) *histogram {
+
+	return &histogram{
+		bounds:  bounds,
+		buckets: make([]atomic.Uint64, len(bounds)+1),
+	}
+
+}
+
+// observe records a duration.
+func (h *histogram) observe(d time.Duration) {
+
+	seconds := d.Seconds()
+	bucket := len(h.bounds)
+	for i, bound := range h.bounds {
+		if seconds <= bound {
+			bucket = i
+			break
+		}
+	}
+
+	h.buckets[bucket].Add(1)
+	h.sum.Add(int64(d))
+
+}
+
+// write writes the buckets, sum and count of the histogram.
+func (h *histogram) write(w io.Writer, name string, labels ...string) {
+
+	// buckets are counted individually and written cumulatively; the count is
+	// the sum of the buckets so that it is never less than any bucket while
+	// observations are recorded concurrently
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.buckets[i].Load()
+		sample(w, name+"_bucket", float64(cumulative), append(labels, "le",
+			strconv.FormatFloat(bound, 'g', -1, 64))...)
+	}
+
+	count := cumulative + h.buckets[len(h.bounds)].Load()
+	sample(w, name+"_bucket", float64(count), append(labels, "le",
+		"+Inf")...)
+	sample(w, name+"_sum", time.Duration(h.sum.Load()).Seconds(), labels...)
+	sample(w, name+"_count", float64(count), labels...)
+
+}
+
+// streamMetrics holds the metrics of an instrumented stream.
+type streamMetrics struct {
+	values  atomic.Uint64
+	latency *histogram
+	errors  sync.Map
+}
+
+// newStreamMetrics returns empty stream metrics.
+func newStreamMetrics() *streamMetrics {
+
+	return &streamMetrics{
+		latency: newHistogram(latencyBuckets),
+	}
+
+}
+
+// errorKind returns the type label of an error; the errors defined by the
+// stream package are named and other errors are identified by the first
+// exported type in their chain of wrapped errors, e.g. *strconv.NumError.
+// Errors without an exported type, such as those created by errors.New or
+// fmt.Errorf, are labelled as error.
+func errorKind(err error) string {
+
+	switch {
+	case errors.Is(err, stream.ErrEndOfStream):
+		return "end_of_stream"
+	case errors.Is(err, stream.ErrNotReady):
+		return "not_ready"
+	}
+
+	for ; err != nil; err = errors.Unwrap(err) {
+
+		t := reflect.TypeOf(err)
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		if token.IsExported(t.Name()) {
+			return fmt.Sprintf("%T", err)
+		}
+
+	}
+
+	return "error"
+
+}
+
+// record records a call to Next that took the specified duration.
+func (m *streamMetrics) record(d time.Duration, err error) {
+
+	m.latency.observe(d)
+
+	if err == nil {
+		m.values.Add(1)
+		return
+	}
+
+	count, _ := m.errors.LoadOrStore(errorKind(err), new(atomic.Uint64))
+	count.(*atomic.Uint64).Add(1)
+
+}
+
+// errorCount is the number of errors of a type.
+type errorCount struct {
+	kind  string
+	count uint64
+}
+
+// errorCounts returns the number of errors of each type ordered by type.
+func (m *streamMetrics) errorCounts() []errorCount {
+
+	var counts []errorCount
+	m.errors.Range(func(key, value any) bool {
+		counts = append(counts, errorCount{
+			kind:  key.(string),
+			count: value.(*atomic.Uint64).Load(),
+		})
+		return true
+	})
+
+	sort.Slice(counts, func(i, j int) bool {
+		return counts[i].kind < counts[j].kind
+	})
+
+	return counts
+
+}
+
+// instrumented is a stream that records metrics.
+type instrumented struct {
+	metrics *streamMetrics
+	in      stream.Stream
+}
+
+// Instrument returns a stream that passes values through while recording the
+// number of values read, the latency of each call to Next and the number of
+// errors of each type under the specified name. Streams instrumented with the
+// same name share metrics.
+func (r *Registry) Instrument(name string, in stream.Stream) stream.Stream {
+
+	return &instrumented{
+		metrics: r.stream(name),
+		in:      in,
+	}
+
+}
+
+func (s *instrumented) Next() (float64, error) {
+
+	start := time.Now()
+	value, err := s.in.Next()
+	s.metrics.record(time.Since(start), err)
+
+	return value, err
+
+}
+
+func (s *instrumented) Close() {
+	s.in.Close()
+}
+
+// instrumentedMulti is a multi stream that records metrics.
+type instrumentedMulti struct {
+	metrics *streamMetrics
+	in      stream.MultiStream
+}
+
+// InstrumentMulti returns a multi stream that passes sets of values through
+// while recording metrics under the specified name, counting each set of
+// values as a single value.
+func (r *Registry) InstrumentMulti(name string,
+	in stream.MultiStream) stream.MultiStream {
+
+	return &instrumentedMulti{
+		metrics: r.stream(name),
+		in:      in,
+	}
+
+}
+
+func (s *instrumentedMulti) Components() []string {
+	return s.in.Components()
+}
+
+func (s *instrumentedMulti) Next() ([]float64, error) {
+
+	start := time.Now()
+	values, err := s.in.Next()
+	s.metrics.record(time.Since(start), err)
+
+	return values, err
+
+}
+
+func (s *instrumentedMulti) Close() {
+	s.in.Close()
+}
+
+// instrumentedCandles is a candle stream that records metrics.
+type instrumentedCandles struct {
+	metrics *streamMetrics
+	in      stream.CandleStream
+}
+
+// InstrumentCandles returns a candle stream that passes candles through while
+// recording metrics under the specified name.
+func (r *Registry) InstrumentCandles(name string,
+	in stream.CandleStream) stream.CandleStream {
+
+	return &instrumentedCandles{
+		metrics: r.stream(name),
+		in:      in,
+	}
+
+}
+
+func (s *instrumentedCandles) Next() (stream.Candle, error) {
+
+	start := time.Now()
+	candle, err := s.in.Next()
+	s.metrics.record(time.Since(start), err)
+
+	return candle, err
+
+}
+
+func (s *instrumentedCandles) Close() {
+	s.in.Close()
+}
@@ -3,24 +3,23 @@ package output
 import (
 	"fmt"
 
-	"github.com/bsladewski/gollections"
 	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
 )
 
 // array is used to retrieve a frame of data from a stream as an array.
 type array struct {
-	size int
-	data gollections.Queue
+	data *util.Series[float64]
 	in   stream.Stream
 }
 
 // NewArrayOutput constructs an output that compiles an array representing a
-// frame of stream data.
-func NewArrayOutput(in stream.Stream, size int) Output {
+// frame of stream data holding at most size values, or every value if size is
+// zero.
+func NewArrayOutput(in stream.Stream, size int) Output[[]float64] {
 
 	return &array{
-		size: size,
-		data: gollections.NewLinkedQueue(),
+		data: util.NewSeries[float64](size),
 		in:   in,
 	}
 
@@ -30,40 +29,98 @@ func (a *array) Next() (float64, error) {
 
 	// get the next value from the input stream
 	value, err := a.in.Next()
-	if err != nil {
+	if err == stream.ErrNotReady {
+		// pass values that are not ready through without recording them
+		return value, err
+	} else if err != nil {
 		return 0.0, err
 	}
 
 	// add the input stream to the current frame of data
-	a.data.Add(value)
-	if a.size > 0 {
-		for a.data.Size() > a.size {
-			a.data.PopFirst()
-		}
-	}
+	a.data.Append(value)
 
 	// return the input value
 	return value, nil
 
 }
 
-func (a *array) GetData() (interface{}, error) {
+func (a *array) GetData() []float64 {
+	return a.data.Snapshot()
+}
+
+func (a *array) Close() {
+	a.in.Close()
+}
+
+// multiArray is used to retrieve a frame of data from each component of a
+// multi stream as an array.
+type multiArray struct {
+	components []string
+	data       *util.Series[[]float64]
+	in         stream.MultiStream
+}
+
+// NewMultiArrayOutput constructs an output that compiles an array representing
+// a frame of data for each component of a multi stream, holding at most size
+// sets of values or every set if size is zero; the data is returned as a map
+// of component names to arrays.
+func NewMultiArrayOutput(in stream.MultiStream,
+	size int) MultiOutput[map[string][]float64] {
+
+	return &multiArray{
+		components: in.Components(),
+		data:       util.NewSeries[[]float64](size),
+		in:         in,
+	}
+
+}
+
+func (a *multiArray) Components() []string {
+	return a.in.Components()
+}
+
+func (a *multiArray) Next() ([]float64, error) {
+
+	// get the next set of values from the input stream
+	values, err := a.in.Next()
+	if err == stream.ErrNotReady {
+		// pass values that are not ready through without recording them
+		return values, err
+	} else if err != nil {
+		return nil, err
+	}
+
+	if len(values) != len(a.components) {
+		return nil, fmt.Errorf("expected %d values, got %d",
+			len(a.components), len(values))
+	}
 
-	data := []float64{}
+	// record a copy of the set of values so that every component of a
+	// snapshot holds the same number of values
+	a.data.Append(append([]float64{}, values...))
 
-	// convert data to an array of floats
-	for _, value := range a.data.ToArray() {
-		if floatValue, ok := value.(float64); ok {
-			data = append(data, floatValue)
-		} else {
-			return nil, fmt.Errorf("invalid data: %v, Type: %T", value, value)
+	// return the input values
+	return values, nil
+
+}
+
+func (a *multiArray) GetData() map[string][]float64 {
+
+	sets := a.data.Snapshot()
+
+	// gather the frame of data for each component
+	data := map[string][]float64{}
+	for i, component := range a.components {
+		data[component] = make([]float64, len(sets))
+		for j, values := range sets {
+			data[component][j] = values[i]
 		}
 	}
 
-	return data, nil
+	return data
 
 }
 
-func (a *array) Close() {
+func (a *multiArray) Close() {
 	a.in.Close()
 }
@@ -0,0 +1,72 @@
+package output_test
+
+import (
+	"testing"
+
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/output"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// TestArrayOutput tests compiling a frame of stream data.
+func TestArrayOutput(t *testing.T) {
+
+	ao := output.NewArrayOutput(input.NewListStream(
+		[]float64{1, 2, 3, 4, 5}), 3)
+	defer ao.Close()
+
+	for {
+		if _, err := ao.Next(); err == stream.ErrEndOfStream {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the frame holds the newest values
+	data := ao.GetData()
+	expected := []float64{3, 4, 5}
+
+	if len(data) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, data)
+	}
+
+	for i := range expected {
+		if data[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, data)
+		}
+	}
+
+}
+
+// TestMultiArrayOutput tests compiling a frame of data for each component of a
+// multi stream.
+func TestMultiArrayOutput(t *testing.T) {
+
+	mo := output.NewMultiArrayOutput(input.NewMultiListStream(
+		[]string{"a", "b"}, [][]float64{{1, 10}, {2, 20}, {3, 30}}), 0)
+	defer mo.Close()
+
+	for {
+		if _, err := mo.Next(); err == stream.ErrEndOfStream {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data := mo.GetData()
+	expected := map[string][]float64{"a": {1, 2, 3}, "b": {10, 20, 30}}
+
+	for component, values := range expected {
+		if len(data[component]) != len(values) {
+			t.Fatalf("expected %v, got %v", expected, data)
+		}
+		for i := range values {
+			if data[component][i] != values[i] {
+				t.Fatalf("expected %v, got %v", expected, data)
+			}
+		}
+	}
+
+}
@@ -0,0 +1,456 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	gomath "math"
+	"sync/atomic"
+	"time"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	arrowarray "github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/ipc"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/apache/arrow/go/v14/parquet"
+	"github.com/apache/arrow/go/v14/parquet/compress"
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// ColumnarFormat identifies a columnar file format.
+type ColumnarFormat int
+
+const (
+	// FormatParquet identifies the Apache Parquet format; columns are
+	// compressed with Snappy.
+	FormatParquet ColumnarFormat = iota
+	// FormatArrow identifies the Apache Arrow IPC file format, also known as
+	// Feather version 2.
+	FormatArrow
+)
+
+// columnarBatchSize is the number of rows buffered before they are written as
+// a record batch, or a row group in Parquet files.
+const columnarBatchSize = 65536
+
+// timestampType is the type of timestamp columns.
+var timestampType = &arrow.TimestampType{
+	Unit:     arrow.Microsecond,
+	TimeZone: "UTC",
+}
+
+// candleColumns name the value columns of candle files.
+var candleColumns = []string{"open", "high", "low", "close", "volume",
+	"quote_volume"}
+
+// positionWriter tracks the position of a writer so that it can stand in for
+// the seeker required by the Arrow file writer, without exposing the Close
+// method of the writer to the format writers.
+type positionWriter struct {
+	w   io.Writer
+	pos int64
+}
+
+func (p *positionWriter) Write(b []byte) (int, error) {
+
+	n, err := p.w.Write(b)
+	p.pos += int64(n)
+
+	return n, err
+
+}
+
+// Seek reports the current position; no other seek is supported.
+func (p *positionWriter) Seek(offset int64, whence int) (int64, error) {
+
+	if offset != 0 || whence != io.SeekCurrent {
+		return 0, errors.New("columnar writer cannot seek")
+	}
+
+	return p.pos, nil
+
+}
+
+// batchWriter writes record batches in a columnar format.
+type batchWriter interface {
+	Write(rec arrow.Record) error
+	Close() error
+}
+
+// columnarWriter buffers rows of a timestamp column followed by float columns
+// and writes them in batches.
+type columnarWriter struct {
+	format   ColumnarFormat
+	schema   *arrow.Schema
+	w        io.Writer
+	builder  *arrowarray.RecordBuilder
+	batch    batchWriter
+	rows     int
+	finished bool
+	samples  atomic.Int64
+	err      sinkError
+}
+
+// newColumnarWriter returns a writer of a timestamp column followed by a
+// float column for each of the specified names; NaN values are written as
+// null if nullable is set.
+func newColumnarWriter(format ColumnarFormat, columns []string,
+	nullable bool, w io.Writer) *columnarWriter {
+
+	fields := []arrow.Field{{Name: "timestamp", Type: timestampType}}
+	for _, column := range columns {
+		fields = append(fields, arrow.Field{
+			Name:     column,
+			Type:     arrow.PrimitiveTypes.Float64,
+			Nullable: nullable,
+		})
+	}
+
+	schema := arrow.NewSchema(fields, nil)
+
+	return &columnarWriter{
+		format:  format,
+		schema:  schema,
+		w:       w,
+		builder: arrowarray.NewRecordBuilder(memory.DefaultAllocator, schema),
+	}
+
+}
+
+// start creates the format writer, which writes the beginning of the file.
+func (c *columnarWriter) start() error {
+
+	w := &positionWriter{w: c.w}
+
+	switch c.format {
+	case FormatParquet:
+		batch, err := pqarrow.NewFileWriter(c.schema, w,
+			parquet.NewWriterProperties(
+				parquet.WithCompression(compress.Codecs.Snappy),
+				parquet.WithMaxRowGroupLength(columnarBatchSize)),
+			pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema()))
+		if err != nil {
+			return err
+		}
+		c.batch = batch
+	case FormatArrow:
+		batch, err := ipc.NewFileWriter(w, ipc.WithSchema(c.schema))
+		if err != nil {
+			return err
+		}
+		c.batch = batch
+	default:
+		return fmt.Errorf("invalid columnar format: %d", c.format)
+	}
+
+	return nil
+
+}
+
+// write buffers a row, writing a batch once enough rows are buffered; any
+// error is recorded.
+func (c *columnarWriter) write(timestamp time.Time, values []float64) error {
+	return c.err.record(c.writeRow(timestamp, values))
+}
+
+// writeRow buffers a row, writing a batch once enough rows are buffered.
+func (c *columnarWriter) writeRow(timestamp time.Time,
+	values []float64) error {
+
+	if c.finished {
+		return errors.New("columnar writer is finished")
+	}
+
+	c.builder.Field(0).(*arrowarray.TimestampBuilder).Append(
+		arrow.Timestamp(timestamp.UnixMicro()))
+
+	for i, value := range values {
+		field := c.builder.Field(i + 1).(*arrowarray.Float64Builder)
+		if gomath.IsNaN(value) && c.schema.Field(i+1).Nullable {
+			field.AppendNull()
+		} else {
+			field.Append(value)
+		}
+	}
+
+	c.rows++
+	c.samples.Add(1)
+
+	if c.rows >= columnarBatchSize {
+		return c.flush()
+	}
+
+	return nil
+
+}
+
+// recoverPanic recovers a panic raised by a format writer as an error; the
+// Parquet writer panics rather than returning some errors of the underlying
+// writer.
+func recoverPanic(err *error) {
+
+	if r := recover(); r != nil {
+		if recovered, ok := r.(error); ok {
+			*err = fmt.Errorf("columnar writer, err: %w", recovered)
+		} else {
+			*err = fmt.Errorf("columnar writer, err: %v", r)
+		}
+	}
+
+}
+
+// flush writes the buffered rows as a batch.
+func (c *columnarWriter) flush() (err error) {
+
+	defer recoverPanic(&err)
+
+	if c.batch == nil {
+		if err := c.start(); err != nil {
+			return err
+		}
+	}
+
+	if c.rows == 0 {
+		return nil
+	}
+
+	record := c.builder.NewRecord()
+	defer record.Release()
+
+	c.rows = 0
+
+	return c.batch.Write(record)
+
+}
+
+// finish writes the buffered rows and the end of the file, recording any
+// error; rows cannot be written once the writer is finished. The underlying
+// writer belongs to the caller and is never closed.
+func (c *columnarWriter) finish() error {
+
+	if c.finished {
+		return nil
+	}
+	c.finished = true
+
+	defer c.builder.Release()
+
+	err := c.flush()
+	if err == nil {
+		err = c.closeBatch()
+	}
+
+	return c.err.record(err)
+
+}
+
+// closeBatch writes the end of the file.
+func (c *columnarWriter) closeBatch() (err error) {
+
+	defer recoverPanic(&err)
+
+	return c.batch.Close()
+
+}
+
+// columnarOutput is used to write the values of a stream to a columnar file.
+type columnarOutput struct {
+	writer *columnarWriter
+	clock  func() time.Time
+	in     stream.Stream
+}
+
+// NewColumnarOutput constructs an output that writes each value of a stream
+// to the supplied writer as a row of a columnar file in the specified format,
+// with a timestamp column holding UTC microseconds and a double column with
+// the specified name in which NaN values are written as null; the output data
+// is the number of rows written. Rows are timestamped by the supplied clock,
+// or by the current time if clock is nil; use the Now method of a candle clock
+// to timestamp rows by the time of the candles they were calculated from.
+//
+// Rows are buffered and written in batches; the file is completed when the
+// end of the input stream is reached or the output is closed, and the writer
+// itself is never closed. Values that are not ready are passed through
+// without being written. Errors writing rows are returned by Next, and Err
+// also reports an error completing the file when the output is closed.
+func NewColumnarOutput(in stream.Stream, column string, w io.Writer,
+	format ColumnarFormat, clock func() time.Time) SinkOutput {
+
+	if clock == nil {
+		clock = time.Now
+	}
+
+	return &columnarOutput{
+		writer: newColumnarWriter(format, []string{column}, true, w),
+		clock:  clock,
+		in:     in,
+	}
+
+}
+
+func (c *columnarOutput) Next() (float64, error) {
+
+	value, err := c.in.Next()
+	if err == stream.ErrNotReady {
+		// pass values that are not ready through without recording them
+		return value, err
+	} else if err == stream.ErrEndOfStream {
+		if err := c.writer.finish(); err != nil {
+			return 0.0, err
+		}
+		return 0.0, stream.ErrEndOfStream
+	} else if err != nil {
+		return 0.0, err
+	}
+
+	if err := c.writer.write(c.clock(), []float64{value}); err != nil {
+		return 0.0, err
+	}
+
+	return value, nil
+
+}
+
+func (c *columnarOutput) GetData() int {
+	return int(c.writer.samples.Load())
+}
+
+func (c *columnarOutput) Close() {
+	c.writer.finish()
+	c.in.Close()
+}
+
+func (c *columnarOutput) Err() error {
+	return c.writer.err.get()
+}
+
+// multiColumnarOutput is used to write the values of a multi stream to a
+// columnar file.
+type multiColumnarOutput struct {
+	writer *columnarWriter
+	clock  func() time.Time
+	in     stream.MultiStream
+}
+
+// NewMultiColumnarOutput constructs an output that writes each set of values
+// of a multi stream to the supplied writer as a row of a columnar file in the
+// specified format, with a double column for each component. The output
+// behaves as described by NewColumnarOutput.
+func NewMultiColumnarOutput(in stream.MultiStream, w io.Writer,
+	format ColumnarFormat, clock func() time.Time) MultiSinkOutput {
+
+	if clock == nil {
+		clock = time.Now
+	}
+
+	return &multiColumnarOutput{
+		writer: newColumnarWriter(format, in.Components(), true, w),
+		clock:  clock,
+		in:     in,
+	}
+
+}
+
+func (c *multiColumnarOutput) Components() []string {
+	return c.in.Components()
+}
+
+func (c *multiColumnarOutput) Next() ([]float64, error) {
+
+	values, err := c.in.Next()
+	if err == stream.ErrNotReady {
+		// pass values that are not ready through without recording them
+		return values, err
+	} else if err == stream.ErrEndOfStream {
+		if err := c.writer.finish(); err != nil {
+			return nil, err
+		}
+		return nil, stream.ErrEndOfStream
+	} else if err != nil {
+		return nil, err
+	}
+
+	if columns := len(c.writer.schema.Fields()) - 1; len(values) != columns {
+		return nil, fmt.Errorf("expected %d values, got %d", columns,
+			len(values))
+	}
+
+	if err := c.writer.write(c.clock(), values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+
+}
+
+func (c *multiColumnarOutput) GetData() int {
+	return int(c.writer.samples.Load())
+}
+
+func (c *multiColumnarOutput) Close() {
+	c.writer.finish()
+	c.in.Close()
+}
+
+func (c *multiColumnarOutput) Err() error {
+	return c.writer.err.get()
+}
+
+// candleColumnarOutput is used to write the candles of a candle stream to a
+// columnar file.
+type candleColumnarOutput struct {
+	writer *columnarWriter
+	in     stream.CandleStream
+}
+
+// NewCandleColumnarOutput constructs an output that writes each candle of a
+// candle stream to the supplied writer as a row of a columnar file in the
+// specified format, with a timestamp column holding the candle timestamp in
+// UTC microseconds followed by open, high, low, close, volume and
+// quote_volume double columns. The output behaves as described by
+// NewColumnarOutput.
+func NewCandleColumnarOutput(in stream.CandleStream, w io.Writer,
+	format ColumnarFormat) CandleSinkOutput {
+
+	return &candleColumnarOutput{
+		writer: newColumnarWriter(format, candleColumns, false, w),
+		in:     in,
+	}
+
+}
+
+func (c *candleColumnarOutput) Next() (stream.Candle, error) {
+
+	candle, err := c.in.Next()
+	if err == stream.ErrEndOfStream {
+		if err := c.writer.finish(); err != nil {
+			return stream.Candle{}, err
+		}
+		return stream.Candle{}, stream.ErrEndOfStream
+	} else if err != nil {
+		return candle, err
+	}
+
+	if err := c.writer.write(candle.Timestamp, []float64{candle.Open,
+		candle.High, candle.Low, candle.Close, candle.Volume,
+		candle.QuoteVolume}); err != nil {
+		return stream.Candle{}, err
+	}
+
+	return candle, nil
+
+}
+
+func (c *candleColumnarOutput) GetData() int {
+	return int(c.writer.samples.Load())
+}
+
+func (c *candleColumnarOutput) Close() {
+	c.writer.finish()
+	c.in.Close()
+}
+
+func (c *candleColumnarOutput) Err() error {
+	return c.writer.err.get()
+}
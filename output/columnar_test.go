@@ -0,0 +1,413 @@
+package output_test
+
+import (
+	"bytes"
+	"context"
+	gomath "math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/ipc"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/output"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// closeBuffer is a buffer that records being closed.
+type closeBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *closeBuffer) Close() error {
+
+	c.closed = true
+
+	return nil
+
+}
+
+// notReadyStream reports its first values as not ready.
+type notReadyStream struct {
+	stream.Stream
+	warmUp int
+}
+
+func (n *notReadyStream) Next() (float64, error) {
+
+	value, err := n.Stream.Next()
+	if err == nil && n.warmUp > 0 {
+		n.warmUp--
+		return value, stream.ErrNotReady
+	}
+
+	return value, err
+
+}
+
+// readColumnar reads a columnar file into a table.
+func readColumnar(t *testing.T, data []byte,
+	format output.ColumnarFormat) arrow.Table {
+
+	t.Helper()
+
+	if format == output.FormatParquet {
+		table, err := pqarrow.ReadTable(context.Background(),
+			bytes.NewReader(data), nil, pqarrow.ArrowReadProperties{},
+			memory.DefaultAllocator)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return table
+	}
+
+	reader, err := ipc.NewFileReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	var records []arrow.Record
+	for i := 0; i < reader.NumRecords(); i++ {
+		record, err := reader.RecordAt(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer record.Release()
+		records = append(records, record)
+	}
+
+	return array.NewTableFromRecords(reader.Schema(), records)
+
+}
+
+// tableFloats returns the values of a double column of a table with nulls
+// read as NaN.
+func tableFloats(t *testing.T, table arrow.Table, column int) []float64 {
+
+	t.Helper()
+
+	var values []float64
+	for _, chunk := range table.Column(column).Data().Chunks() {
+		floats := chunk.(*array.Float64)
+		for i := 0; i < floats.Len(); i++ {
+			if floats.IsNull(i) {
+				values = append(values, gomath.NaN())
+			} else {
+				values = append(values, floats.Value(i))
+			}
+		}
+	}
+
+	return values
+
+}
+
+// tableTimes returns the values of a timestamp column of a table.
+func tableTimes(t *testing.T, table arrow.Table, column int) []time.Time {
+
+	t.Helper()
+
+	unit := table.Schema().Field(column).Type.(*arrow.TimestampType).Unit
+
+	var times []time.Time
+	for _, chunk := range table.Column(column).Data().Chunks() {
+		timestamps := chunk.(*array.Timestamp)
+		for i := 0; i < timestamps.Len(); i++ {
+			times = append(times, timestamps.Value(i).ToTime(unit))
+		}
+	}
+
+	return times
+
+}
+
+// TestColumnarOutput tests writing the values of a stream to columnar files.
+func TestColumnarOutput(t *testing.T) {
+
+	for _, format := range []output.ColumnarFormat{output.FormatParquet,
+		output.FormatArrow} {
+
+		var buf closeBuffer
+		co := output.NewColumnarOutput(&notReadyStream{
+			warmUp: 1,
+			Stream: input.NewListStream([]float64{0, 1.5, gomath.NaN(), 3}),
+		}, "equity", &buf, format, hourlyClock())
+
+		for {
+			if _, err := co.Next(); err == stream.ErrEndOfStream {
+				break
+			} else if err != nil && err != stream.ErrNotReady {
+				t.Fatal(err)
+			}
+		}
+
+		// the file is completed at the end of the stream and the writer is
+		// left open
+		if buf.closed || co.GetData() != 3 || co.Err() != nil {
+			t.Fatalf("format %d: expected 3 rows in an open writer, got %d, "+
+				"closed: %t, err: %v", format, co.GetData(), buf.closed,
+				co.Err())
+		}
+
+		co.Close()
+
+		if buf.closed {
+			t.Fatalf("format %d: expected the writer to be left open", format)
+		}
+
+		table := readColumnar(t, buf.Bytes(), format)
+		defer table.Release()
+
+		schema := table.Schema()
+		if schema.NumFields() != 2 || schema.Field(0).Name != "timestamp" ||
+			schema.Field(1).Name != "equity" ||
+			schema.Field(1).Type.ID() != arrow.FLOAT64 {
+			t.Fatalf("format %d: unexpected schema: %s", format, schema)
+		}
+
+		timestampType, ok := schema.Field(0).Type.(*arrow.TimestampType)
+		if !ok || timestampType.TimeZone != "UTC" {
+			t.Fatalf("format %d: unexpected timestamp type: %s", format,
+				schema.Field(0).Type)
+		}
+
+		values := tableFloats(t, table, 1)
+		if len(values) != 3 || values[0] != 1.5 ||
+			!gomath.IsNaN(values[1]) || values[2] != 3 {
+			t.Fatalf("format %d: unexpected values: %v", format, values)
+		}
+
+		// NaN values are written as null
+		if table.Column(1).Data().NullN() != 1 {
+			t.Fatalf("format %d: expected 1 null, got %d", format,
+				table.Column(1).Data().NullN())
+		}
+
+		times := tableTimes(t, table, 0)
+		if !times[1].Equal(times[0].Add(time.Hour)) {
+			t.Fatalf("format %d: unexpected timestamps: %v", format, times)
+		}
+
+	}
+
+}
+
+// TestMultiColumnarOutput tests writing the values of a multi stream to a
+// columnar file.
+func TestMultiColumnarOutput(t *testing.T) {
+
+	var buf bytes.Buffer
+	mo := output.NewMultiColumnarOutput(input.NewMultiListStream(
+		[]string{"upper", "lower"}, [][]float64{{3, 1}, {4, 2}}), &buf,
+		output.FormatParquet, hourlyClock())
+
+	for {
+		if _, err := mo.Next(); err == stream.ErrEndOfStream {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mo.Close()
+
+	table := readColumnar(t, buf.Bytes(), output.FormatParquet)
+	defer table.Release()
+
+	if table.NumRows() != 2 || table.Schema().Field(1).Name != "upper" ||
+		table.Schema().Field(2).Name != "lower" {
+		t.Fatalf("unexpected table: %s", table.Schema())
+	}
+
+	if lower := tableFloats(t, table, 2); lower[0] != 1 || lower[1] != 2 {
+		t.Fatalf("unexpected lower values: %v", lower)
+	}
+
+}
+
+// TestCandleColumnarOutput tests that candles written to columnar files are
+// read back by the columnar candle inputs.
+func TestCandleColumnarOutput(t *testing.T) {
+
+	mockData, err := os.Open("../input/mock_data.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mockData.Close()
+
+	ms, err := input.NewCoinbaseMockCandleStream(mockData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var candles []stream.Candle
+	for {
+		candle, err := ms.Next()
+		if err == stream.ErrEndOfStream {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		candles = append(candles, candle)
+	}
+
+	readers := map[output.ColumnarFormat]func(
+		input.ReaderAtSeeker) (stream.CandleStream, error){
+		output.FormatParquet: input.NewParquetCandleStream,
+		output.FormatArrow:   input.NewArrowCandleStream,
+	}
+
+	for format, reader := range readers {
+
+		var buf bytes.Buffer
+		co := output.NewCandleColumnarOutput(
+			input.NewCandleListStream(candles), &buf, format)
+
+		for {
+			if _, err := co.Next(); err == stream.ErrEndOfStream {
+				break
+			} else if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if co.GetData() != len(candles) {
+			t.Fatalf("format %d: expected %d rows, got %d", format,
+				len(candles), co.GetData())
+		}
+
+		co.Close()
+
+		cs, err := reader(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, expected := range candles {
+
+			candle, err := cs.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !candle.Timestamp.Equal(expected.Timestamp) ||
+				candle.Open != expected.Open ||
+				candle.Close != expected.Close ||
+				candle.QuoteVolume != expected.QuoteVolume {
+				t.Fatalf("format %d: expected candle %d %+v, got %+v",
+					format, i, expected, candle)
+			}
+
+		}
+
+		if _, err := cs.Next(); err != stream.ErrEndOfStream {
+			t.Fatalf("format %d: expected end of stream, got %v", format,
+				err)
+		}
+
+		cs.Close()
+
+	}
+
+}
+
+// TestColumnarOutputBatches tests writing more rows than fit in a batch.
+func TestColumnarOutputBatches(t *testing.T) {
+
+	values := make([]float64, 70000)
+	for i := range values {
+		values[i] = float64(i)
+	}
+
+	var buf bytes.Buffer
+	co := output.NewColumnarOutput(input.NewListStream(values), "value",
+		&buf, output.FormatParquet, hourlyClock())
+
+	for {
+		if _, err := co.Next(); err == stream.ErrEndOfStream {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	table := readColumnar(t, buf.Bytes(), output.FormatParquet)
+	defer table.Release()
+
+	read := tableFloats(t, table, 1)
+	if len(read) != len(values) || read[len(read)-1] != 69999 {
+		t.Fatalf("expected %d values, got %d", len(values), len(read))
+	}
+
+}
+
+// TestColumnarOutputErrors tests reporting errors completing a file.
+func TestColumnarOutputErrors(t *testing.T) {
+
+	for _, format := range []output.ColumnarFormat{output.FormatParquet,
+		output.FormatArrow} {
+
+		// a failure completing the file at the end of the stream is returned
+		// by Next
+		co := output.NewColumnarOutput(input.NewListStream([]float64{1}),
+			"value", failingWriter{}, format, hourlyClock())
+
+		if _, err := co.Next(); err != nil {
+			t.Fatalf("format %d: %v", format, err)
+		}
+
+		if _, err := co.Next(); err == nil || err == stream.ErrEndOfStream {
+			t.Fatalf("format %d: expected a write error, got %v", format, err)
+		}
+
+		if co.Err() == nil {
+			t.Fatalf("format %d: expected the error to be recorded", format)
+		}
+
+		// a failure completing the file when the output is closed is
+		// reported by Err
+		cs := output.NewCandleColumnarOutput(input.NewCandleListStream(
+			[]stream.Candle{{Close: 1}, {Close: 2}}), failingWriter{}, format)
+
+		if _, err := cs.Next(); err != nil {
+			t.Fatalf("format %d: %v", format, err)
+		}
+
+		cs.Close()
+
+		if cs.Err() == nil {
+			t.Fatalf("format %d: expected the error to be reported after "+
+				"closing", format)
+		}
+
+	}
+
+}
+
+// TestColumnarOutputEmpty tests that closing an output without rows writes an
+// empty file.
+func TestColumnarOutputEmpty(t *testing.T) {
+
+	for _, format := range []output.ColumnarFormat{output.FormatParquet,
+		output.FormatArrow} {
+
+		var buf bytes.Buffer
+		output.NewColumnarOutput(input.NewListStream(nil), "value", &buf,
+			format, nil).Close()
+
+		table := readColumnar(t, buf.Bytes(), format)
+		if table.NumRows() != 0 || table.NumCols() != 2 {
+			t.Fatalf("format %d: expected an empty table, got %d rows",
+				format, table.NumRows())
+		}
+		table.Release()
+
+	}
+
+}
@@ -0,0 +1,380 @@
+package output
+
+import (
+	"errors"
+	gomath "math"
+	"sync"
+
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// A Span summarizes the values read from a stream at positions Start up to
+// but excluding End; positions count every value read, including values that
+// are not ready, so that the spans of streams read together line up. The
+// minimum, maximum, first and last values are NaN if the span holds no ready
+// values.
+type Span struct {
+	Start int
+	End   int
+	// Count is the number of ready values in the span.
+	Count int
+	Min   float64
+	Max   float64
+	First float64
+	Last  float64
+	Sum   float64
+}
+
+// newSpan returns an empty span starting at the specified position.
+func newSpan(start int) Span {
+
+	nan := gomath.NaN()
+
+	return Span{
+		Start: start,
+		End:   start,
+		Min:   nan,
+		Max:   nan,
+		First: nan,
+		Last:  nan,
+	}
+
+}
+
+// Mean returns the mean of the ready values in the span, or NaN if there are
+// none.
+func (s Span) Mean() float64 {
+
+	if s.Count == 0 {
+		return gomath.NaN()
+	}
+
+	return s.Sum / float64(s.Count)
+
+}
+
+// add adds the value at the next position to the span; NaN values extend the
+// span without being summarized.
+func (s *Span) add(value float64) {
+
+	s.End++
+
+	if gomath.IsNaN(value) {
+		return
+	}
+
+	if s.Count == 0 {
+		s.Min, s.Max, s.First = value, value, value
+	} else {
+		s.Min = gomath.Min(s.Min, value)
+		s.Max = gomath.Max(s.Max, value)
+	}
+
+	s.Last = value
+	s.Sum += value
+	s.Count++
+
+}
+
+// merge extends the span with the span that follows it.
+func (s *Span) merge(next Span) {
+
+	if next.Count > 0 {
+		if s.Count == 0 {
+			s.Min, s.Max, s.First = next.Min, next.Max, next.First
+		} else {
+			s.Min = gomath.Min(s.Min, next.Min)
+			s.Max = gomath.Max(s.Max, next.Max)
+		}
+		s.Last = next.Last
+	}
+
+	s.End = next.End
+	s.Sum += next.Sum
+	s.Count += next.Count
+
+}
+
+// downsampleLevel holds the spans of a single resolution.
+type downsampleLevel struct {
+	width     int
+	spans     *util.Series[Span]
+	completed int
+}
+
+// downsample is used to maintain a multi-resolution view of a stream; the
+// levels are updated in place and the view is taken when it is read, so
+// reading values does not allocate. The lock is only held while a value is
+// added or the spans in progress are copied; the spans of each level are read
+// from their series without it.
+type downsample struct {
+	capacity int
+	factor   int
+	mu       sync.Mutex
+	count    int
+	levels   []*downsampleLevel
+	current  []Span
+	in       stream.Stream
+}
+
+// NewDownsampleOutput constructs an output that maintains a multi-resolution
+// view of every value read from a stream. The finest level holds the most
+// recent capacity values and each coarser level holds the most recent
+// capacity spans of factor spans of the level below; a coarser level is added
+// whenever the coarsest level would otherwise drop its oldest span, so that
+// the coarsest level always covers the entire history. Memory is bounded by
+// capacity spans per level while the number of levels grows with the
+// logarithm of the history, e.g. eight levels cover a billion values with a
+// capacity of 1024 and a factor of 8. The capacity must be at least the
+// factor, which must be at least 2.
+func NewDownsampleOutput(in stream.Stream, capacity,
+	factor int) (Output[Downsampled], error) {
+
+	if factor < 2 {
+		return nil, errors.New("downsample factor must be at least 2")
+	}
+
+	if capacity < factor {
+		return nil, errors.New("downsample capacity must be at least the " +
+			"factor")
+	}
+
+	d := &downsample{
+		capacity: capacity,
+		factor:   factor,
+		in:       in,
+	}
+
+	d.addLevel()
+
+	return d, nil
+
+}
+
+// addLevel adds a coarser level seeded from the spans of the coarsest level,
+// which must cover the entire history.
+func (d *downsample) addLevel() {
+
+	level := &downsampleLevel{
+		width: 1,
+		spans: util.NewSeries[Span](d.capacity),
+	}
+	current := newSpan(0)
+
+	if n := len(d.levels); n > 0 {
+
+		coarsest := d.levels[n-1]
+		level.width = coarsest.width * d.factor
+
+		// group the completed spans of the coarsest level, leaving the spans
+		// of an incomplete group in progress
+		for _, span := range coarsest.spans.Snapshot() {
+			current.merge(span)
+			if current.End-current.Start == level.width {
+				level.spans.Append(current)
+				level.completed++
+				current = newSpan(current.End)
+			}
+		}
+
+		current.merge(d.current[n-1])
+		if current.End-current.Start == level.width {
+			level.spans.Append(current)
+			level.completed++
+			current = newSpan(current.End)
+		}
+
+	}
+
+	d.levels = append(d.levels, level)
+	d.current = append(d.current, current)
+
+}
+
+// add adds the value at the next position to every level.
+func (d *downsample) add(value float64) {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.count++
+
+	for i, level := range d.levels {
+
+		d.current[i].add(value)
+
+		if d.current[i].End-d.current[i].Start == level.width {
+
+			// the coarsest level must not drop a span before a coarser level
+			// has been seeded from its spans
+			if i == len(d.levels)-1 && level.completed == d.capacity {
+				d.addLevel()
+			}
+
+			level.spans.Append(d.current[i])
+			level.completed++
+			d.current[i] = newSpan(d.count)
+
+		}
+
+	}
+
+}
+
+func (d *downsample) Next() (float64, error) {
+
+	value, err := d.in.Next()
+	if err == stream.ErrNotReady {
+		// values that are not ready hold their position without being
+		// summarized
+		d.add(gomath.NaN())
+		return value, err
+	} else if err != nil {
+		return value, err
+	}
+
+	d.add(value)
+
+	return value, nil
+
+}
+
+func (d *downsample) GetData() Downsampled {
+
+	for {
+
+		d.mu.Lock()
+		count := d.count
+		levels := append([]*downsampleLevel{}, d.levels...)
+		currents := append([]Span{}, d.current...)
+		d.mu.Unlock()
+
+		data := Downsampled{
+			count:  count,
+			levels: make([][]Span, len(levels)),
+		}
+
+		for i, level := range levels {
+
+			// spans completed after the spans in progress were copied are
+			// excluded
+			current := currents[i]
+			var spans []Span
+			for _, span := range level.spans.Snapshot() {
+				if span.End <= current.Start {
+					spans = append(spans, span)
+				}
+			}
+
+			if current.End > current.Start {
+				spans = append(spans, current)
+			}
+
+			data.levels[i] = spans
+
+		}
+
+		// the coarsest level copied may have dropped spans if a coarser level
+		// was added since the spans in progress were copied, in which case
+		// the view is taken again
+		coarsest := data.levels[len(data.levels)-1]
+		if len(coarsest) == 0 || coarsest[0].Start == 0 {
+			return data
+		}
+
+	}
+
+}
+
+func (d *downsample) Close() {
+	d.in.Close()
+}
+
+// Downsampled is a multi-resolution view of the values read from a stream.
+type Downsampled struct {
+	count  int
+	levels [][]Span
+}
+
+// Count returns the number of values read from the stream.
+func (d Downsampled) Count() int {
+
+	return d.count
+
+}
+
+// Levels returns the number of resolutions in the view.
+func (d Downsampled) Levels() int {
+
+	return len(d.levels)
+
+}
+
+// Level returns the spans of a level ordered by position, where level zero
+// holds the most recent values and each coarser level holds longer spans
+// reaching further back; the coarsest level covers the entire history. The
+// last span may be incomplete.
+func (d Downsampled) Level(level int) []Span {
+
+	if level < 0 || level >= len(d.levels) {
+		return nil
+	}
+
+	return append([]Span{}, d.levels[level]...)
+
+}
+
+// Query returns at most points spans covering the positions from up to but
+// excluding to, or up to the latest value if to is zero. The spans are taken
+// from the finest level that holds the entire range in at most points spans,
+// falling back to the coarsest level that holds the range with spans merged
+// to fit if no level does.
+func (d Downsampled) Query(from, to, points int) []Span {
+
+	if to <= 0 || to > d.count {
+		to = d.count
+	}
+
+	if from < 0 {
+		from = 0
+	}
+
+	if points <= 0 || from >= to || len(d.levels) == 0 {
+		return nil
+	}
+
+	var spans []Span
+	for _, level := range d.levels {
+
+		if len(level) == 0 || level[0].Start > from {
+			continue
+		}
+
+		spans = spans[:0]
+		for _, span := range level {
+			if span.End > from && span.Start < to {
+				spans = append(spans, span)
+			}
+		}
+
+		if len(spans) <= points {
+			return append([]Span{}, spans...)
+		}
+
+	}
+
+	// merge consecutive spans of the coarsest level to fit
+	group := (len(spans) + points - 1) / points
+	merged := make([]Span, 0, points)
+	for i := 0; i < len(spans); i += group {
+		span := spans[i]
+		for j := i + 1; j < i+group && j < len(spans); j++ {
+			span.merge(spans[j])
+		}
+		merged = append(merged, span)
+	}
+
+	return merged
+
+}
@@ -0,0 +1,281 @@
+package output_test
+
+import (
+	gomath "math"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/output"
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// readDownsampled reads every value through a downsample output.
+func readDownsampled(t *testing.T, values []float64, capacity,
+	factor int) output.Downsampled {
+
+	t.Helper()
+
+	do, err := output.NewDownsampleOutput(input.NewListStream(values),
+		capacity, factor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer do.Close()
+
+	for {
+		if _, err := do.Next(); err == stream.ErrEndOfStream {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return do.GetData()
+
+}
+
+// assertSpan asserts that a span summarizes the values at its positions.
+func assertSpan(t *testing.T, values []float64, span output.Span) {
+
+	t.Helper()
+
+	expected := output.Span{
+		Start: span.Start,
+		End:   span.End,
+		Min:   gomath.Inf(1),
+		Max:   gomath.Inf(-1),
+	}
+	for i, value := range values[span.Start:span.End] {
+		if i == 0 {
+			expected.First = value
+		}
+		expected.Min = gomath.Min(expected.Min, value)
+		expected.Max = gomath.Max(expected.Max, value)
+		expected.Last = value
+		expected.Sum += value
+		expected.Count++
+	}
+
+	if span.Count != expected.Count || span.Min != expected.Min ||
+		span.Max != expected.Max || span.First != expected.First ||
+		span.Last != expected.Last ||
+		util.CompareFloat(span.Sum, expected.Sum) != 0 {
+		t.Fatalf("expected span %+v, got %+v", expected, span)
+	}
+
+}
+
+// TestDownsampleOutput tests the levels of a downsampled view against the
+// values read.
+func TestDownsampleOutput(t *testing.T) {
+
+	random := rand.New(rand.NewSource(1))
+
+	values := make([]float64, 1000)
+	for i := range values {
+		values[i] = random.NormFloat64()
+	}
+
+	for _, c := range []struct {
+		capacity int
+		factor   int
+		levels   int
+	}{
+		{16, 4, 4},
+		{10, 3, 6},
+		{2, 2, 10},
+		{1000, 4, 1},
+	} {
+
+		data := readDownsampled(t, values, c.capacity, c.factor)
+
+		if data.Count() != len(values) || data.Levels() != c.levels {
+			t.Fatalf("capacity %d, factor %d: expected %d values in %d "+
+				"levels, got %d in %d", c.capacity, c.factor, len(values),
+				c.levels, data.Count(), data.Levels())
+		}
+
+		width := 1
+		for level := 0; level < data.Levels(); level++ {
+
+			spans := data.Level(level)
+
+			// spans are contiguous, complete but for the last and bounded
+			// by the capacity
+			if len(spans) == 0 || len(spans) > c.capacity+1 {
+				t.Fatalf("unexpected number of spans at level %d: %d",
+					level, len(spans))
+			}
+
+			for i, span := range spans {
+				if i > 0 && span.Start != spans[i-1].End {
+					t.Fatalf("level %d: span %d is not contiguous", level,
+						i)
+				}
+				if i < len(spans)-1 && span.End-span.Start != width {
+					t.Fatalf("level %d: expected width %d, got %d", level,
+						width, span.End-span.Start)
+				}
+				assertSpan(t, values, span)
+			}
+
+			if spans[len(spans)-1].End != len(values) {
+				t.Fatalf("level %d does not reach the latest value", level)
+			}
+
+			width *= c.factor
+
+		}
+
+		// the coarsest level covers the entire history
+		if spans := data.Level(data.Levels() - 1); spans[0].Start != 0 {
+			t.Fatalf("expected the coarsest level to start at 0, got %d",
+				spans[0].Start)
+		}
+
+	}
+
+}
+
+// TestDownsampleQuery tests querying a downsampled view at different zoom
+// levels.
+func TestDownsampleQuery(t *testing.T) {
+
+	values := make([]float64, 1000)
+	for i := range values {
+		values[i] = float64(i)
+	}
+
+	data := readDownsampled(t, values, 16, 4)
+
+	// recent values are returned at full resolution
+	spans := data.Query(990, 0, 10)
+	if len(spans) != 10 || spans[0].Start != 990 || spans[9].End != 1000 ||
+		spans[0].Min != 990 {
+		t.Fatalf("unexpected recent spans: %+v", spans)
+	}
+
+	// older ranges come from coarser levels
+	spans = data.Query(0, 500, 10)
+	if len(spans) == 0 || len(spans) > 10 || spans[0].Start != 0 ||
+		spans[len(spans)-1].End < 500 {
+		t.Fatalf("unexpected spans: %+v", spans)
+	}
+
+	// the entire history is merged to fit
+	spans = data.Query(0, 0, 5)
+	if len(spans) == 0 || len(spans) > 5 {
+		t.Fatalf("expected at most 5 spans, got %d", len(spans))
+	}
+
+	min, max, count := gomath.Inf(1), gomath.Inf(-1), 0
+	for i, span := range spans {
+		if i > 0 && span.Start != spans[i-1].End {
+			t.Fatalf("span %d is not contiguous", i)
+		}
+		assertSpan(t, values, span)
+		min, max = gomath.Min(min, span.Min), gomath.Max(max, span.Max)
+		count += span.Count
+	}
+
+	if min != 0 || max != 999 || count != 1000 {
+		t.Fatalf("expected the entire history, got %f to %f in %d values",
+			min, max, count)
+	}
+
+	if util.CompareFloat(spans[0].Mean(), spans[0].Sum/float64(
+		spans[0].Count)) != 0 {
+		t.Fatalf("unexpected mean: %f", spans[0].Mean())
+	}
+
+	for _, q := range [][3]int{{10, 5, 5}, {0, 0, 0}, {2000, 0, 5}} {
+		if spans := data.Query(q[0], q[1], q[2]); spans != nil {
+			t.Fatalf("expected no spans for query %v, got %v", q, spans)
+		}
+	}
+
+}
+
+// TestDownsampleNotReady tests that values that are not ready hold their
+// positions.
+func TestDownsampleNotReady(t *testing.T) {
+
+	data := readDownsampled(t, []float64{gomath.NaN(), gomath.NaN(), 1, 2},
+		2, 2)
+
+	spans := data.Level(1)
+	if len(spans) != 2 || spans[0].Count != 0 ||
+		!gomath.IsNaN(spans[0].Min) || !gomath.IsNaN(spans[0].Mean()) ||
+		spans[1].Count != 2 || spans[1].First != 1 || spans[1].Last != 2 {
+		t.Fatalf("unexpected spans: %+v", spans)
+	}
+
+}
+
+// TestDownsampleOutputInvalid tests rejecting invalid parameters.
+func TestDownsampleOutputInvalid(t *testing.T) {
+
+	for _, c := range [][2]int{{8, 1}, {2, 4}} {
+		if _, err := output.NewDownsampleOutput(input.NewListStream(nil),
+			c[0], c[1]); err == nil {
+			t.Errorf("expected error for capacity %d, factor %d", c[0],
+				c[1])
+		}
+	}
+
+}
+
+// TestDownsampleConcurrent tests reading the downsampled view while values
+// are being read.
+func TestDownsampleConcurrent(t *testing.T) {
+
+	values := make([]float64, 5000)
+	for i := range values {
+		values[i] = float64(i)
+	}
+
+	do, err := output.NewDownsampleOutput(input.NewListStream(values), 8, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer do.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+
+		defer wg.Done()
+
+		for {
+			if _, err := do.Next(); err == stream.ErrEndOfStream {
+				return
+			}
+		}
+
+	}()
+
+	// every view covers the values read when it was taken exactly once
+	for data := do.GetData(); data.Count() < len(values); data = do.GetData() {
+
+		spans := data.Level(data.Levels() - 1)
+
+		count := 0
+		for i, span := range spans {
+			if i > 0 && span.Start != spans[i-1].End {
+				t.Fatalf("span %d is not contiguous", i)
+			}
+			count += span.Count
+		}
+
+		if count != data.Count() {
+			t.Fatalf("expected %d values, got %d", data.Count(), count)
+		}
+
+	}
+
+	wg.Wait()
+
+}
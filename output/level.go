@@ -0,0 +1,79 @@
+package output
+
+import (
+	"sort"
+	"sync/atomic"
+
+	"github.com/bsladewski/lapis/stream"
+)
+
+// activeLevels is used to retrieve the levels that are currently active in a
+// level stream.
+type activeLevels struct {
+	levels atomic.Pointer[[]stream.Level]
+	in     stream.LevelStream
+}
+
+// NewActiveLevelOutput constructs an output that compiles the levels that are
+// currently active in a level stream; the data is returned as an array of
+// levels ordered by price descending.
+func NewActiveLevelOutput(in stream.LevelStream) LevelOutput[[]stream.Level] {
+
+	a := &activeLevels{
+		in: in,
+	}
+
+	a.levels.Store(&[]stream.Level{})
+
+	return a
+
+}
+
+func (a *activeLevels) Next() (stream.Level, error) {
+
+	// get the next change to the active levels from the input stream
+	level, err := a.in.Next()
+	if err != nil {
+		return stream.Level{}, err
+	}
+
+	// the active levels are replaced rather than modified so that snapshots
+	// may be read while levels change
+	current := *a.levels.Load()
+	levels := make([]stream.Level, 0, len(current)+1)
+
+	for _, active := range current {
+		if !level.Active && active.Kind == level.Kind &&
+			active.Price == level.Price &&
+			active.Timestamp.Equal(level.Timestamp) {
+			continue
+		}
+		levels = append(levels, active)
+	}
+
+	if level.Active {
+		levels = append(levels, level)
+	}
+
+	a.levels.Store(&levels)
+
+	// return the input level
+	return level, nil
+
+}
+
+func (a *activeLevels) GetData() []stream.Level {
+
+	data := append([]stream.Level{}, *a.levels.Load()...)
+
+	sort.SliceStable(data, func(i, j int) bool {
+		return data[i].Price > data[j].Price
+	})
+
+	return data
+
+}
+
+func (a *activeLevels) Close() {
+	a.in.Close()
+}
@@ -4,7 +4,63 @@ import "github.com/bsladewski/lapis/stream"
 
 // Output functions as a stream that passes data through while also compiling
 // data to be output.
-type Output interface {
+type Output[T any] interface {
 	stream.Stream
-	GetData() (interface{}, error)
+	// GetData returns a snapshot of the data compiled so far; it may be called
+	// concurrently with Next without blocking it.
+	GetData() T
+}
+
+// MultiOutput functions as a multi stream that passes sets of values through
+// while also compiling data to be output for every component.
+type MultiOutput[T any] interface {
+	stream.MultiStream
+	// GetData returns a snapshot of the data compiled so far; it may be called
+	// concurrently with Next without blocking it.
+	GetData() T
+}
+
+// CandleOutput functions as a candle stream that passes candles through while
+// also compiling data to be output.
+type CandleOutput[T any] interface {
+	stream.CandleStream
+	// GetData returns a snapshot of the data compiled so far; it may be called
+	// concurrently with Next without blocking it.
+	GetData() T
+}
+
+// SinkOutput is an output that writes the values of a stream to a destination;
+// the output data is the number of values written.
+type SinkOutput interface {
+	Output[int]
+	// Err returns the first error encountered writing to the destination,
+	// including an error encountered when the output is closed.
+	Err() error
+}
+
+// MultiSinkOutput is a multi output that writes the values of a multi stream
+// to a destination; the output data is the number of values written.
+type MultiSinkOutput interface {
+	MultiOutput[int]
+	// Err returns the first error encountered writing to the destination,
+	// including an error encountered when the output is closed.
+	Err() error
+}
+
+// CandleSinkOutput is a candle output that writes the candles of a candle
+// stream to a destination; the output data is the number of candles written.
+type CandleSinkOutput interface {
+	CandleOutput[int]
+	// Err returns the first error encountered writing to the destination,
+	// including an error encountered when the output is closed.
+	Err() error
+}
+
+// LevelOutput functions as a level stream that passes changes to levels
+// through while also compiling data to be output.
+type LevelOutput[T any] interface {
+	stream.LevelStream
+	// GetData returns a snapshot of the data compiled so far; it may be called
+	// concurrently with Next without blocking it.
+	GetData() T
 }
@@ -0,0 +1,226 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// sinkBufferSize is the size of the buffer used by sinks to batch writes.
+const sinkBufferSize = 64 * 1024
+
+// recordWriter is written to by sinks one encoded record at a time.
+type recordWriter interface {
+	io.Writer
+	// begin prepares to write a record of the specified size for a sample
+	// taken at the specified time, reporting whether the record will begin a
+	// new file.
+	begin(timestamp time.Time, size int) (bool, error)
+	// Flush writes any buffered records.
+	Flush() error
+	// Close flushes any buffered records and closes any file opened by the
+	// record writer.
+	Close() error
+}
+
+// bufferedWriter is a record writer that buffers records written to an
+// underlying writer; the underlying writer belongs to the caller and is never
+// closed.
+type bufferedWriter struct {
+	*bufio.Writer
+	started bool
+}
+
+// newRecordWriter returns a record writer for the supplied writer; a rotating
+// file is a record writer itself, any other writer is buffered.
+func newRecordWriter(w io.Writer) recordWriter {
+
+	if r, ok := w.(*RotatingFile); ok {
+		return r
+	}
+
+	return &bufferedWriter{
+		Writer: bufio.NewWriterSize(w, sinkBufferSize),
+	}
+
+}
+
+func (b *bufferedWriter) begin(timestamp time.Time, size int) (bool, error) {
+
+	started := b.started
+	b.started = true
+
+	return !started, nil
+
+}
+
+func (b *bufferedWriter) Close() error {
+	return b.Flush()
+}
+
+// FileRotation configures when a rotating file starts a new file.
+type FileRotation struct {
+	// MaxBytes is the largest size of a file; a record that would grow the
+	// file beyond this size starts a new file. Zero disables rotation by size.
+	MaxBytes int64
+	// Interval starts a new file for each interval of sample timestamps,
+	// measured from the zero time; an interval of 24 hours starts a new file
+	// each day at midnight UTC. Zero disables rotation by date.
+	Interval time.Duration
+}
+
+// A RotatingFile is a buffered file writer that starts new files as
+// configured by a file rotation; it is used as the writer of a sink.
+type RotatingFile struct {
+	path     string
+	rotation FileRotation
+	file     *os.File
+	buffer   *bufio.Writer
+	size     int64
+	stamp    time.Time
+	sequence int
+}
+
+// NewRotatingFile returns a rotating file that names each file it starts by
+// inserting the start of its interval, or the timestamp of the first sample
+// if rotation by date is disabled, and a sequence number before the extension
+// of the specified path, e.g. samples-20200101T000000Z-0.csv. No file is
+// created until the first record is written and existing files are never
+// overwritten.
+func NewRotatingFile(path string, rotation FileRotation) *RotatingFile {
+
+	return &RotatingFile{
+		path:     path,
+		rotation: rotation,
+	}
+
+}
+
+func (r *RotatingFile) begin(timestamp time.Time, size int) (bool, error) {
+
+	if r.rotation.MaxBytes < 0 || r.rotation.Interval < 0 {
+		return false, fmt.Errorf("file rotation cannot be negative")
+	}
+
+	if r.file == nil {
+		r.stamp = timestamp
+		if r.rotation.Interval > 0 {
+			r.stamp = timestamp.Truncate(r.rotation.Interval)
+		}
+		return true, r.open()
+	}
+
+	// start a new file for each interval
+	if r.rotation.Interval > 0 {
+		if stamp := timestamp.Truncate(r.rotation.Interval); !stamp.Equal(
+			r.stamp) {
+			r.stamp, r.sequence = stamp, 0
+			return true, r.open()
+		}
+	}
+
+	// start a new file if the record would grow the file beyond its largest
+	// size; a record larger than a file is written to a file of its own
+	if r.rotation.MaxBytes > 0 && r.size > 0 &&
+		r.size+int64(size) > r.rotation.MaxBytes {
+		r.sequence++
+		return true, r.open()
+	}
+
+	return false, nil
+
+}
+
+// open closes the current file and opens the next file.
+func (r *RotatingFile) open() error {
+
+	if err := r.Close(); err != nil {
+		return err
+	}
+
+	ext := filepath.Ext(r.path)
+	stem := strings.TrimSuffix(r.path, ext)
+	stamp := r.stamp.UTC().Format("20060102T150405Z")
+
+	// skip names used by files that already exist
+	for {
+
+		name := fmt.Sprintf("%s-%s-%d%s", stem, stamp, r.sequence, ext)
+
+		file, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY,
+			0644)
+		if os.IsExist(err) {
+			r.sequence++
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		r.file = file
+		r.buffer = bufio.NewWriterSize(file, sinkBufferSize)
+		r.size = 0
+
+		return nil
+
+	}
+
+}
+
+// Name returns the name of the current file, or an empty string if no file
+// has been started.
+func (r *RotatingFile) Name() string {
+
+	if r.file == nil {
+		return ""
+	}
+
+	return r.file.Name()
+
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+
+	if r.file == nil {
+		return 0, fmt.Errorf("rotating file has not been started")
+	}
+
+	n, err := r.buffer.Write(p)
+	r.size += int64(n)
+
+	return n, err
+
+}
+
+// Flush writes any buffered records to the current file.
+func (r *RotatingFile) Flush() error {
+
+	if r.file == nil {
+		return nil
+	}
+
+	return r.buffer.Flush()
+
+}
+
+// Close flushes any buffered records and closes the current file; the next
+// record written starts a new file.
+func (r *RotatingFile) Close() error {
+
+	if r.file == nil {
+		return nil
+	}
+
+	err := r.buffer.Flush()
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+
+	r.file, r.buffer = nil, nil
+
+	return err
+
+}
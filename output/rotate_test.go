@@ -0,0 +1,162 @@
+package output_test
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/output"
+)
+
+// readFiles returns the contents of the files in a directory ordered by name.
+func readFiles(t *testing.T, dir string) ([]string, []string) {
+
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names, contents []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents = append(contents, string(data))
+	}
+
+	return names, contents
+
+}
+
+// TestRotatingFileInterval tests starting a new file for each day of samples.
+func TestRotatingFileInterval(t *testing.T) {
+
+	dir := t.TempDir()
+
+	// samples are six hours apart so each day holds four samples
+	next := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time {
+		now := next
+		next = next.Add(6 * time.Hour)
+		return now
+	}
+
+	so := output.NewCSVOutput(input.NewListStream(
+		[]float64{1, 2, 3, 4, 5, 6, 7}), "value",
+		output.NewRotatingFile(filepath.Join(dir, "samples.csv"),
+			output.FileRotation{Interval: 24 * time.Hour}), clock)
+
+	drain(t, so)
+	so.Close()
+
+	names, contents := readFiles(t, dir)
+
+	expectedNames := []string{
+		"samples-20200101T000000Z-0.csv",
+		"samples-20200102T000000Z-0.csv",
+		"samples-20200103T000000Z-0.csv",
+	}
+	expectedRows := []int{2, 4, 1}
+
+	if strings.Join(names, ",") != strings.Join(expectedNames, ",") {
+		t.Fatalf("expected files %v, got %v", expectedNames, names)
+	}
+
+	// every file begins with the header
+	for i, content := range contents {
+		lines := strings.Split(strings.TrimSpace(content), "\n")
+		if lines[0] != "timestamp,value" ||
+			len(lines)-1 != expectedRows[i] {
+			t.Fatalf("file %s; unexpected content:\n%s", names[i], content)
+		}
+	}
+
+}
+
+// TestRotatingFileSize tests starting a new file when a file reaches its
+// largest size without overwriting existing files.
+func TestRotatingFileSize(t *testing.T) {
+
+	dir := t.TempDir()
+
+	// a file left by a previous run must not be overwritten
+	existing := filepath.Join(dir, "samples-20200101T000000Z-0.jsonl")
+	if err := os.WriteFile(existing, []byte("previous\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// each sample is 42 bytes, so two fit in a file
+	so := output.NewJSONLinesOutput(input.NewListStream(
+		[]float64{1, 2, 3, 4, 5}), "value",
+		output.NewRotatingFile(filepath.Join(dir, "samples.jsonl"),
+			output.FileRotation{MaxBytes: 100}), hourlyClock())
+
+	drain(t, so)
+	so.Close()
+
+	names, contents := readFiles(t, dir)
+
+	if len(names) != 4 || contents[0] != "previous\n" {
+		t.Fatalf("expected the previous file and three new files, got %v",
+			names)
+	}
+
+	for i, content := range contents[1:] {
+
+		lines := strings.Split(strings.TrimSpace(content), "\n")
+
+		expected := 2
+		if i == 2 {
+			expected = 1
+		}
+
+		if len(lines) != expected || len(content) > 100 {
+			t.Fatalf("file %s; unexpected content:\n%s", names[i+1], content)
+		}
+
+	}
+
+}
+
+// TestRotatingFileSizeHeader tests that the header only counts towards the
+// size of the file it begins.
+func TestRotatingFileSizeHeader(t *testing.T) {
+
+	dir := t.TempDir()
+
+	// the header is 16 bytes and each sample is 23 bytes, so the header and
+	// two samples fill a file exactly
+	so := output.NewCSVOutput(input.NewListStream(
+		[]float64{1, 2, 3, 4}), "value",
+		output.NewRotatingFile(filepath.Join(dir, "samples.csv"),
+			output.FileRotation{MaxBytes: 62}), hourlyClock())
+
+	drain(t, so)
+	so.Close()
+
+	names, contents := readFiles(t, dir)
+
+	if len(names) != 2 {
+		t.Fatalf("expected two files, got %v", names)
+	}
+
+	for i, content := range contents {
+		lines := strings.Split(strings.TrimSpace(content), "\n")
+		if len(lines) != 3 || len(content) != 62 {
+			t.Fatalf("file %s; unexpected content:\n%s", names[i], content)
+		}
+	}
+
+}
@@ -0,0 +1,372 @@
+package output
+
+import (
+	"database/sql"
+	"fmt"
+	gomath "math"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/bsladewski/lapis/stream"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// seriesPoint is a single value of a named series as stored in the database;
+// NaN values are stored as null.
+type seriesPoint struct {
+	ID        uint            `gorm:"primary_key"`
+	Series    string          `gorm:"index:series_timestamp;not null"`
+	Timestamp time.Time       `gorm:"index:series_timestamp;not null"`
+	Value     sql.NullFloat64 `json:"value"`
+}
+
+// TableName names the table that stores series points.
+func (seriesPoint) TableName() string {
+	return "series_points"
+}
+
+// A SeriesPoint is a single timestamped value of a named series.
+type SeriesPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// MigrateSeries creates or updates the table that stores series points.
+func MigrateSeries(db *gorm.DB) error {
+
+	return db.AutoMigrate(seriesPoint{}).Error
+
+}
+
+// QuerySeries returns the points of the named series with timestamps from the
+// start of the range up to but not including the end of the range, ordered by
+// timestamp; a zero start or end leaves that side of the range unbounded.
+func QuerySeries(db *gorm.DB, name string, from,
+	to time.Time) ([]SeriesPoint, error) {
+
+	query := db.Where("series = ?", name)
+	if !from.IsZero() {
+		query = query.Where("timestamp >= ?", from.UTC())
+	}
+	if !to.IsZero() {
+		query = query.Where("timestamp < ?", to.UTC())
+	}
+
+	var stored []seriesPoint
+	if err := query.Order("timestamp, id").Find(&stored).Error; err != nil {
+		return nil, fmt.Errorf("query series %s, err: %v", name, err)
+	}
+
+	points := make([]SeriesPoint, len(stored))
+	for i, point := range stored {
+		points[i] = SeriesPoint{
+			Timestamp: point.Timestamp,
+			Value:     gomath.NaN(),
+		}
+		if point.Value.Valid {
+			points[i].Value = point.Value.Float64
+		}
+	}
+
+	return points, nil
+
+}
+
+// ListSeries returns the names of the series stored in the database in
+// alphabetical order.
+func ListSeries(db *gorm.DB) ([]string, error) {
+
+	var names []string
+	if err := db.Model(seriesPoint{}).Order("series").
+		Pluck("DISTINCT series", &names).Error; err != nil {
+		return nil, fmt.Errorf("list series, err: %v", err)
+	}
+
+	return names, nil
+
+}
+
+// seriesInsertRows is the largest number of points inserted by a single
+// statement, keeping the parameters of the statement within the SQLite limit
+// of 999.
+const seriesInsertRows = 333
+
+// insertPoints inserts points into the series table with a single statement;
+// the points are not modified, so a batch that fails to commit can be inserted
+// again without reusing primary keys.
+func insertPoints(tx *gorm.DB, points []seriesPoint) error {
+
+	rows := make([]string, len(points))
+	values := make([]interface{}, 0, 3*len(points))
+	for i, point := range points {
+		rows[i] = "(?, ?, ?)"
+		values = append(values, point.Series, point.Timestamp, point.Value)
+	}
+
+	quote := tx.Dialect().Quote
+
+	return tx.Exec(fmt.Sprintf("INSERT INTO %s (%s, %s, %s) VALUES %s",
+		quote(seriesPoint{}.TableName()), quote("series"), quote("timestamp"),
+		quote("value"), strings.Join(rows, ", ")), values...).Error
+
+}
+
+// seriesWriter writes points of named series to the database in batches.
+type seriesWriter struct {
+	db       *gorm.DB
+	batch    int
+	clock    func() time.Time
+	pending  []seriesPoint
+	migrated bool
+	written  atomic.Int64
+	err      sinkError
+}
+
+// newSeriesWriter returns a writer that commits points to the database in
+// batches of the specified size.
+func newSeriesWriter(db *gorm.DB, batch int,
+	clock func() time.Time) *seriesWriter {
+
+	if clock == nil {
+		clock = time.Now
+	}
+
+	return &seriesWriter{
+		db:    db,
+		batch: batch,
+		clock: clock,
+	}
+
+}
+
+// add queues the values of the named series for a single sample, committing
+// the queued points once the batch is full.
+func (s *seriesWriter) add(names []string, values []float64) error {
+
+	timestamp := s.clock().UTC()
+
+	for i, name := range names {
+		s.pending = append(s.pending, seriesPoint{
+			Series:    name,
+			Timestamp: timestamp,
+			Value: sql.NullFloat64{
+				Float64: values[i],
+				Valid:   !gomath.IsNaN(values[i]),
+			},
+		})
+	}
+
+	if len(s.pending) >= s.batch*len(names) {
+		return s.flush()
+	}
+
+	return nil
+
+}
+
+// flush commits the queued points in a single transaction, recording any
+// error; points that fail to commit remain queued.
+func (s *seriesWriter) flush() error {
+	return s.err.record(s.commit())
+}
+
+// commit inserts the queued points in a single transaction.
+func (s *seriesWriter) commit() error {
+
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	if !s.migrated {
+		if err := MigrateSeries(s.db); err != nil {
+			return errors.WithStack(err)
+		}
+		s.migrated = true
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return errors.WithStack(tx.Error)
+	}
+
+	for start := 0; start < len(s.pending); start += seriesInsertRows {
+
+		end := start + seriesInsertRows
+		if end > len(s.pending) {
+			end = len(s.pending)
+		}
+
+		if err := insertPoints(tx, s.pending[start:end]); err != nil {
+			tx.Rollback()
+			return errors.WithStack(err)
+		}
+
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	s.written.Add(int64(len(s.pending)))
+	s.pending = s.pending[:0]
+
+	return nil
+
+}
+
+// seriesOutput is used to persist the values of a stream as a named series.
+type seriesOutput struct {
+	name   string
+	writer *seriesWriter
+	in     stream.Stream
+}
+
+// NewSeriesOutput constructs an output that persists each value of a stream
+// as a point of the named series, committing points to the database in
+// transactions of batch points and when the stream ends or is closed; the
+// output data is the number of points committed. Points are timestamped by
+// the supplied clock, or by the current time if clock is nil; use the Now
+// method of a candle clock to timestamp points by the time of the candles they
+// were calculated from, so that the time ranges of QuerySeries are meaningful
+// when replaying historical candles. NaN values are stored as null.
+//
+// The series table is created when the first batch is committed, in the
+// database of lapis events if db is event.DB(); see QuerySeries for reading a
+// series back. Errors committing points are returned by Next, and Err also
+// reports an error committing the remaining points when the output is closed.
+func NewSeriesOutput(in stream.Stream, db *gorm.DB, name string, batch int,
+	clock func() time.Time) SinkOutput {
+
+	return &seriesOutput{
+		name:   name,
+		writer: newSeriesWriter(db, batch, clock),
+		in:     in,
+	}
+
+}
+
+func (s *seriesOutput) Next() (float64, error) {
+
+	if s.writer.batch <= 0 {
+		return 0.0, errors.New("series batch size cannot be negative or zero")
+	}
+
+	// get the next value from the input stream
+	value, err := s.in.Next()
+	if err == stream.ErrNotReady {
+		// pass values that are not ready through without recording them
+		return value, err
+	} else if err == stream.ErrEndOfStream {
+		// commit any points remaining at the end of the stream
+		if err := s.writer.flush(); err != nil {
+			return 0.0, err
+		}
+		return 0.0, stream.ErrEndOfStream
+	} else if err != nil {
+		return 0.0, err
+	}
+
+	if err := s.writer.add([]string{s.name}, []float64{value}); err != nil {
+		return 0.0, err
+	}
+
+	// return the input value
+	return value, nil
+
+}
+
+func (s *seriesOutput) GetData() int {
+	return int(s.writer.written.Load())
+}
+
+func (s *seriesOutput) Close() {
+	s.in.Close()
+	s.writer.flush()
+}
+
+func (s *seriesOutput) Err() error {
+	return s.writer.err.get()
+}
+
+// multiSeriesOutput is used to persist the values of each component of a
+// multi stream as a named series.
+type multiSeriesOutput struct {
+	names  []string
+	writer *seriesWriter
+	in     stream.MultiStream
+}
+
+// NewMultiSeriesOutput constructs an output that persists the values of each
+// component of a multi stream as a series named by joining the specified name
+// and the component with a dot, e.g. macd.signal. The output behaves as
+// described by NewSeriesOutput and the output data is the number of points
+// committed across all components.
+func NewMultiSeriesOutput(in stream.MultiStream, db *gorm.DB, name string,
+	batch int, clock func() time.Time) MultiSinkOutput {
+
+	var names []string
+	for _, component := range in.Components() {
+		names = append(names, name+"."+component)
+	}
+
+	return &multiSeriesOutput{
+		names:  names,
+		writer: newSeriesWriter(db, batch, clock),
+		in:     in,
+	}
+
+}
+
+func (s *multiSeriesOutput) Components() []string {
+	return s.in.Components()
+}
+
+func (s *multiSeriesOutput) Next() ([]float64, error) {
+
+	if s.writer.batch <= 0 {
+		return nil, errors.New("series batch size cannot be negative or zero")
+	}
+
+	// get the next set of values from the input stream
+	values, err := s.in.Next()
+	if err == stream.ErrNotReady {
+		// pass values that are not ready through without recording them
+		return values, err
+	} else if err == stream.ErrEndOfStream {
+		// commit any points remaining at the end of the stream
+		if err := s.writer.flush(); err != nil {
+			return nil, err
+		}
+		return nil, stream.ErrEndOfStream
+	} else if err != nil {
+		return nil, err
+	}
+
+	if len(values) != len(s.names) {
+		return nil, fmt.Errorf("expected %d values, got %d", len(s.names),
+			len(values))
+	}
+
+	if err := s.writer.add(s.names, values); err != nil {
+		return nil, err
+	}
+
+	// return the input values
+	return values, nil
+
+}
+
+func (s *multiSeriesOutput) GetData() int {
+	return int(s.writer.written.Load())
+}
+
+func (s *multiSeriesOutput) Close() {
+	s.in.Close()
+	s.writer.flush()
+}
+
+func (s *multiSeriesOutput) Err() error {
+	return s.writer.err.get()
+}
@@ -0,0 +1,230 @@
+package output_test
+
+import (
+	gomath "math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/output"
+	"github.com/bsladewski/lapis/stream"
+	"github.com/jinzhu/gorm"
+	_ "github.com/mattn/go-sqlite3" // support for SQLite database
+)
+
+// openSeriesDB opens a temporary SQLite database.
+func openSeriesDB(t *testing.T) *gorm.DB {
+
+	t.Helper()
+
+	db, err := gorm.Open("sqlite3",
+		filepath.Join(t.TempDir(), "series.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	return db
+
+}
+
+// TestSeriesOutput tests persisting a stream as a series and reading it back
+// by time range.
+func TestSeriesOutput(t *testing.T) {
+
+	db := openSeriesDB(t)
+
+	so := output.NewSeriesOutput(input.NewListStream(
+		[]float64{1, 2, gomath.NaN(), 4, 5}), db, "close", 2, hourlyClock())
+	defer so.Close()
+
+	// points are committed in batches of two
+	for i, expected := range []int{0, 2, 2, 4, 4} {
+		if _, err := so.Next(); err != nil {
+			t.Fatalf("index %d; err: %v", i, err)
+		}
+		if so.GetData() != expected {
+			t.Fatalf("index %d; expected %d points, got %d", i, expected,
+				so.GetData())
+		}
+	}
+
+	// the remaining point is committed at the end of the stream
+	drain(t, so)
+	if so.GetData() != 5 {
+		t.Fatalf("expected 5 points, got %d", so.GetData())
+	}
+
+	start := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	points, err := output.QuerySeries(db, "close", start.Add(time.Hour),
+		start.Add(4*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []float64{2, gomath.NaN(), 4}
+	if len(points) != len(expected) {
+		t.Fatalf("expected %d points, got %v", len(expected), points)
+	}
+
+	for i, point := range points {
+
+		if !point.Timestamp.Equal(start.Add(time.Duration(i+1) * time.Hour)) {
+			t.Fatalf("index %d; unexpected timestamp %v", i, point.Timestamp)
+		}
+
+		if gomath.IsNaN(expected[i]) != gomath.IsNaN(point.Value) ||
+			!gomath.IsNaN(expected[i]) && point.Value != expected[i] {
+			t.Fatalf("index %d; expected %v, got %v", i, expected[i],
+				point.Value)
+		}
+
+	}
+
+	// an unbounded range returns the whole series
+	if points, err = output.QuerySeries(db, "close", time.Time{},
+		time.Time{}); err != nil || len(points) != 5 {
+		t.Fatalf("expected 5 points, got %v, err: %v", points, err)
+	}
+
+}
+
+// TestMultiSeriesOutput tests persisting each component of a multi stream as
+// a series.
+func TestMultiSeriesOutput(t *testing.T) {
+
+	db := openSeriesDB(t)
+
+	so := output.NewMultiSeriesOutput(input.NewMultiListStream(
+		[]string{"upper", "lower"}, [][]float64{{2, 1}, {4, 3}, {6, 5}}), db,
+		"bands", 10, hourlyClock())
+
+	drainMulti(t, so)
+	so.Close()
+
+	if so.GetData() != 6 {
+		t.Fatalf("expected 6 points, got %d", so.GetData())
+	}
+
+	names, err := output.ListSeries(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(names) != 2 || names[0] != "bands.lower" ||
+		names[1] != "bands.upper" {
+		t.Fatalf("expected bands.lower and bands.upper, got %v", names)
+	}
+
+	points, err := output.QuerySeries(db, "bands.lower", time.Time{},
+		time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, expected := range []float64{1, 3, 5} {
+		if points[i].Value != expected {
+			t.Fatalf("index %d; expected %v, got %v", i, expected,
+				points[i].Value)
+		}
+	}
+
+}
+
+// TestSeriesOutputCandleClock tests timestamping points by the candles they
+// were calculated from.
+func TestSeriesOutputCandleClock(t *testing.T) {
+
+	db := openSeriesDB(t)
+
+	start := time.Date(2019, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	var candles []stream.Candle
+	for i := 0; i < 3; i++ {
+		candles = append(candles, stream.Candle{
+			Timestamp: start.Add(time.Duration(i) * 24 * time.Hour),
+			Close:     float64(i + 1),
+		})
+	}
+
+	clock := input.NewCandleClock(input.NewCandleListStream(candles))
+	so := output.NewSeriesOutput(input.NewCandleFieldStream(clock,
+		input.FieldClose), db, "close", 2, clock.Now)
+
+	drain(t, so)
+	so.Close()
+
+	points, err := output.QuerySeries(db, "close", start.Add(time.Hour),
+		time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(points) != 2 {
+		t.Fatalf("expected 2 points, got %v", points)
+	}
+
+	for i, point := range points {
+		if !point.Timestamp.Equal(candles[i+1].Timestamp) ||
+			point.Value != candles[i+1].Close {
+			t.Fatalf("index %d; expected %v at %v, got %v", i,
+				candles[i+1].Close, candles[i+1].Timestamp, point)
+		}
+	}
+
+}
+
+// TestSeriesOutputRetry tests that a batch that fails to commit is reported
+// and committed again with the next batch.
+func TestSeriesOutputRetry(t *testing.T) {
+
+	db := openSeriesDB(t)
+
+	so := output.NewSeriesOutput(input.NewListStream(
+		[]float64{1, 2, 3, 4, 5}), db, "close", 2, hourlyClock())
+
+	for i := 0; i < 2; i++ {
+		if _, err := so.Next(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// commits fail while the series table is missing
+	if err := db.DropTable("series_points").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	so.Next()
+	if _, err := so.Next(); err == nil {
+		t.Fatal("expected an error committing a batch")
+	}
+
+	if so.Err() == nil {
+		t.Fatal("expected the error to be reported")
+	}
+
+	if err := output.MigrateSeries(db); err != nil {
+		t.Fatal(err)
+	}
+
+	// the failed batch is committed with the remaining point
+	drain(t, so)
+	so.Close()
+
+	points, err := output.QuerySeries(db, "close", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(points) != 3 || points[0].Value != 3 || points[2].Value != 5 {
+		t.Fatalf("expected points 3 to 5, got %v", points)
+	}
+
+	if so.GetData() != 5 {
+		t.Fatalf("expected 5 points, got %d", so.GetData())
+	}
+
+}
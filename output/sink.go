@@ -0,0 +1,411 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	gomath "math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bsladewski/lapis/stream"
+	"github.com/pkg/errors"
+)
+
+// sinkFlushInterval is the longest time a sink holds buffered records before
+// flushing them when a new sample is written.
+const sinkFlushInterval = time.Second
+
+// sinkEncoder encodes the header and samples written by a sink.
+type sinkEncoder interface {
+	// header encodes the header that begins each file, if any.
+	header(columns []string) ([]byte, error)
+	// encode encodes a single sample.
+	encode(columns []string, timestamp time.Time,
+		values []float64) ([]byte, error)
+}
+
+// csvEncoder encodes samples as comma separated values.
+type csvEncoder struct{}
+
+func (csvEncoder) header(columns []string) ([]byte, error) {
+	return csvRecord(append([]string{"timestamp"}, columns...))
+}
+
+func (csvEncoder) encode(columns []string, timestamp time.Time,
+	values []float64) ([]byte, error) {
+
+	record := []string{timestamp.Format(time.RFC3339Nano)}
+	for _, value := range values {
+		record = append(record, strconv.FormatFloat(value, 'g', -1, 64))
+	}
+
+	return csvRecord(record)
+
+}
+
+// csvRecord encodes a single CSV record.
+func csvRecord(record []string) ([]byte, error) {
+
+	var buffer bytes.Buffer
+
+	w := csv.NewWriter(&buffer)
+	if err := w.Write(record); err != nil {
+		return nil, err
+	}
+	w.Flush()
+
+	return buffer.Bytes(), w.Error()
+
+}
+
+// jsonLinesEncoder encodes samples as JSON objects, one per line.
+type jsonLinesEncoder struct{}
+
+func (jsonLinesEncoder) header(columns []string) ([]byte, error) {
+	return nil, nil
+}
+
+func (jsonLinesEncoder) encode(columns []string, timestamp time.Time,
+	values []float64) ([]byte, error) {
+
+	var buffer bytes.Buffer
+
+	buffer.WriteString(`{"timestamp":`)
+	encoded, err := json.Marshal(timestamp.Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, err
+	}
+	buffer.Write(encoded)
+
+	for i, column := range columns {
+
+		if encoded, err = json.Marshal(column); err != nil {
+			return nil, err
+		}
+
+		buffer.WriteByte(',')
+		buffer.Write(encoded)
+		buffer.WriteByte(':')
+
+		// JSON cannot represent NaN or infinite values
+		if gomath.IsNaN(values[i]) || gomath.IsInf(values[i], 0) {
+			buffer.WriteString("null")
+			continue
+		}
+
+		buffer.WriteString(strconv.FormatFloat(values[i], 'g', -1, 64))
+
+	}
+
+	buffer.WriteString("}\n")
+
+	return buffer.Bytes(), nil
+
+}
+
+// sinkError records the first error encountered by an output writing to a
+// destination; it may be read concurrently with the output.
+type sinkError struct {
+	mu  sync.Mutex
+	err error
+}
+
+// record records an error if no error has been recorded, returning the
+// supplied error.
+func (e *sinkError) record(err error) error {
+
+	if err == nil {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.err == nil {
+		e.err = err
+	}
+
+	return err
+
+}
+
+// get returns the first error recorded, if any.
+func (e *sinkError) get() error {
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.err
+
+}
+
+// sink writes samples to a record writer.
+type sink struct {
+	columns []string
+	encoder sinkEncoder
+	clock   func() time.Time
+	w       recordWriter
+	flushed time.Time
+	samples atomic.Int64
+	err     sinkError
+}
+
+// newSink returns a sink that writes samples of the specified columns to the
+// supplied writer.
+func newSink(columns []string, encoder sinkEncoder, w io.Writer,
+	clock func() time.Time) *sink {
+
+	if clock == nil {
+		clock = time.Now
+	}
+
+	return &sink{
+		columns: columns,
+		encoder: encoder,
+		clock:   clock,
+		w:       newRecordWriter(w),
+		flushed: time.Now(),
+	}
+
+}
+
+// write writes a sample of values, one for each column.
+func (s *sink) write(values []float64) error {
+
+	return s.err.record(s.writeSample(values))
+
+}
+
+// flush writes any buffered samples.
+func (s *sink) flush() error {
+
+	return s.err.record(errors.WithStack(s.w.Flush()))
+
+}
+
+// close flushes any buffered samples and closes any file opened by the record
+// writer.
+func (s *sink) close() error {
+
+	return s.err.record(errors.WithStack(s.w.Close()))
+
+}
+
+// writeSample encodes a sample of values and writes it to the record writer.
+func (s *sink) writeSample(values []float64) error {
+
+	timestamp := s.clock()
+
+	record, err := s.encoder.encode(s.columns, timestamp, values)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	header, err := s.encoder.header(s.columns)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// the header is only written when the record begins a new file, so only
+	// the record counts towards the size of the current file
+	started, err := s.w.begin(timestamp, len(record))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// each file begins with the header
+	if started && len(header) > 0 {
+		if _, err := s.w.Write(header); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	if _, err := s.w.Write(record); err != nil {
+		return errors.WithStack(err)
+	}
+
+	s.samples.Add(1)
+
+	// flush buffered samples periodically so that slow streams reach their
+	// destination promptly
+	if now := time.Now(); now.Sub(s.flushed) >= sinkFlushInterval {
+		s.flushed = now
+		if err := s.w.Flush(); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return nil
+
+}
+
+// sinkOutput is used to write the values of a stream to a sink.
+type sinkOutput struct {
+	sink *sink
+	in   stream.Stream
+}
+
+// NewCSVOutput constructs an output that writes each value of a stream as a
+// sample to the supplied writer as comma separated values, with a header
+// naming the timestamp and value columns; the output data is the number of
+// samples written. Samples are timestamped by the supplied clock, or by the
+// current time if clock is nil; use the Now method of a candle clock to
+// timestamp samples by the time of the candles they were calculated from.
+//
+// Samples are buffered and flushed when the buffer fills, when a sample is
+// written at least a second after the last flush, at the end of the stream
+// and when the output is closed; the writer itself is never closed. Use a
+// rotating file as the writer to write samples to files rotated by size or by
+// the date of their timestamps; each file begins with the header and the
+// current file is closed when the output is closed. Errors writing samples are
+// returned by Next, and Err also reports an error flushing the samples when
+// the output is closed.
+func NewCSVOutput(in stream.Stream, column string, w io.Writer,
+	clock func() time.Time) SinkOutput {
+
+	return &sinkOutput{
+		sink: newSink([]string{column}, csvEncoder{}, w, clock),
+		in:   in,
+	}
+
+}
+
+// NewJSONLinesOutput constructs an output that writes each value of a stream
+// as a sample to the supplied writer as a JSON object per line, holding the
+// timestamp and the value keyed by the column name; NaN and infinite values
+// are written as null. The output behaves as described by NewCSVOutput.
+func NewJSONLinesOutput(in stream.Stream, column string, w io.Writer,
+	clock func() time.Time) SinkOutput {
+
+	return &sinkOutput{
+		sink: newSink([]string{column}, jsonLinesEncoder{}, w, clock),
+		in:   in,
+	}
+
+}
+
+func (s *sinkOutput) Next() (float64, error) {
+
+	// get the next value from the input stream
+	value, err := s.in.Next()
+	if err == stream.ErrNotReady {
+		// pass values that are not ready through without recording them
+		return value, err
+	} else if err == stream.ErrEndOfStream {
+		// flush any samples remaining at the end of the stream
+		if err := s.sink.flush(); err != nil {
+			return 0.0, err
+		}
+		return 0.0, stream.ErrEndOfStream
+	} else if err != nil {
+		return 0.0, err
+	}
+
+	if err := s.sink.write([]float64{value}); err != nil {
+		return 0.0, err
+	}
+
+	// return the input value
+	return value, nil
+
+}
+
+func (s *sinkOutput) GetData() int {
+	return int(s.sink.samples.Load())
+}
+
+func (s *sinkOutput) Close() {
+	s.in.Close()
+	s.sink.close()
+}
+
+func (s *sinkOutput) Err() error {
+	return s.sink.err.get()
+}
+
+// multiSinkOutput is used to write the values of a multi stream to a sink.
+type multiSinkOutput struct {
+	sink *sink
+	in   stream.MultiStream
+}
+
+// NewMultiCSVOutput constructs an output that writes each set of values of a
+// multi stream as a sample to the supplied writer as comma separated values,
+// with a column for each component. The output behaves as described by
+// NewCSVOutput.
+func NewMultiCSVOutput(in stream.MultiStream, w io.Writer,
+	clock func() time.Time) MultiSinkOutput {
+
+	return &multiSinkOutput{
+		sink: newSink(in.Components(), csvEncoder{}, w, clock),
+		in:   in,
+	}
+
+}
+
+// NewMultiJSONLinesOutput constructs an output that writes each set of values
+// of a multi stream as a sample to the supplied writer as a JSON object per
+// line, with a key for each component. The output behaves as described by
+// NewJSONLinesOutput.
+func NewMultiJSONLinesOutput(in stream.MultiStream, w io.Writer,
+	clock func() time.Time) MultiSinkOutput {
+
+	return &multiSinkOutput{
+		sink: newSink(in.Components(), jsonLinesEncoder{}, w, clock),
+		in:   in,
+	}
+
+}
+
+func (s *multiSinkOutput) Components() []string {
+	return s.in.Components()
+}
+
+func (s *multiSinkOutput) Next() ([]float64, error) {
+
+	// get the next set of values from the input stream
+	values, err := s.in.Next()
+	if err == stream.ErrNotReady {
+		// pass values that are not ready through without recording them
+		return values, err
+	} else if err == stream.ErrEndOfStream {
+		// flush any samples remaining at the end of the stream
+		if err := s.sink.flush(); err != nil {
+			return nil, err
+		}
+		return nil, stream.ErrEndOfStream
+	} else if err != nil {
+		return nil, err
+	}
+
+	if len(values) != len(s.sink.columns) {
+		return nil, fmt.Errorf("expected %d values, got %d",
+			len(s.sink.columns), len(values))
+	}
+
+	if err := s.sink.write(values); err != nil {
+		return nil, err
+	}
+
+	// return the input values
+	return values, nil
+
+}
+
+func (s *multiSinkOutput) GetData() int {
+	return int(s.sink.samples.Load())
+}
+
+func (s *multiSinkOutput) Close() {
+	s.in.Close()
+	s.sink.close()
+}
+
+func (s *multiSinkOutput) Err() error {
+	return s.sink.err.get()
+}
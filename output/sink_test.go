@@ -0,0 +1,200 @@
+package output_test
+
+import (
+	"bytes"
+	"errors"
+	gomath "math"
+	"testing"
+	"time"
+
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/output"
+	"github.com/bsladewski/lapis/stream"
+)
+
+// hourlyClock returns a clock that advances an hour from midnight on the
+// first of January 2020 each time it is read.
+func hourlyClock() func() time.Time {
+
+	next := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	return func() time.Time {
+		now := next
+		next = next.Add(time.Hour)
+		return now
+	}
+
+}
+
+// drain reads a stream until the end of the stream.
+func drain(t *testing.T, s stream.Stream) {
+
+	t.Helper()
+
+	for {
+		if _, err := s.Next(); err == stream.ErrEndOfStream {
+			return
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+}
+
+// drainMulti reads a multi stream until the end of the stream.
+func drainMulti(t *testing.T, s stream.MultiStream) {
+
+	t.Helper()
+
+	for {
+		if _, err := s.Next(); err == stream.ErrEndOfStream {
+			return
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+}
+
+// TestSinkOutput tests writing the values of a stream as CSV and JSON Lines.
+func TestSinkOutput(t *testing.T) {
+
+	cases := []struct {
+		name     string
+		create   func(stream.Stream, string, *bytes.Buffer) output.Output[int]
+		expected string
+	}{
+		{"TestCSV",
+			func(in stream.Stream, column string,
+				w *bytes.Buffer) output.Output[int] {
+				return output.NewCSVOutput(in, column, w, hourlyClock())
+			},
+			"timestamp,\"close, usd\"\n" +
+				"2020-01-01T00:00:00Z,1.5\n" +
+				"2020-01-01T01:00:00Z,NaN\n" +
+				"2020-01-01T02:00:00Z,-2\n"},
+		{"TestJSONLines",
+			func(in stream.Stream, column string,
+				w *bytes.Buffer) output.Output[int] {
+				return output.NewJSONLinesOutput(in, column, w, hourlyClock())
+			},
+			`{"timestamp":"2020-01-01T00:00:00Z","close, usd":1.5}` + "\n" +
+				`{"timestamp":"2020-01-01T01:00:00Z","close, usd":null}` +
+				"\n" +
+				`{"timestamp":"2020-01-01T02:00:00Z","close, usd":-2}` + "\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			var buffer bytes.Buffer
+
+			so := c.create(input.NewListStream(
+				[]float64{1.5, gomath.NaN(), -2}), "close, usd", &buffer)
+
+			drain(t, so)
+
+			if so.GetData() != 3 {
+				t.Fatalf("expected 3 samples, got %d", so.GetData())
+			}
+
+			// samples are buffered until the output is closed
+			so.Close()
+
+			if buffer.String() != c.expected {
+				t.Fatalf("expected:\n%s\ngot:\n%s", c.expected,
+					buffer.String())
+			}
+
+		})
+	}
+
+}
+
+// TestMultiSinkOutput tests writing the values of a multi stream with a column
+// for each component.
+func TestMultiSinkOutput(t *testing.T) {
+
+	var buffer bytes.Buffer
+
+	so := output.NewMultiJSONLinesOutput(input.NewMultiListStream(
+		[]string{"upper", "lower"}, [][]float64{{2, 1}, {4, 3}}), &buffer,
+		hourlyClock())
+
+	drainMulti(t, so)
+	so.Close()
+
+	expected := `{"timestamp":"2020-01-01T00:00:00Z","upper":2,"lower":1}` +
+		"\n" + `{"timestamp":"2020-01-01T01:00:00Z","upper":4,"lower":3}` +
+		"\n"
+
+	if buffer.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, buffer.String())
+	}
+
+}
+
+// failingWriter is a writer that fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+// TestSinkOutputErrors tests reporting errors flushing buffered samples.
+func TestSinkOutputErrors(t *testing.T) {
+
+	// a failed flush at the end of the stream is returned by Next
+	so := output.NewCSVOutput(input.NewListStream([]float64{1}), "value",
+		failingWriter{}, hourlyClock())
+
+	if _, err := so.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := so.Next(); err == nil || err == stream.ErrEndOfStream {
+		t.Fatalf("expected a flush error, got %v", err)
+	}
+
+	if so.Err() == nil {
+		t.Fatal("expected the flush error to be recorded")
+	}
+
+	// a failed flush when the output is closed is reported by Err
+	so = output.NewCSVOutput(input.NewListStream([]float64{1, 2}), "value",
+		failingWriter{}, hourlyClock())
+
+	if _, err := so.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	if so.Err() != nil {
+		t.Fatalf("expected no error before closing, got %v", so.Err())
+	}
+
+	so.Close()
+
+	if so.Err() == nil {
+		t.Fatal("expected the flush error to be reported after closing")
+	}
+
+}
+
+// TestSinkOutputWriter tests that closing an output flushes the writer
+// without closing it.
+func TestSinkOutputWriter(t *testing.T) {
+
+	var buffer closeBuffer
+
+	so := output.NewCSVOutput(input.NewListStream([]float64{1}), "value",
+		&buffer, hourlyClock())
+
+	drain(t, so)
+	so.Close()
+
+	if buffer.closed || buffer.Len() == 0 || so.Err() != nil {
+		t.Fatalf("expected a flushed open writer, got closed %t, %d bytes, "+
+			"err: %v", buffer.closed, buffer.Len(), so.Err())
+	}
+
+}
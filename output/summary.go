@@ -0,0 +1,186 @@
+package output
+
+import (
+	"fmt"
+	gomath "math"
+	"sync"
+
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// A Quantile is an estimated quantile of the values read from a stream.
+type Quantile struct {
+	// Probability is the probability of a value falling at or below the
+	// quantile.
+	Probability float64
+	// Value is the estimated quantile.
+	Value float64
+}
+
+// A Bucket counts the values read from a stream that fall at or below its
+// upper bound and above the upper bound of the previous bucket.
+type Bucket struct {
+	// Upper is the upper bound of the bucket; the last bucket is unbounded.
+	Upper float64
+	// Count is the number of values in the bucket.
+	Count int
+}
+
+// A Summary describes the distribution of the values read from a stream; every
+// statistic is NaN until a value has been read.
+type Summary struct {
+	Count int
+	Min   float64
+	Max   float64
+	Mean  float64
+	// Variance is the sample variance of the values.
+	Variance float64
+	StdDev   float64
+	// Skewness is the population skewness of the values.
+	Skewness float64
+	// Kurtosis is the population excess kurtosis of the values.
+	Kurtosis  float64
+	Quantiles []Quantile
+	Histogram []Bucket
+}
+
+// summaryState is the state accumulated by a summary output.
+type summaryState struct {
+	min       float64
+	max       float64
+	moments   util.Moments
+	quantiles []util.P2Quantile
+	bounds    []float64
+	counts    []int
+}
+
+// summary is used to accumulate running statistics of a stream; the state is
+// updated in place and the summary is calculated when it is read, so reading
+// values does not allocate. The lock is only held while a value is added or
+// the summary is calculated.
+type summary struct {
+	mu    sync.Mutex
+	state summaryState
+	in    stream.Stream
+}
+
+// NewSummaryOutput constructs an output that accumulates statistics of the
+// values read from a stream in constant memory: the count, minimum, maximum,
+// mean, variance, skewness and kurtosis, an estimate of each of the specified
+// quantiles, which must be between zero and one exclusive, and a histogram
+// with a bucket for each of the specified upper bounds, which must be
+// ascending, followed by an unbounded bucket. Values that are not ready and
+// infinite values are passed through without being summarized.
+func NewSummaryOutput(in stream.Stream, quantiles []float64,
+	buckets []float64) (Output[Summary], error) {
+
+	s := &summary{
+		state: summaryState{
+			min:    gomath.Inf(1),
+			max:    gomath.Inf(-1),
+			bounds: append(append([]float64{}, buckets...), gomath.Inf(1)),
+			counts: make([]int, len(buckets)+1),
+		},
+		in: in,
+	}
+
+	for _, p := range quantiles {
+		estimator, err := util.NewP2Quantile(p)
+		if err != nil {
+			return nil, err
+		}
+		s.state.quantiles = append(s.state.quantiles, estimator)
+	}
+
+	for i := 1; i < len(buckets); i++ {
+		if !(buckets[i] > buckets[i-1]) {
+			return nil, fmt.Errorf("histogram buckets must be ascending, "+
+				"got %v after %v", buckets[i], buckets[i-1])
+		}
+	}
+
+	return s, nil
+
+}
+
+func (s *summary) Next() (float64, error) {
+
+	value, err := s.in.Next()
+	if err != nil || gomath.IsNaN(value) || gomath.IsInf(value, 0) {
+		// pass errors, values that are not ready and infinite values, which
+		// would leave every moment NaN, through without summarizing them
+		return value, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.min = gomath.Min(s.state.min, value)
+	s.state.max = gomath.Max(s.state.max, value)
+	s.state.moments.Add(value)
+
+	for i := range s.state.quantiles {
+		s.state.quantiles[i].Add(value)
+	}
+
+	for i, upper := range s.state.bounds {
+		if value <= upper {
+			s.state.counts[i]++
+			break
+		}
+	}
+
+	return value, nil
+
+}
+
+func (s *summary) GetData() Summary {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := &s.state
+
+	data := Summary{
+		Count:     state.moments.Count(),
+		Quantiles: make([]Quantile, len(state.quantiles)),
+		Histogram: make([]Bucket, len(state.bounds)),
+	}
+
+	for i, upper := range state.bounds {
+		data.Histogram[i] = Bucket{Upper: upper, Count: state.counts[i]}
+	}
+
+	if data.Count == 0 {
+
+		nan := gomath.NaN()
+		data.Min, data.Max, data.Mean = nan, nan, nan
+		data.Variance, data.StdDev = nan, nan
+		data.Skewness, data.Kurtosis = nan, nan
+		for i, q := range state.quantiles {
+			data.Quantiles[i] = Quantile{Probability: q.Quantile(), Value: nan}
+		}
+
+		return data
+
+	}
+
+	data.Min, data.Max = state.min, state.max
+	data.Mean = state.moments.Mean()
+	data.Variance = state.moments.SampleVariance()
+	data.StdDev = gomath.Sqrt(data.Variance)
+	data.Skewness = state.moments.Skewness()
+	data.Kurtosis = state.moments.Kurtosis()
+
+	for i, q := range state.quantiles {
+		data.Quantiles[i] = Quantile{Probability: q.Quantile(), Value: q.Value()}
+	}
+
+	return data
+
+}
+
+func (s *summary) Close() {
+	s.in.Close()
+}
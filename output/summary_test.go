@@ -0,0 +1,184 @@
+package output_test
+
+import (
+	gomath "math"
+	"sync"
+	"testing"
+
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/output"
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/util"
+)
+
+// TestSummaryOutput tests summarizing the values of a stream.
+func TestSummaryOutput(t *testing.T) {
+
+	values := make([]float64, 0, 101)
+	for i := 0; i <= 100; i++ {
+		values = append(values, float64(i))
+	}
+	values = append(values, gomath.NaN(), gomath.Inf(1), gomath.Inf(-1))
+
+	so, err := output.NewSummaryOutput(input.NewListStream(values),
+		[]float64{0.1, 0.5, 0.9}, []float64{25, 50, 75})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer so.Close()
+
+	for {
+		if _, err := so.Next(); err == stream.ErrEndOfStream {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data := so.GetData()
+
+	// values that are not ready and infinite values are not summarized
+	if data.Count != 101 || data.Min != 0 || data.Max != 100 {
+		t.Fatalf("unexpected count and range: %d, %f, %f", data.Count,
+			data.Min, data.Max)
+	}
+
+	expected := []struct {
+		name     string
+		expected float64
+		actual   float64
+	}{
+		{"mean", 50, data.Mean},
+		{"variance", 858.5, data.Variance},
+		{"std dev", gomath.Sqrt(858.5), data.StdDev},
+		{"skewness", 0, data.Skewness},
+		{"kurtosis", -1.2002353, data.Kurtosis},
+	}
+
+	for _, e := range expected {
+		if gomath.Abs(e.actual-e.expected) > 1e-6 {
+			t.Errorf("expected %s %f, got %f", e.name, e.expected, e.actual)
+		}
+	}
+
+	if len(data.Quantiles) != 3 {
+		t.Fatalf("expected 3 quantiles, got %v", data.Quantiles)
+	}
+
+	for _, q := range data.Quantiles {
+		if gomath.Abs(q.Value-q.Probability*100) > 2 {
+			t.Errorf("expected quantile %.2f near %.2f, got %.2f",
+				q.Probability, q.Probability*100, q.Value)
+		}
+	}
+
+	buckets := []output.Bucket{
+		{Upper: 25, Count: 26},
+		{Upper: 50, Count: 25},
+		{Upper: 75, Count: 25},
+		{Upper: gomath.Inf(1), Count: 25},
+	}
+
+	if len(data.Histogram) != len(buckets) {
+		t.Fatalf("expected %v, got %v", buckets, data.Histogram)
+	}
+
+	for i := range buckets {
+		if data.Histogram[i] != buckets[i] {
+			t.Fatalf("expected %v, got %v", buckets, data.Histogram)
+		}
+	}
+
+}
+
+// TestSummaryOutputEmpty tests the summary of a stream without values.
+func TestSummaryOutputEmpty(t *testing.T) {
+
+	so, err := output.NewSummaryOutput(input.NewListStream(nil),
+		[]float64{0.5}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer so.Close()
+
+	data := so.GetData()
+
+	if data.Count != 0 || !gomath.IsNaN(data.Mean) ||
+		!gomath.IsNaN(data.Min) || !gomath.IsNaN(data.Quantiles[0].Value) {
+		t.Fatalf("expected empty summary, got %+v", data)
+	}
+
+	if len(data.Histogram) != 1 || !gomath.IsInf(data.Histogram[0].Upper, 1) {
+		t.Fatalf("expected a single unbounded bucket, got %v",
+			data.Histogram)
+	}
+
+}
+
+// TestSummaryOutputInvalid tests rejecting invalid quantiles and buckets.
+func TestSummaryOutputInvalid(t *testing.T) {
+
+	if _, err := output.NewSummaryOutput(input.NewListStream(nil),
+		[]float64{1.5}, nil); err == nil {
+		t.Fatal("expected invalid quantile error")
+	}
+
+	if _, err := output.NewSummaryOutput(input.NewListStream(nil), nil,
+		[]float64{2, 1}); err == nil {
+		t.Fatal("expected invalid bucket error")
+	}
+
+}
+
+// TestSummaryOutputConcurrent tests reading the summary while values are
+// being read.
+func TestSummaryOutputConcurrent(t *testing.T) {
+
+	values := make([]float64, 1000)
+	for i := range values {
+		values[i] = float64(i % 10)
+	}
+
+	so, err := output.NewSummaryOutput(input.NewListStream(values),
+		[]float64{0.5}, []float64{5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer so.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+
+		defer wg.Done()
+
+		for {
+			if _, err := so.Next(); err == stream.ErrEndOfStream {
+				return
+			}
+		}
+
+	}()
+
+	// every snapshot is consistent with itself
+	for data := so.GetData(); data.Count < len(values); data = so.GetData() {
+
+		total := 0
+		for _, bucket := range data.Histogram {
+			total += bucket.Count
+		}
+
+		if total != data.Count {
+			t.Fatalf("expected %d values in buckets, got %d", data.Count,
+				total)
+		}
+
+	}
+
+	wg.Wait()
+
+	if data := so.GetData(); util.CompareFloat(data.Mean, 4.5) != 0 {
+		t.Fatalf("expected mean 4.5, got %f", data.Mean)
+	}
+
+}
@@ -0,0 +1,30 @@
+package stream
+
+import "time"
+
+// A Candle summarizes trading activity over an interval of time.
+type Candle struct {
+	// Timestamp is the start of the interval summarized by the candle.
+	Timestamp time.Time
+	// Open is the price at the start of the interval.
+	Open float64
+	// High is the highest price reached during the interval.
+	High float64
+	// Low is the lowest price reached during the interval.
+	Low float64
+	// Close is the price at the end of the interval.
+	Close float64
+	// Volume is the amount of the base currency traded during the interval.
+	Volume float64
+	// QuoteVolume is the amount of the quote currency traded during the
+	// interval.
+	QuoteVolume float64
+}
+
+// A CandleStream provides a stream of candles.
+type CandleStream interface {
+	// Next gets the next candle in the stream.
+	Next() (Candle, error)
+	// Close closes any resources the stream is currently reading.
+	Close()
+}
@@ -0,0 +1,26 @@
+package stream
+
+import "time"
+
+// A Level is a price level of interest, such as a pivot point or a support or
+// resistance level. Levels are identified by their kind, timestamp and price;
+// a level stream announces a level when it becomes active and announces it
+// again as inactive when it is withdrawn.
+type Level struct {
+	// Timestamp is the time at which the level was established.
+	Timestamp time.Time
+	// Kind names the kind of level, e.g. r1 or support.
+	Kind string
+	// Price is the price of the level.
+	Price float64
+	// Active reports whether the level is active or being withdrawn.
+	Active bool
+}
+
+// A LevelStream provides a stream of changes to a set of active levels.
+type LevelStream interface {
+	// Next gets the next change to the active levels in the stream.
+	Next() (Level, error)
+	// Close closes any resources the stream is currently reading.
+	Close()
+}
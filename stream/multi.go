@@ -0,0 +1,13 @@
+package stream
+
+// A MultiStream provides a stream of sets of related values; each value in a
+// set is identified by the name of a component, e.g. the upper, middle and
+// lower bands of an indicator.
+type MultiStream interface {
+	// Components gets the names of the components of each set of values.
+	Components() []string
+	// Next gets the next set of values in the stream ordered by component.
+	Next() ([]float64, error)
+	// Close closes any resources the stream is currently reading.
+	Close()
+}
@@ -7,6 +7,13 @@ import "errors"
 var (
 	// ErrEndOfStream indicates that the end of the stream has been reached.
 	ErrEndOfStream = errors.New("end of stream")
+	// ErrNotReady indicates that the stream has not yet received enough input
+	// to produce a meaningful value, e.g. an indicator that has not filled its
+	// window; the value returned alongside this error is provisional and
+	// should not be treated as a signal. Streams that receive a provisional
+	// value either calculate their own provisional value from it or pass it
+	// through, never a placeholder such as zero.
+	ErrNotReady = errors.New("stream not ready")
 )
 
 // A Stream provides allows
@@ -0,0 +1,95 @@
+package util
+
+import gomath "math"
+
+// A Moments maintains the mean and the second, third and fourth central
+// moments of a collection of values in a single pass, from which the variance,
+// skewness and kurtosis of the values can be calculated; the mean and second
+// moment are maintained by a Welford. Values cannot be removed.
+type Moments struct {
+	welford Welford
+	m3      float64
+	m4      float64
+}
+
+// Add adds a value to the collection.
+func (m *Moments) Add(value float64) {
+
+	n1 := float64(m.welford.n)
+	n := n1 + 1.0
+
+	delta := value - m.welford.mean
+	deltaN := delta / n
+	deltaN2 := deltaN * deltaN
+	term := delta * deltaN * n1
+
+	// the higher moments are updated from the lower moments before they are
+	// themselves updated
+	m.m4 += term*deltaN2*(n*n-3.0*n+3.0) + 6.0*deltaN2*m.welford.m2 -
+		4.0*deltaN*m.m3
+	m.m3 += term*deltaN*(n-2.0) - 3.0*deltaN*m.welford.m2
+	m.welford.Add(value)
+
+}
+
+// Count returns the number of values in the collection.
+func (m *Moments) Count() int {
+
+	return m.welford.Count()
+
+}
+
+// Mean returns the mean of the values in the collection.
+func (m *Moments) Mean() float64 {
+
+	return m.welford.Mean()
+
+}
+
+// Variance returns the population variance of the values in the collection.
+func (m *Moments) Variance() float64 {
+
+	return m.welford.Variance()
+
+}
+
+// SampleVariance returns the sample variance of the values in the collection.
+func (m *Moments) SampleVariance() float64 {
+
+	return m.welford.SampleVariance()
+
+}
+
+// Skewness returns the population skewness of the values in the collection,
+// or zero if the values do not vary.
+func (m *Moments) Skewness() float64 {
+
+	m2 := m.welford.m2
+	if m2 == 0.0 {
+		return 0.0
+	}
+
+	return gomath.Sqrt(float64(m.welford.n)) * m.m3 / gomath.Pow(m2, 1.5)
+
+}
+
+// Kurtosis returns the population excess kurtosis of the values in the
+// collection, which is zero for normally distributed values, or zero if the
+// values do not vary.
+func (m *Moments) Kurtosis() float64 {
+
+	m2 := m.welford.m2
+	if m2 == 0.0 {
+		return 0.0
+	}
+
+	return float64(m.welford.n)*m.m4/(m2*m2) - 3.0
+
+}
+
+// Reset removes all values from the collection.
+func (m *Moments) Reset() {
+
+	*m = Moments{}
+
+}
@@ -0,0 +1,74 @@
+package util_test
+
+import (
+	gomath "math"
+	"testing"
+
+	"github.com/bsladewski/lapis/util"
+)
+
+// TestMoments tests the moments of a collection of values against moments
+// calculated directly from the values.
+func TestMoments(t *testing.T) {
+
+	// define input data skewed to the right
+	inputData := []float64{1.0, 2.0, 2.0, 3.0, 3.0, 3.0, 4.0, 8.0, 13.0}
+
+	var m util.Moments
+	for _, value := range inputData {
+		m.Add(value)
+	}
+
+	// calculate the central moments directly
+	n := float64(len(inputData))
+	var mean, m2, m3, m4 float64
+	for _, value := range inputData {
+		mean += value / n
+	}
+	for _, value := range inputData {
+		d := value - mean
+		m2 += d * d
+		m3 += d * d * d
+		m4 += d * d * d * d
+	}
+
+	expected := []struct {
+		name     string
+		expected float64
+		actual   float64
+	}{
+		{"mean", mean, m.Mean()},
+		{"variance", m2 / n, m.Variance()},
+		{"sample variance", m2 / (n - 1), m.SampleVariance()},
+		{"skewness", gomath.Sqrt(n) * m3 / gomath.Pow(m2, 1.5),
+			m.Skewness()},
+		{"kurtosis", n*m4/(m2*m2) - 3.0, m.Kurtosis()},
+	}
+
+	for _, e := range expected {
+		if util.CompareFloat(e.actual, e.expected) != 0 {
+			t.Errorf("expected %s %f, got %f", e.name, e.expected, e.actual)
+		}
+	}
+
+	if m.Count() != len(inputData) {
+		t.Fatalf("expected count %d, got %d", len(inputData), m.Count())
+	}
+
+	if m.Skewness() <= 0.0 {
+		t.Fatalf("expected positive skewness, got %f", m.Skewness())
+	}
+
+	// assert that constant values have no skewness or kurtosis
+	m.Reset()
+	for i := 0; i < 3; i++ {
+		m.Add(5.0)
+	}
+
+	if m.Mean() != 5.0 || m.Variance() != 0.0 || m.Skewness() != 0.0 ||
+		m.Kurtosis() != 0.0 {
+		t.Fatalf("unexpected moments of constant values: %f, %f, %f, %f",
+			m.Mean(), m.Variance(), m.Skewness(), m.Kurtosis())
+	}
+
+}
@@ -0,0 +1,171 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+)
+
+// A P2Quantile estimates a quantile of a collection of values in constant
+// memory using the P² algorithm of Jain and Chlamtac, which adjusts the
+// heights of five markers as values are added so that the middle marker
+// tracks the quantile; the quantile is exact until five values have been
+// added. A P2Quantile holds no references and may be copied by value.
+type P2Quantile struct {
+	p     float64
+	count int
+	// heights are the marker heights
+	heights [5]float64
+	// positions are the marker positions
+	positions [5]float64
+	// desired are the desired marker positions
+	desired [5]float64
+	// increments are the increments to the desired positions for each value
+	increments [5]float64
+}
+
+// NewP2Quantile returns an estimator of the quantile p, which must be between
+// zero and one exclusive.
+func NewP2Quantile(p float64) (P2Quantile, error) {
+
+	if !(p > 0.0 && p < 1.0) {
+		return P2Quantile{}, fmt.Errorf("invalid quantile: %v", p)
+	}
+
+	return P2Quantile{
+		p:          p,
+		positions:  [5]float64{1, 2, 3, 4, 5},
+		desired:    [5]float64{1, 1 + 2*p, 1 + 4*p, 3 + 2*p, 5},
+		increments: [5]float64{0, p / 2, p, (1 + p) / 2, 1},
+	}, nil
+
+}
+
+// Quantile returns the quantile estimated.
+func (q *P2Quantile) Quantile() float64 {
+
+	return q.p
+
+}
+
+// Count returns the number of values added.
+func (q *P2Quantile) Count() int {
+
+	return q.count
+
+}
+
+// Add adds a value to the collection.
+func (q *P2Quantile) Add(value float64) {
+
+	// the first five values become the initial marker heights
+	if q.count < 5 {
+		q.heights[q.count] = value
+		q.count++
+		if q.count == 5 {
+			sort.Float64s(q.heights[:])
+		}
+		return
+	}
+
+	q.count++
+
+	// find the cell containing the value, extending the extreme markers
+	var k int
+	switch {
+	case value < q.heights[0]:
+		q.heights[0] = value
+		k = 0
+	case value >= q.heights[4]:
+		q.heights[4] = value
+		k = 3
+	default:
+		for k = 0; k < 3; k++ {
+			if value < q.heights[k+1] {
+				break
+			}
+		}
+	}
+
+	for i := k + 1; i < 5; i++ {
+		q.positions[i]++
+	}
+
+	for i := range q.desired {
+		q.desired[i] += q.increments[i]
+	}
+
+	// adjust the heights of the middle markers that are off their desired
+	// positions by at least one
+	for i := 1; i < 4; i++ {
+
+		d := q.desired[i] - q.positions[i]
+		if (d < 1.0 || q.positions[i+1]-q.positions[i] <= 1.0) &&
+			(d > -1.0 || q.positions[i-1]-q.positions[i] >= -1.0) {
+			continue
+		}
+
+		s := 1.0
+		if d < 0.0 {
+			s = -1.0
+		}
+
+		height := q.parabolic(i, s)
+		if height <= q.heights[i-1] || height >= q.heights[i+1] {
+			height = q.linear(i, s)
+		}
+
+		q.heights[i] = height
+		q.positions[i] += s
+
+	}
+
+}
+
+// parabolic returns the height of marker i moved by s using the
+// piecewise-parabolic prediction formula.
+func (q *P2Quantile) parabolic(i int, s float64) float64 {
+
+	h, n := q.heights, q.positions
+
+	return h[i] + s/(n[i+1]-n[i-1])*
+		((n[i]-n[i-1]+s)*(h[i+1]-h[i])/(n[i+1]-n[i])+
+			(n[i+1]-n[i]-s)*(h[i]-h[i-1])/(n[i]-n[i-1]))
+
+}
+
+// linear returns the height of marker i moved by s using linear prediction.
+func (q *P2Quantile) linear(i int, s float64) float64 {
+
+	j := i + int(s)
+
+	return q.heights[i] + s*(q.heights[j]-q.heights[i])/
+		(q.positions[j]-q.positions[i])
+
+}
+
+// Value returns the estimated quantile, or zero if no values have been
+// added.
+func (q *P2Quantile) Value() float64 {
+
+	if q.count == 0 {
+		return 0.0
+	}
+
+	if q.count >= 5 {
+		return q.heights[2]
+	}
+
+	// interpolate between the closest ranks of the values added so far
+	values := append([]float64{}, q.heights[:q.count]...)
+	sort.Float64s(values)
+
+	rank := q.p * float64(q.count-1)
+	lower := int(rank)
+	if lower+1 >= q.count {
+		return values[lower]
+	}
+
+	return values[lower] + (rank-float64(lower))*
+		(values[lower+1]-values[lower])
+
+}
@@ -0,0 +1,86 @@
+package util_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/bsladewski/lapis/util"
+)
+
+// TestP2QuantileExact tests that quantiles are exact until five values have
+// been added.
+func TestP2QuantileExact(t *testing.T) {
+
+	q, err := util.NewP2Quantile(0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if q.Value() != 0.0 {
+		t.Fatalf("expected zero without values, got %f", q.Value())
+	}
+
+	expected := []float64{4.0, 3.0, 4.0, 3.5, 4.0}
+	for i, value := range []float64{4.0, 2.0, 6.0, 3.0, 9.0} {
+
+		q.Add(value)
+
+		if util.CompareFloat(q.Value(), expected[i]) != 0 {
+			t.Fatalf("expected median %f after %d values, got %f",
+				expected[i], i+1, q.Value())
+		}
+
+	}
+
+}
+
+// TestP2QuantileEstimate tests the estimated quantiles of uniformly
+// distributed values.
+func TestP2QuantileEstimate(t *testing.T) {
+
+	random := rand.New(rand.NewSource(1))
+
+	probabilities := []float64{0.05, 0.25, 0.5, 0.75, 0.95}
+	estimators := make([]util.P2Quantile, len(probabilities))
+	for i, p := range probabilities {
+		var err error
+		if estimators[i], err = util.NewP2Quantile(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 10000; i++ {
+		value := random.Float64() * 100.0
+		for j := range estimators {
+			estimators[j].Add(value)
+		}
+	}
+
+	for i, p := range probabilities {
+
+		estimate := estimators[i].Value()
+		if estimate < p*100.0-2.0 || estimate > p*100.0+2.0 {
+			t.Errorf("expected quantile %.2f near %.2f, got %.2f", p,
+				p*100.0, estimate)
+		}
+
+		if estimators[i].Quantile() != p || estimators[i].Count() != 10000 {
+			t.Errorf("unexpected estimator state: %f, %d",
+				estimators[i].Quantile(), estimators[i].Count())
+		}
+
+	}
+
+}
+
+// TestP2QuantileInvalid tests that quantiles outside of zero and one are
+// rejected.
+func TestP2QuantileInvalid(t *testing.T) {
+
+	for _, p := range []float64{0.0, 1.0, -0.5, 2.0} {
+		if _, err := util.NewP2Quantile(p); err == nil {
+			t.Errorf("expected error for quantile %f", p)
+		}
+	}
+
+}
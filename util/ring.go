@@ -0,0 +1,102 @@
+package util
+
+// A Ring is a fixed capacity buffer of values; once the buffer is full, adding
+// a value overwrites the oldest value in the buffer.
+type Ring[T any] struct {
+	values []T
+	start  int
+	size   int
+}
+
+// NewRing returns an empty ring buffer that holds at most capacity values.
+func NewRing[T any](capacity int) *Ring[T] {
+
+	if capacity < 0 {
+		capacity = 0
+	}
+
+	return &Ring[T]{
+		values: make([]T, capacity),
+	}
+
+}
+
+// Add appends a value to the ring buffer; if the buffer was already full the
+// oldest value is evicted and returned along with true.
+func (r *Ring[T]) Add(value T) (T, bool) {
+
+	// a ring without capacity immediately evicts every value it receives
+	if len(r.values) == 0 {
+		return value, true
+	}
+
+	// if the buffer is full overwrite the oldest value and advance the start
+	// of the buffer
+	if r.size == len(r.values) {
+		evicted := r.values[r.start]
+		r.values[r.start] = value
+		r.start = (r.start + 1) % len(r.values)
+		return evicted, true
+	}
+
+	r.values[(r.start+r.size)%len(r.values)] = value
+	r.size++
+
+	var zero T
+	return zero, false
+
+}
+
+// Get returns the value at index i where index zero is the oldest value in the
+// ring buffer; Get panics if i is out of range.
+func (r *Ring[T]) Get(i int) T {
+
+	if i < 0 || i >= r.size {
+		panic("ring index out of range")
+	}
+
+	return r.values[(r.start+i)%len(r.values)]
+
+}
+
+// Size returns the number of values currently stored in the ring buffer.
+func (r *Ring[T]) Size() int {
+
+	return r.size
+
+}
+
+// Cap returns the maximum number of values the ring buffer can hold.
+func (r *Ring[T]) Cap() int {
+
+	return len(r.values)
+
+}
+
+// IsFull returns whether the ring buffer has reached capacity.
+func (r *Ring[T]) IsFull() bool {
+
+	return r.size == len(r.values)
+
+}
+
+// ToArray returns a copy of the values in the ring buffer ordered from oldest
+// to newest.
+func (r *Ring[T]) ToArray() []T {
+
+	data := make([]T, r.size)
+	for i := range data {
+		data[i] = r.values[(r.start+i)%len(r.values)]
+	}
+
+	return data
+
+}
+
+// Clear removes all values from the ring buffer.
+func (r *Ring[T]) Clear() {
+
+	r.start = 0
+	r.size = 0
+
+}
@@ -0,0 +1,57 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/bsladewski/lapis/util"
+)
+
+// TestRing tests adding values to a ring buffer beyond its capacity.
+func TestRing(t *testing.T) {
+
+	// create a ring buffer with room for three values
+	r := util.NewRing[float64](3)
+
+	// define values to add and the value expected to be evicted by each add
+	cases := []struct {
+		value   float64
+		evicted float64
+		ok      bool
+	}{
+		{1.0, 0.0, false},
+		{2.0, 0.0, false},
+		{3.0, 0.0, false},
+		{4.0, 1.0, true},
+		{5.0, 2.0, true},
+	}
+
+	for i, tc := range cases {
+
+		evicted, ok := r.Add(tc.value)
+		if ok != tc.ok || evicted != tc.evicted {
+			t.Fatalf("index %d; expected (%.2f, %v), got (%.2f, %v)", i,
+				tc.evicted, tc.ok, evicted, ok)
+		}
+
+	}
+
+	// assert that the buffer is full and ordered from oldest to newest
+	if !r.IsFull() || r.Size() != 3 {
+		t.Fatalf("expected full buffer of size 3, got size %d", r.Size())
+	}
+
+	expected := []float64{3.0, 4.0, 5.0}
+	for i, value := range r.ToArray() {
+		if value != expected[i] || r.Get(i) != expected[i] {
+			t.Fatalf("index %d; expected %.2f, got %.2f", i, expected[i],
+				value)
+		}
+	}
+
+	// assert that clearing the buffer removes all values
+	r.Clear()
+	if r.Size() != 0 || len(r.ToArray()) != 0 {
+		t.Fatalf("expected empty buffer, got size %d", r.Size())
+	}
+
+}
@@ -0,0 +1,112 @@
+package util
+
+import "sync/atomic"
+
+// seriesChunkSize is the number of values held by each chunk of a series.
+const seriesChunkSize = 64
+
+// seriesChunk is a fixed size block of values in a series; values are only
+// ever appended to a chunk, so a value below the length of the chunk is never
+// modified again.
+type seriesChunk[T any] struct {
+	values [seriesChunkSize]T
+	length atomic.Int64
+}
+
+// seriesState lists the chunks holding the values of a series; every chunk
+// but the last is full. A state is never modified once it has been published.
+type seriesState[T any] struct {
+	chunks []*seriesChunk[T]
+}
+
+// A Series is a buffer that retains the most recent values appended to it, up
+// to a fixed capacity. A single writer may append values while any number of
+// readers take snapshots of the series; neither blocks the other, as values
+// are stored in append-only chunks and each snapshot copies the values that
+// had been appended when it started.
+type Series[T any] struct {
+	capacity int
+	state    atomic.Pointer[seriesState[T]]
+}
+
+// NewSeries returns an empty series that retains at most capacity values, or
+// every value appended to it if capacity is zero.
+func NewSeries[T any](capacity int) *Series[T] {
+
+	if capacity < 0 {
+		capacity = 0
+	}
+
+	s := &Series[T]{
+		capacity: capacity,
+	}
+
+	s.state.Store(&seriesState[T]{})
+
+	return s
+
+}
+
+// Append appends a value to the series, evicting the oldest value if the
+// series is full; Append must not be called concurrently with itself.
+func (s *Series[T]) Append(value T) {
+
+	state := s.state.Load()
+
+	// append to the last chunk if it has room
+	if n := len(state.chunks); n > 0 {
+		last := state.chunks[n-1]
+		if length := last.length.Load(); length < seriesChunkSize {
+			last.values[length] = value
+			last.length.Store(length + 1)
+			return
+		}
+	}
+
+	chunk := &seriesChunk[T]{}
+	chunk.values[0] = value
+	chunk.length.Store(1)
+
+	// readers only see the chunks within the length of their own state so
+	// appending to the shared array never modifies a published state
+	chunks := append(state.chunks, chunk)
+
+	// drop the oldest chunk once the values after it fill the series
+	if s.capacity > 0 {
+		for len(chunks) > 1 &&
+			(len(chunks)-2)*seriesChunkSize+1 >= s.capacity {
+			chunks = chunks[1:]
+		}
+	}
+
+	s.state.Store(&seriesState[T]{chunks: chunks})
+
+}
+
+// Snapshot returns a copy of the values in the series ordered from oldest to
+// newest.
+func (s *Series[T]) Snapshot() []T {
+
+	chunks := s.state.Load().chunks
+	if len(chunks) == 0 {
+		return []T{}
+	}
+
+	count := (len(chunks)-1)*seriesChunkSize +
+		int(chunks[len(chunks)-1].length.Load())
+
+	// skip values that have been evicted from the series
+	skip := 0
+	if s.capacity > 0 && count > s.capacity {
+		skip = count - s.capacity
+	}
+
+	data := make([]T, 0, count-skip)
+	for i := skip; i < count; i++ {
+		data = append(data,
+			chunks[i/seriesChunkSize].values[i%seriesChunkSize])
+	}
+
+	return data
+
+}
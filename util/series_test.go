@@ -0,0 +1,95 @@
+package util_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/bsladewski/lapis/util"
+)
+
+// TestSeries tests that a series retains the most recent values up to its
+// capacity as values are appended across many chunks.
+func TestSeries(t *testing.T) {
+
+	for _, capacity := range []int{0, 1, 63, 64, 65, 100, 200} {
+
+		s := util.NewSeries[int](capacity)
+
+		for n := 1; n <= 300; n++ {
+
+			s.Append(n)
+
+			// the snapshot holds the newest values, oldest first
+			expected := n
+			if capacity > 0 && expected > capacity {
+				expected = capacity
+			}
+
+			data := s.Snapshot()
+			if len(data) != expected {
+				t.Fatalf("capacity %d, n %d; expected %d values, got %d",
+					capacity, n, expected, len(data))
+			}
+
+			for i, value := range data {
+				if value != n-expected+1+i {
+					t.Fatalf("capacity %d, n %d; index %d: expected %d, got %d",
+						capacity, n, i, n-expected+1+i, value)
+				}
+			}
+
+		}
+
+	}
+
+}
+
+// TestSeriesSnapshotEmpty tests taking a snapshot of an empty series.
+func TestSeriesSnapshotEmpty(t *testing.T) {
+
+	data := util.NewSeries[float64](10).Snapshot()
+	if data == nil || len(data) != 0 {
+		t.Fatalf("expected empty snapshot, got %v", data)
+	}
+
+}
+
+// TestSeriesConcurrentSnapshot tests taking snapshots while values are
+// appended; each snapshot must hold consecutive values. Run with the race
+// detector to check that readers and the writer do not conflict.
+func TestSeriesConcurrentSnapshot(t *testing.T) {
+
+	s := util.NewSeries[int](100)
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				data := s.Snapshot()
+				for i := 1; i < len(data); i++ {
+					if data[i] != data[i-1]+1 {
+						t.Errorf("snapshot is not consecutive: %v", data)
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	for n := 0; n < 100000; n++ {
+		s.Append(n)
+	}
+
+	close(done)
+	wg.Wait()
+
+}
@@ -0,0 +1,44 @@
+package util
+
+import "math"
+
+// A KahanSum maintains a running sum of float64 values using Kahan-Babuska
+// compensated summation; this keeps rounding error from accumulating when a
+// long-lived sum is repeatedly added to and subtracted from.
+type KahanSum struct {
+	sum          float64
+	compensation float64
+}
+
+// Add adds a value to the running sum; values may be removed from the sum by
+// adding their negation.
+func (k *KahanSum) Add(value float64) {
+
+	t := k.sum + value
+
+	// accumulate the low order bits lost by the addition, taking care to use
+	// the larger of the two operands as the reference
+	if math.Abs(k.sum) >= math.Abs(value) {
+		k.compensation += (k.sum - t) + value
+	} else {
+		k.compensation += (value - t) + k.sum
+	}
+
+	k.sum = t
+
+}
+
+// Value returns the current compensated sum.
+func (k *KahanSum) Value() float64 {
+
+	return k.sum + k.compensation
+
+}
+
+// Reset sets the running sum back to zero.
+func (k *KahanSum) Reset() {
+
+	k.sum = 0.0
+	k.compensation = 0.0
+
+}
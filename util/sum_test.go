@@ -0,0 +1,33 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/bsladewski/lapis/util"
+)
+
+// TestKahanSum tests that a compensated sum does not drift when values are
+// repeatedly added and removed.
+func TestKahanSum(t *testing.T) {
+
+	var sum util.KahanSum
+
+	// add a large value followed by many small values that would be lost to
+	// rounding using naive summation
+	sum.Add(1e16)
+	for i := 0; i < 1000; i++ {
+		sum.Add(1.0)
+	}
+	sum.Add(-1e16)
+
+	if util.CompareFloat(sum.Value(), 1000.0) != 0 {
+		t.Fatalf("expected 1000.00, got %.2f", sum.Value())
+	}
+
+	// assert that resetting the sum returns it to zero
+	sum.Reset()
+	if sum.Value() != 0.0 {
+		t.Fatalf("expected 0.00, got %.2f", sum.Value())
+	}
+
+}
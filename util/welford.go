@@ -0,0 +1,345 @@
+package util
+
+// A Welford maintains the mean and variance of a collection of values using
+// Welford's online algorithm; values may be removed as well as added so that a
+// Welford can track a sliding window of values without the loss of precision
+// suffered by a running sum of squares.
+type Welford struct {
+	n    int
+	mean float64
+	m2   float64
+}
+
+// Add adds a value to the collection.
+func (w *Welford) Add(value float64) {
+
+	w.n++
+
+	delta := value - w.mean
+	w.mean += delta / float64(w.n)
+	w.m2 += delta * (value - w.mean)
+
+}
+
+// Remove removes a value that was previously added to the collection.
+func (w *Welford) Remove(value float64) {
+
+	if w.n <= 1 {
+		w.Reset()
+		return
+	}
+
+	delta := value - w.mean
+	w.n--
+	w.mean -= delta / float64(w.n)
+	w.m2 -= delta * (value - w.mean)
+
+	// guard against rounding error producing a negative sum of squares
+	if w.m2 < 0.0 {
+		w.m2 = 0.0
+	}
+
+}
+
+// Count returns the number of values in the collection.
+func (w *Welford) Count() int {
+
+	return w.n
+
+}
+
+// Mean returns the mean of the values in the collection.
+func (w *Welford) Mean() float64 {
+
+	return w.mean
+
+}
+
+// Variance returns the population variance of the values in the collection.
+func (w *Welford) Variance() float64 {
+
+	if w.n == 0 {
+		return 0.0
+	}
+
+	return w.m2 / float64(w.n)
+
+}
+
+// SampleVariance returns the sample variance of the values in the collection.
+func (w *Welford) SampleVariance() float64 {
+
+	if w.n < 2 {
+		return 0.0
+	}
+
+	return w.m2 / float64(w.n-1)
+
+}
+
+// Reset removes all values from the collection.
+func (w *Welford) Reset() {
+
+	w.n = 0
+	w.mean = 0.0
+	w.m2 = 0.0
+
+}
+
+// A Comoment maintains the means and co-moment of a collection of pairs of
+// values using Welford's online algorithm, from which the covariance of the
+// pairs can be calculated; pairs may be removed as well as added so that a
+// Comoment can track a sliding window of pairs.
+type Comoment struct {
+	n     int
+	meanX float64
+	meanY float64
+	c     float64
+}
+
+// Add adds a pair of values to the collection.
+func (c *Comoment) Add(x, y float64) {
+
+	c.n++
+
+	dx := x - c.meanX
+	c.meanX += dx / float64(c.n)
+	c.meanY += (y - c.meanY) / float64(c.n)
+	c.c += dx * (y - c.meanY)
+
+}
+
+// Remove removes a pair of values that was previously added to the collection.
+func (c *Comoment) Remove(x, y float64) {
+
+	if c.n <= 1 {
+		c.Reset()
+		return
+	}
+
+	c.n--
+
+	meanY := c.meanY
+	c.meanX -= (x - c.meanX) / float64(c.n)
+	c.meanY -= (y - c.meanY) / float64(c.n)
+	c.c -= (x - c.meanX) * (y - meanY)
+
+}
+
+// Count returns the number of pairs in the collection.
+func (c *Comoment) Count() int {
+
+	return c.n
+
+}
+
+// Covariance returns the population covariance of the pairs in the
+// collection.
+func (c *Comoment) Covariance() float64 {
+
+	if c.n == 0 {
+		return 0.0
+	}
+
+	return c.c / float64(c.n)
+
+}
+
+// SampleCovariance returns the sample covariance of the pairs in the
+// collection.
+func (c *Comoment) SampleCovariance() float64 {
+
+	if c.n < 2 {
+		return 0.0
+	}
+
+	return c.c / float64(c.n-1)
+
+}
+
+// Reset removes all pairs from the collection.
+func (c *Comoment) Reset() {
+
+	c.n = 0
+	c.meanX = 0.0
+	c.meanY = 0.0
+	c.c = 0.0
+
+}
+
+// A WelfordWindow maintains the mean and variance of a sliding window of the
+// most recent values. Removing values from a Welford slowly accumulates
+// rounding error, so once every value in the window has been replaced the
+// statistics are recalculated from the window, keeping the amortized cost of
+// each value constant.
+type WelfordWindow struct {
+	frame   *Ring[float64]
+	stats   Welford
+	evicted int
+}
+
+// NewWelfordWindow returns an empty window that holds at most size values.
+func NewWelfordWindow(size int) *WelfordWindow {
+
+	return &WelfordWindow{
+		frame: NewRing[float64](size),
+	}
+
+}
+
+// Add adds a value to the window, evicting the oldest value if the window is
+// full.
+func (w *WelfordWindow) Add(value float64) {
+
+	w.add(value)
+
+}
+
+// add adds a value to the window, returning the evicted value if the window
+// was full and whether the statistics were recalculated from the window.
+func (w *WelfordWindow) add(value float64) (float64, bool, bool) {
+
+	evicted, ok := w.frame.Add(value)
+	if !ok {
+		w.stats.Add(value)
+		return 0.0, false, false
+	}
+
+	w.evicted++
+	if w.evicted >= w.frame.Cap() {
+		w.evicted = 0
+		w.stats.Reset()
+		for i := 0; i < w.frame.Size(); i++ {
+			w.stats.Add(w.frame.Get(i))
+		}
+		return evicted, true, true
+	}
+
+	w.stats.Remove(evicted)
+	w.stats.Add(value)
+
+	return evicted, true, false
+
+}
+
+// Count returns the number of values in the window.
+func (w *WelfordWindow) Count() int {
+
+	return w.frame.Size()
+
+}
+
+// Get returns the value at the specified index of the window, where index zero
+// is the oldest value.
+func (w *WelfordWindow) Get(i int) float64 {
+
+	return w.frame.Get(i)
+
+}
+
+// Values returns a copy of the values in the window ordered from oldest to
+// newest.
+func (w *WelfordWindow) Values() []float64 {
+
+	return w.frame.ToArray()
+
+}
+
+// Mean returns the mean of the values in the window.
+func (w *WelfordWindow) Mean() float64 {
+
+	return w.stats.Mean()
+
+}
+
+// Variance returns the population variance of the values in the window.
+func (w *WelfordWindow) Variance() float64 {
+
+	return w.stats.Variance()
+
+}
+
+// SampleVariance returns the sample variance of the values in the window.
+func (w *WelfordWindow) SampleVariance() float64 {
+
+	return w.stats.SampleVariance()
+
+}
+
+// A ComomentWindow maintains the means, variances and covariance of a sliding
+// window of the most recent pairs of values; the statistics are recalculated
+// from the window as described by WelfordWindow.
+type ComomentWindow struct {
+	x        *WelfordWindow
+	y        *WelfordWindow
+	comoment Comoment
+}
+
+// NewComomentWindow returns an empty window that holds at most size pairs.
+func NewComomentWindow(size int) *ComomentWindow {
+
+	return &ComomentWindow{
+		x: NewWelfordWindow(size),
+		y: NewWelfordWindow(size),
+	}
+
+}
+
+// Add adds a pair of values to the window, evicting the oldest pair if the
+// window is full.
+func (c *ComomentWindow) Add(x, y float64) {
+
+	// both windows hold the same number of values so their statistics are
+	// recalculated together
+	evictedX, full, recalculated := c.x.add(x)
+	evictedY, _, _ := c.y.add(y)
+
+	switch {
+	case recalculated:
+		c.comoment.Reset()
+		for i := 0; i < c.x.Count(); i++ {
+			c.comoment.Add(c.x.Get(i), c.y.Get(i))
+		}
+	case full:
+		c.comoment.Remove(evictedX, evictedY)
+		c.comoment.Add(x, y)
+	default:
+		c.comoment.Add(x, y)
+	}
+
+}
+
+// Count returns the number of pairs in the window.
+func (c *ComomentWindow) Count() int {
+
+	return c.x.Count()
+
+}
+
+// X returns the window of the first value of each pair.
+func (c *ComomentWindow) X() *WelfordWindow {
+
+	return c.x
+
+}
+
+// Y returns the window of the second value of each pair.
+func (c *ComomentWindow) Y() *WelfordWindow {
+
+	return c.y
+
+}
+
+// Covariance returns the population covariance of the pairs in the window.
+func (c *ComomentWindow) Covariance() float64 {
+
+	return c.comoment.Covariance()
+
+}
+
+// SampleCovariance returns the sample covariance of the pairs in the window.
+func (c *ComomentWindow) SampleCovariance() float64 {
+
+	return c.comoment.SampleCovariance()
+
+}
@@ -0,0 +1,175 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/bsladewski/lapis/util"
+)
+
+// TestWelford tests the mean and variance of a sliding window of values
+// maintained by adding and removing values.
+func TestWelford(t *testing.T) {
+
+	// define input data
+	inputData := []float64{2.0, 4.0, 4.0, 4.0, 5.0, 5.0, 7.0, 9.0}
+
+	var w util.Welford
+	for _, value := range inputData {
+		w.Add(value)
+	}
+
+	// assert the mean and variance of the full collection
+	if util.CompareFloat(w.Mean(), 5.0) != 0 {
+		t.Fatalf("expected mean 5.00, got %.2f", w.Mean())
+	}
+
+	if util.CompareFloat(w.Variance(), 4.0) != 0 {
+		t.Fatalf("expected variance 4.00, got %.2f", w.Variance())
+	}
+
+	if util.CompareFloat(w.SampleVariance(), 32.0/7.0) != 0 {
+		t.Fatalf("expected sample variance %.2f, got %.2f", 32.0/7.0,
+			w.SampleVariance())
+	}
+
+	// remove the first four values leaving {5, 5, 7, 9}
+	for _, value := range inputData[:4] {
+		w.Remove(value)
+	}
+
+	if w.Count() != 4 {
+		t.Fatalf("expected count 4, got %d", w.Count())
+	}
+
+	if util.CompareFloat(w.Mean(), 6.5) != 0 {
+		t.Fatalf("expected mean 6.50, got %.2f", w.Mean())
+	}
+
+	if util.CompareFloat(w.Variance(), 2.75) != 0 {
+		t.Fatalf("expected variance 2.75, got %.2f", w.Variance())
+	}
+
+}
+
+// TestComoment tests the covariance of a sliding window of pairs maintained by
+// adding and removing pairs.
+func TestComoment(t *testing.T) {
+
+	// define input data
+	inputX := []float64{1.0, 2.0, 3.0, 4.0, 5.0}
+	inputY := []float64{2.0, 4.0, 5.0, 4.0, 5.0}
+
+	var c util.Comoment
+	for i := range inputX {
+		c.Add(inputX[i], inputY[i])
+	}
+
+	// assert the covariance of the full collection
+	if util.CompareFloat(c.Covariance(), 1.2) != 0 {
+		t.Fatalf("expected covariance 1.20, got %.2f", c.Covariance())
+	}
+
+	if util.CompareFloat(c.SampleCovariance(), 1.5) != 0 {
+		t.Fatalf("expected sample covariance 1.50, got %.2f",
+			c.SampleCovariance())
+	}
+
+	// remove the first two pairs leaving {(3, 5), (4, 4), (5, 5)}
+	for i := 0; i < 2; i++ {
+		c.Remove(inputX[i], inputY[i])
+	}
+
+	if c.Count() != 3 {
+		t.Fatalf("expected count 3, got %d", c.Count())
+	}
+
+	if util.CompareFloat(c.Covariance(), 0.0) != 0 {
+		t.Fatalf("expected covariance 0.00, got %.2f", c.Covariance())
+	}
+
+}
+
+// TestWelfordWindow tests the mean and variance of a sliding window of values
+// across the points at which the statistics are recalculated.
+func TestWelfordWindow(t *testing.T) {
+
+	// define input data
+	inputData := []float64{2.0, 4.0, 4.0, 4.0, 5.0, 5.0, 7.0, 9.0}
+
+	w := util.NewWelfordWindow(4)
+
+	for i, value := range inputData {
+
+		w.Add(value)
+
+		// calculate the statistics of the window directly
+		frame := inputData[:i+1]
+		if len(frame) > 4 {
+			frame = frame[len(frame)-4:]
+		}
+
+		var expected util.Welford
+		for _, value := range frame {
+			expected.Add(value)
+		}
+
+		if w.Count() != len(frame) || w.Get(0) != frame[0] ||
+			util.CompareFloat(w.Mean(), expected.Mean()) != 0 ||
+			util.CompareFloat(w.Variance(), expected.Variance()) != 0 ||
+			util.CompareFloat(w.SampleVariance(),
+				expected.SampleVariance()) != 0 {
+			t.Fatalf("index %d; expected mean %.2f and variance %.2f of %v, "+
+				"got %.2f and %.2f", i, expected.Mean(), expected.Variance(),
+				frame, w.Mean(), w.Variance())
+		}
+
+	}
+
+	if values := w.Values(); len(values) != 4 || values[3] != 9.0 {
+		t.Fatalf("expected the newest four values, got %v", values)
+	}
+
+}
+
+// TestComomentWindow tests the covariance of a sliding window of pairs across
+// the points at which the statistics are recalculated.
+func TestComomentWindow(t *testing.T) {
+
+	// define input data
+	inputX := []float64{1.0, 2.0, 3.0, 4.0, 5.0, 6.0, 7.0}
+	inputY := []float64{2.0, 4.0, 5.0, 4.0, 5.0, 3.0, 8.0}
+
+	w := util.NewComomentWindow(3)
+
+	for i := range inputX {
+
+		w.Add(inputX[i], inputY[i])
+
+		// calculate the covariance of the window directly
+		start := i - 2
+		if start < 0 {
+			start = 0
+		}
+
+		var expected util.Comoment
+		for j := start; j <= i; j++ {
+			expected.Add(inputX[j], inputY[j])
+		}
+
+		if w.Count() != i-start+1 ||
+			util.CompareFloat(w.Covariance(), expected.Covariance()) != 0 ||
+			util.CompareFloat(w.SampleCovariance(),
+				expected.SampleCovariance()) != 0 {
+			t.Fatalf("index %d; expected covariance %.4f, got %.4f", i,
+				expected.Covariance(), w.Covariance())
+		}
+
+		if w.X().Get(0) != inputX[start] || w.Y().Get(0) != inputY[start] {
+			t.Fatalf("index %d; expected oldest pair (%.2f, %.2f), got "+
+				"(%.2f, %.2f)", i, inputX[start], inputY[start],
+				w.X().Get(0), w.Y().Get(0))
+		}
+
+	}
+
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/bsladewski/lapis/event"
+	"github.com/bsladewski/lapis/indicator"
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/output"
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/watch"
+)
+
+// runWatch runs the watch command, which renders a pipeline and recent event
+// workers in the terminal until interrupted.
+func runWatch(args []string) error {
+
+	flags := flag.NewFlagSet("lapis watch", flag.ExitOnError)
+	replay := flags.String("replay", "",
+		"coinbase candle file to replay through the watched pipeline")
+	interval := flags.Duration("interval", 100*time.Millisecond,
+		"delay between replayed candles")
+	refresh := flags.Duration("refresh", 250*time.Millisecond,
+		"interval between screen refreshes")
+	workers := flags.Int("workers", 10, "number of recent workers to list")
+	width := flags.Int("width", terminalWidth(), "width of the terminal")
+	flags.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	screen := watch.NewScreen("lapis watch", nil)
+	screen.Workers(*workers, listWorkers)
+
+	if *replay != "" {
+
+		file, err := os.Open(*replay)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		candles, err := input.NewCoinbaseMockCandleStream(file)
+		if err != nil {
+			return err
+		}
+
+		// the close price and pattern signals each read every candle
+		split := input.NewCandleSplitterStream(candles, 2)
+
+		closes := output.NewArrayOutput(input.NewCandleFieldStream(split,
+			input.FieldClose), *width)
+		ma := output.NewArrayOutput(indicator.NewMAStream(closes, 20), *width)
+		patterns := output.NewArrayOutput(indicator.NewPatternSignalStream(
+			split, indicator.DefaultPatternThresholds()), *width)
+		defer ma.Close()
+		defer patterns.Close()
+
+		screen.Sparkline("close", closes)
+		screen.Sparkline("ma", ma)
+		screen.Signal("patterns", patterns)
+
+		go advance(ctx, *interval, ma, patterns)
+
+	}
+
+	return screen.Run(ctx, os.Stdout, *width, *refresh)
+
+}
+
+// advance reads each of the streams in turn at the specified interval until
+// any stream ends or the context is done.
+func advance(ctx context.Context, interval time.Duration,
+	streams ...stream.Stream) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+
+		for _, s := range streams {
+			if _, err := s.Next(); err != nil && err != stream.ErrNotReady {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+	}
+
+}
+
+// terminalWidth returns the width of the terminal given by the COLUMNS
+// environment variable, or 80 if it is not set.
+func terminalWidth() int {
+
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil &&
+		width > 0 {
+		return width
+	}
+
+	return 80
+
+}
+
+// listWorkers lists up to limit event workers, most recently updated first,
+// as rows of the workers table of a screen.
+func listWorkers(limit int) ([]watch.Worker, error) {
+
+	records, err := event.ListWorkers(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	workers := make([]watch.Worker, len(records))
+	for i, record := range records {
+		workers[i] = watch.Worker{
+			ID:        record.ID,
+			Name:      record.Name,
+			State:     record.State.String(),
+			Errors:    record.Errors,
+			UpdatedAt: record.UpdatedAt,
+		}
+	}
+
+	return workers, nil
+
+}
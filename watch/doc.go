@@ -0,0 +1,4 @@
+// Package watch renders the outputs of running pipelines in a terminal as
+// sparklines, latest values and signal states, refreshing as the pipelines
+// advance, along with a table of recent event workers.
+package watch
@@ -0,0 +1,262 @@
+package watch
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	gomath "math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bsladewski/lapis/output"
+)
+
+// the widths of the name and latest value columns in characters
+const (
+	nameWidth   = 16
+	latestWidth = 12
+)
+
+// clearScreen moves the cursor home and clears the terminal.
+const clearScreen = "\x1b[H\x1b[2J"
+
+// the kinds of rows rendered for outputs
+const (
+	sparklineRow = iota
+	signalRow
+)
+
+// A row renders an output on a screen.
+type row struct {
+	kind int
+	name string
+	out  output.Output[[]float64]
+}
+
+// A Screen renders selected outputs in a terminal; outputs are read through
+// their snapshots so a screen may be rendered while pipelines advance.
+type Screen struct {
+	title string
+	clock func() time.Time
+
+	mu          sync.Mutex
+	rows        []row
+	workers     int
+	listWorkers func(limit int) ([]Worker, error)
+}
+
+// A Worker is a row of the table of event workers rendered by a screen.
+type Worker struct {
+	ID        uint
+	Name      string
+	State     string
+	Errors    string
+	UpdatedAt time.Time
+}
+
+// NewScreen returns a screen with the specified title that is timestamped by
+// the supplied clock, or by the current time if clock is nil.
+func NewScreen(title string, clock func() time.Time) *Screen {
+
+	if clock == nil {
+		clock = time.Now
+	}
+
+	return &Screen{
+		title: title,
+		clock: clock,
+	}
+
+}
+
+// Sparkline adds a row that renders the latest value of an output along with
+// a sparkline of its recent values.
+func (s *Screen) Sparkline(name string, out output.Output[[]float64]) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rows = append(s.rows, row{kind: sparklineRow, name: name, out: out})
+
+}
+
+// Signal adds a row that renders the state of a signal output, such as the
+// output of a pattern signal stream, along with its recent states; positive
+// values are buy signals and negative values are sell signals.
+func (s *Screen) Signal(name string, out output.Output[[]float64]) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rows = append(s.rows, row{kind: signalRow, name: name, out: out})
+
+}
+
+// Workers adds a table of up to limit event workers, most recently updated
+// first, listed by the supplied function each time the screen is rendered,
+// e.g. by adapting event.ListWorkers.
+func (s *Screen) Workers(limit int,
+	list func(limit int) ([]Worker, error)) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.workers, s.listWorkers = limit, list
+
+}
+
+// Run renders the screen at the specified interval until the context is
+// done, clearing the terminal before each frame and rendering a final frame
+// when the context is done.
+func (s *Screen) Run(ctx context.Context, w io.Writer, width int,
+	interval time.Duration) error {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+
+		if _, err := io.WriteString(w, clearScreen); err != nil {
+			return err
+		}
+
+		if err := s.Render(w, width); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+	}
+
+}
+
+// Render writes a single frame of the screen that fits the specified width.
+func (s *Screen) Render(w io.Writer, width int) error {
+
+	s.mu.Lock()
+	rows := append([]row{}, s.rows...)
+	workers, listWorkers := s.workers, s.listWorkers
+	s.mu.Unlock()
+
+	buf := bufio.NewWriter(w)
+
+	timestamp := s.clock().UTC().Format("2006-01-02 15:04:05 UTC")
+	fmt.Fprintf(buf, "%s%s\n\n", fit(s.title, width-len(timestamp)),
+		timestamp)
+
+	chart := width - nameWidth - latestWidth - 2
+	if chart < 1 {
+		chart = 1
+	}
+
+	if len(rows) > 0 {
+		fmt.Fprintf(buf, "%-*s %-*s %s\n", nameWidth, "OUTPUT", latestWidth,
+			"LATEST", "RECENT")
+	}
+
+	for _, r := range rows {
+
+		values := r.out.GetData()
+		if len(values) > chart {
+			values = values[len(values)-chart:]
+		}
+
+		latest := gomath.NaN()
+		if len(values) > 0 {
+			latest = values[len(values)-1]
+		}
+
+		var state, recent string
+		switch r.kind {
+		case signalRow:
+			state, recent = signalState(latest), signals(values)
+		default:
+			state, recent = formatValue(latest), sparkline(values)
+		}
+
+		fmt.Fprintf(buf, "%s %s %s\n", fit(r.name, nameWidth),
+			fit(state, latestWidth), recent)
+
+	}
+
+	if workers > 0 && listWorkers != nil {
+		if len(rows) > 0 {
+			fmt.Fprintln(buf)
+		}
+		writeWorkers(buf, listWorkers, workers, width)
+	}
+
+	return buf.Flush()
+
+}
+
+// writeWorkers writes a table of recent event workers.
+func writeWorkers(w io.Writer, list func(limit int) ([]Worker, error),
+	limit, width int) {
+
+	records, err := list(limit)
+	if err != nil {
+		fmt.Fprintf(w, "workers unavailable: %v\n", err)
+		return
+	}
+
+	const idWidth, stateWidth, updatedWidth = 8, 10, 21
+
+	fmt.Fprintf(w, "%-*s %-*s %-*s %-*s %s\n", idWidth, "WORKER", nameWidth,
+		"NAME", stateWidth, "STATE", updatedWidth, "UPDATED", "ERRORS")
+
+	errorWidth := width - idWidth - nameWidth - stateWidth - updatedWidth - 4
+	if errorWidth < 0 {
+		errorWidth = 0
+	}
+
+	for _, record := range records {
+
+		// only the first line of errors fits in the table
+		errors := strings.SplitN(record.Errors, "\n", 2)[0]
+
+		fmt.Fprintf(w, "%-*d %s %s %s %s\n", idWidth, record.ID,
+			fit(record.Name, nameWidth), fit(record.State, stateWidth),
+			fit(record.UpdatedAt.UTC().Format("2006-01-02 15:04:05"),
+				updatedWidth),
+			strings.TrimRight(fit(errors, errorWidth), " "))
+
+	}
+
+}
+
+// fit pads or truncates text to the specified width in characters.
+func fit(text string, width int) string {
+
+	if width <= 0 {
+		return ""
+	}
+
+	runes := []rune(text)
+	if len(runes) > width {
+		if width == 1 {
+			return "…"
+		}
+		return string(runes[:width-1]) + "…"
+	}
+
+	return text + strings.Repeat(" ", width-len(runes))
+
+}
+
+// formatValue formats the latest value of an output.
+func formatValue(value float64) string {
+
+	if gomath.IsNaN(value) {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.6g", value)
+
+}
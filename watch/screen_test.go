@@ -0,0 +1,159 @@
+package watch_test
+
+import (
+	"bytes"
+	"context"
+	gomath "math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bsladewski/lapis/input"
+	"github.com/bsladewski/lapis/output"
+	"github.com/bsladewski/lapis/stream"
+	"github.com/bsladewski/lapis/watch"
+)
+
+// testClock returns a fixed time.
+func testClock() time.Time {
+
+	return time.Date(2020, 1, 1, 12, 30, 0, 0, time.UTC)
+
+}
+
+// drain reads every value from an output.
+func drain(t *testing.T, out output.Output[[]float64]) {
+
+	t.Helper()
+
+	for {
+		if _, err := out.Next(); err == stream.ErrEndOfStream {
+			return
+		} else if err != nil && err != stream.ErrNotReady {
+			t.Fatal(err)
+		}
+	}
+
+}
+
+func TestRender(t *testing.T) {
+
+	prices := output.NewArrayOutput(input.NewListStream(
+		[]float64{1, 2, 3, 4, 5, 6, 7, 8, gomath.NaN(), 1234.56789}), 0)
+	flat := output.NewArrayOutput(input.NewListStream(
+		[]float64{2, 2, 2}), 0)
+	signals := output.NewArrayOutput(input.NewListStream(
+		[]float64{0, 1, 0, -1}), 0)
+	empty := output.NewArrayOutput(input.NewListStream(nil), 0)
+
+	for _, out := range []output.Output[[]float64]{prices, flat, signals} {
+		drain(t, out)
+	}
+
+	s := watch.NewScreen("lapis", testClock)
+	s.Sparkline("a very long output name", prices)
+	s.Sparkline("flat", flat)
+	s.Signal("patterns", signals)
+	s.Signal("none", empty)
+
+	var buf bytes.Buffer
+	if err := s.Render(&buf, 80); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"lapis" + strings.Repeat(" ", 52) + "2020-01-01 12:30:00 UTC",
+		"",
+		"OUTPUT           LATEST       RECENT",
+		"a very long out… 1234.57      ▁▁▁▁▁▁▁▁ █",
+		"flat             2            ▄▄▄",
+		"patterns         SELL         ·▲·▼",
+		"none             -            ",
+		"",
+	}
+
+	lines := strings.Split(buf.String(), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines, got:\n%s", len(expected), buf.String())
+	}
+
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("expected line %d %q, got %q", i, expected[i], lines[i])
+		}
+	}
+
+}
+
+func TestRenderRecent(t *testing.T) {
+
+	values := make([]float64, 100)
+	for i := range values {
+		values[i] = float64(i)
+	}
+
+	out := output.NewArrayOutput(input.NewListStream(values), 0)
+	drain(t, out)
+
+	s := watch.NewScreen("", testClock)
+	s.Sparkline("values", out)
+
+	var buf bytes.Buffer
+	if err := s.Render(&buf, 40); err != nil {
+		t.Fatal(err)
+	}
+
+	// only the most recent values that fit the width are drawn
+	lines := strings.Split(buf.String(), "\n")
+	recent := []rune(lines[3])[30:]
+	if len(recent) != 10 || recent[0] != '▁' || recent[9] != '█' {
+		t.Fatalf("unexpected sparkline: %q", string(recent))
+	}
+
+}
+
+func TestRenderWorkers(t *testing.T) {
+
+	s := watch.NewScreen("lapis", testClock)
+	s.Workers(5, func(limit int) ([]watch.Worker, error) {
+		return []watch.Worker{{
+			ID:        1,
+			Name:      "watched",
+			State:     "finished",
+			UpdatedAt: testClock(),
+		}}, nil
+	})
+
+	var buf bytes.Buffer
+	if err := s.Render(&buf, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(buf.String(), "\n")
+	if !strings.HasPrefix(lines[2], "WORKER") ||
+		!strings.Contains(lines[3], "watched") ||
+		!strings.Contains(lines[3], "finished") {
+		t.Fatalf("unexpected workers table:\n%s", buf.String())
+	}
+
+}
+
+func TestRun(t *testing.T) {
+
+	s := watch.NewScreen("lapis", testClock)
+
+	ctx, cancel := context.WithTimeout(context.Background(),
+		50*time.Millisecond)
+	defer cancel()
+
+	var buf bytes.Buffer
+	if err := s.Run(ctx, &buf, 80, 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	// each frame clears the terminal
+	if frames := strings.Count(buf.String(), "\x1b[H\x1b[2J"); frames < 2 {
+		t.Fatalf("expected several frames, got %d", frames)
+	}
+
+}
@@ -0,0 +1,82 @@
+package watch
+
+import (
+	gomath "math"
+	"strings"
+)
+
+// sparkBlocks are the characters used to draw sparklines from lowest to
+// highest.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values as a line of block characters scaled between the
+// lowest and highest value; missing values are drawn as spaces.
+func sparkline(values []float64) string {
+
+	low, high := gomath.Inf(1), gomath.Inf(-1)
+	for _, value := range values {
+		if !gomath.IsNaN(value) && !gomath.IsInf(value, 0) {
+			low = gomath.Min(low, value)
+			high = gomath.Max(high, value)
+		}
+	}
+
+	var b strings.Builder
+	for _, value := range values {
+
+		switch {
+		case gomath.IsNaN(value) || gomath.IsInf(value, 0):
+			b.WriteRune(' ')
+		case high == low:
+			// values that do not vary are drawn at mid height
+			b.WriteRune(sparkBlocks[len(sparkBlocks)/2-1])
+		default:
+			level := int((value - low) / (high - low) *
+				float64(len(sparkBlocks)-1))
+			b.WriteRune(sparkBlocks[level])
+		}
+
+	}
+
+	return b.String()
+
+}
+
+// signalState returns the state of a signal value.
+func signalState(value float64) string {
+
+	switch {
+	case gomath.IsNaN(value):
+		return "-"
+	case value > 0:
+		return "BUY"
+	case value < 0:
+		return "SELL"
+	}
+
+	return "NEUTRAL"
+
+}
+
+// signals draws signal values as a line of buy, sell and neutral marks.
+func signals(values []float64) string {
+
+	var b strings.Builder
+	for _, value := range values {
+
+		switch {
+		case gomath.IsNaN(value):
+			b.WriteRune(' ')
+		case value > 0:
+			b.WriteRune('▲')
+		case value < 0:
+			b.WriteRune('▼')
+		default:
+			b.WriteRune('·')
+		}
+
+	}
+
+	return b.String()
+
+}